@@ -20,11 +20,22 @@ type AstExpression interface {
 type AstStatement interface {
 	AstNode
 	StatementNode()
+	Lines() *Span
 }
 
+// source lines covered by a statement, filled in by the parser
+type Span struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+}
+
+func (s *Span) Lines() *Span { return s }
+
 // program
 type Program struct {
 	Statements []AstStatement
+	Comments   []token.Token
 }
 
 func (p *Program) String() string {
@@ -37,6 +48,7 @@ func (p *Program) String() string {
 
 // statements
 type BlockStatement struct {
+	Span
 	Statements []AstStatement
 }
 
@@ -45,7 +57,6 @@ func (bs *BlockStatement) String() string {
 	var output string
 
 	output += "BlockStatement(\n"
-	println(len(bs.Statements))
 	for _, statement := range bs.Statements {
 		output += statement.String()
 		output += "\n"
@@ -56,8 +67,11 @@ func (bs *BlockStatement) String() string {
 }
 
 type VarStatement struct {
+	Span
 	Identifer IdentiferLiteral
-	Value     AstExpression
+	// nil when the declaration has no annotation
+	Type  *TypeName
+	Value AstExpression
 }
 
 func (vs *VarStatement) StatementNode() {}
@@ -67,6 +81,9 @@ func (vs *VarStatement) String() string {
 	output += "VarStatement("
 	output += "var "
 	output += vs.Identifer.String()
+	if vs.Type != nil {
+		output += ": " + vs.Type.String()
+	}
 	output += " = "
 	output += vs.Value.String()
 	output += ")"
@@ -75,6 +92,7 @@ func (vs *VarStatement) String() string {
 }
 
 type Conditional struct {
+	Span
 	Condition   AstExpression
 	Consequence BlockStatement
 }
@@ -94,6 +112,7 @@ func (con *Conditional) String() string {
 }
 
 type IfStatement struct {
+	Span
 	If   Conditional
 	Elif []Conditional
 	Else BlockStatement
@@ -125,6 +144,7 @@ func (is *IfStatement) String() string {
 }
 
 type ReturnStatement struct {
+	Span
 	ReturnValue AstExpression
 }
 
@@ -133,13 +153,16 @@ func (rs *ReturnStatement) String() string {
 	var output string
 
 	output += "ReturnStatement("
-	output += rs.ReturnValue.String()
+	if rs.ReturnValue != nil {
+		output += rs.ReturnValue.String()
+	}
 	output += ")"
 
 	return output
 }
 
 type ExpressionStatement struct {
+	Span
 	Token      token.Token
 	Expression AstExpression
 }
@@ -156,6 +179,7 @@ func (es *ExpressionStatement) String() string {
 }
 
 type ForStatement struct {
+	Span
 	Initializer AstStatement
 	Conditional AstExpression
 	Increment   AstStatement
@@ -177,6 +201,7 @@ func (fs *ForStatement) String() string {
 }
 
 type WhileStatement struct {
+	Span
 	Condition AstExpression
 	Body      AstStatement
 }
@@ -193,6 +218,117 @@ func (ws *WhileStatement) String() string {
 	return output
 }
 
+type TryStatement struct {
+	Span
+	Body BlockStatement
+	// nil when the error is not bound to a name
+	CatchIdent *IdentiferLiteral
+	// nil when there is no catch or finally clause
+	Catch   *BlockStatement
+	Finally *BlockStatement
+}
+
+func (ts *TryStatement) StatementNode() {}
+func (ts *TryStatement) String() string {
+	var output string
+
+	output += "Try(\n"
+	output += ts.Body.String()
+	output += ")"
+
+	if ts.Catch != nil {
+		output += "Catch("
+		if ts.CatchIdent != nil {
+			output += ts.CatchIdent.String()
+		}
+		output += ts.Catch.String()
+		output += ")"
+	}
+
+	if ts.Finally != nil {
+		output += "Finally(\n"
+		output += ts.Finally.String()
+		output += ")"
+	}
+
+	return output
+}
+
+type ThrowStatement struct {
+	Span
+	Value AstExpression
+}
+
+func (ts *ThrowStatement) StatementNode() {}
+func (ts *ThrowStatement) String() string {
+	var output string
+
+	output += "Throw("
+	output += ts.Value.String()
+	output += ")"
+
+	return output
+}
+
+type StructStatement struct {
+	Span
+	Token   token.Token // the name
+	Name    string
+	Fields  []IdentiferLiteral
+	Methods []*FunctionLiteral
+}
+
+func (ss *StructStatement) StatementNode() {}
+func (ss *StructStatement) String() string {
+	var output string
+
+	output += "Struct("
+	output += ss.Name
+	output += "Fields("
+	for _, field := range ss.Fields {
+		output += field.String()
+	}
+	output += ")"
+	for _, method := range ss.Methods {
+		output += method.String()
+	}
+	output += ")"
+
+	return output
+}
+
+type EnumStatement struct {
+	Span
+	Token    token.Token // the name
+	Name     string
+	Variants []EnumVariant
+}
+
+type EnumVariant struct {
+	Token  token.Token
+	Name   string
+	Fields []IdentiferLiteral
+}
+
+func (es *EnumStatement) StatementNode() {}
+func (es *EnumStatement) String() string {
+	var output string
+
+	output += "Enum("
+	output += es.Name
+	for _, variant := range es.Variants {
+		output += "Variant("
+		output += variant.Name
+		for _, field := range variant.Fields {
+			output += field.String()
+		}
+		output += ")"
+	}
+	output += ")"
+
+	return output
+}
+
 // expressions
 type InfixExpression struct {
 	Left     AstExpression
@@ -248,6 +384,41 @@ func (ce *CallExpression) String() string {
 	return output
 }
 
+// an argument passed by name, f(x = 1)
+type NamedArgument struct {
+	Name  IdentiferLiteral
+	Value AstExpression
+}
+
+func (na *NamedArgument) ExpressionNode() {}
+func (na *NamedArgument) String() string {
+	var output string
+
+	output += "Named("
+	output += na.Name.String()
+	output += na.Value.String()
+	output += ")"
+
+	return output
+}
+
+// an array expanded into the arguments of a call, f(...args)
+type SpreadExpression struct {
+	Token token.Token
+	Value AstExpression
+}
+
+func (se *SpreadExpression) ExpressionNode() {}
+func (se *SpreadExpression) String() string {
+	var output string
+
+	output += "Spread("
+	output += se.Value.String()
+	output += ")"
+
+	return output
+}
+
 type IdentiferLiteral struct {
 	Token token.Token
 	Ident string
@@ -264,6 +435,22 @@ func (idl *IdentiferLiteral) String() string {
 	return output
 }
 
+// a type annotation, x: int
+type TypeName struct {
+	Token token.Token
+	Name  string
+}
+
+func (tn *TypeName) String() string {
+	var output string
+
+	output += "Type("
+	output += tn.Name
+	output += ")"
+
+	return output
+}
+
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
@@ -329,9 +516,14 @@ func (bl *BooleanLiteral) String() string {
 }
 
 type FunctionLiteral struct {
-	Name       string
-	Parameters []IdentiferLiteral
+	Token      token.Token // the name, or where an anonymous function starts
+	Name       string      // empty for anonymous functions
+	Parameters []Parameter
+	// nil when the return type is not annotated
+	ReturnType *TypeName
 	Body       BlockStatement
+	// written x => x * 2, the body returns the expression
+	Arrow bool
 }
 
 func (fl *FunctionLiteral) ExpressionNode() {}
@@ -341,16 +533,49 @@ func (fl *FunctionLiteral) String() string {
 	output += "Func( \n"
 	output += fl.Name
 	output += "Params("
-	for _, ident := range fl.Parameters {
-		output += ident.String()
+	for _, param := range fl.Parameters {
+		output += param.String()
+	}
+	output += ")"
+	if fl.ReturnType != nil {
+		output += " -> " + fl.ReturnType.String()
 	}
-	output += ") \n"
+	output += " \n"
 	output += fl.Body.String()
 	output += ")"
 
 	return output
 }
 
+// a parameter of a function, func f(a: int, b = 1, ...rest)
+type Parameter struct {
+	Ident IdentiferLiteral
+	// nil when the parameter is not annotated
+	Type *TypeName
+	// nil when an argument must be passed for the parameter
+	Default AstExpression
+	// collects the remaining arguments into an array, only the last
+	// parameter can
+	Variadic bool
+}
+
+func (pa *Parameter) String() string {
+	var output string
+
+	if pa.Variadic {
+		output += "..."
+	}
+	output += pa.Ident.String()
+	if pa.Type != nil {
+		output += ": " + pa.Type.String()
+	}
+	if pa.Default != nil {
+		output += " = " + pa.Default.String()
+	}
+
+	return output
+}
+
 type ArrayLiteral struct {
 	Elements []AstExpression
 }
@@ -386,6 +611,7 @@ func (ie *IndexExpression) String() string {
 }
 
 type HashLiteral struct {
+	Keys  []AstExpression // in source order
 	Pairs map[AstExpression]AstExpression
 }
 
@@ -394,9 +620,9 @@ func (hl *HashLiteral) String() string {
 	var output string
 
 	output += "Hash("
-	for key, value := range hl.Pairs {
+	for _, key := range hl.Keys {
 		output += key.String()
-		output += value.String()
+		output += hl.Pairs[key].String()
 	}
 	output += ")"
 
@@ -419,3 +645,188 @@ func (ra *Reassignment) String() string {
 
 	return output
 }
+
+type MemberExpression struct {
+	Left   AstExpression
+	Member IdentiferLiteral
+}
+
+func (me *MemberExpression) ExpressionNode() {}
+func (me *MemberExpression) String() string {
+	var output string
+
+	output += "Member("
+	output += me.Left.String()
+	output += me.Member.String()
+	output += ")"
+
+	return output
+}
+
+type FieldAssignment struct {
+	Target *MemberExpression
+	Value  AstExpression
+}
+
+func (fa *FieldAssignment) ExpressionNode() {}
+func (fa *FieldAssignment) String() string {
+	var output string
+
+	output += "AssignField("
+	output += fa.Target.String()
+	output += fa.Value.String()
+	output += ")"
+
+	return output
+}
+
+type MatchExpression struct {
+	Token   token.Token // the match keyword
+	Subject AstExpression
+	Arms    []MatchArm
+}
+
+type MatchArm struct {
+	Pattern Pattern
+	// nil when the arm has no if guard
+	Guard AstExpression
+	Body  AstExpression
+}
+
+func (me *MatchExpression) ExpressionNode() {}
+func (me *MatchExpression) String() string {
+	var output string
+
+	output += "Match("
+	output += me.Subject.String()
+	for _, arm := range me.Arms {
+		output += "Arm("
+		output += arm.Pattern.String()
+		if arm.Guard != nil {
+			output += "If("
+			output += arm.Guard.String()
+			output += ")"
+		}
+		output += arm.Body.String()
+		output += ")"
+	}
+	output += ")"
+
+	return output
+}
+
+// patterns
+type Pattern interface {
+	AstNode
+	PatternNode()
+}
+
+type WildcardPattern struct {
+	Token token.Token
+}
+
+func (wp *WildcardPattern) PatternNode()   {}
+func (wp *WildcardPattern) String() string { return "_" }
+
+// binds the value to a name
+type BindingPattern struct {
+	Ident IdentiferLiteral
+}
+
+func (bp *BindingPattern) PatternNode() {}
+func (bp *BindingPattern) String() string {
+	var output string
+
+	output += "Bind("
+	output += bp.Ident.Ident
+	output += ")"
+
+	return output
+}
+
+type LiteralPattern struct {
+	Value AstExpression
+}
+
+func (lp *LiteralPattern) PatternNode()   {}
+func (lp *LiteralPattern) String() string { return lp.Value.String() }
+
+// matches values from Low to High, both included
+type RangePattern struct {
+	Low  AstExpression
+	High AstExpression
+}
+
+func (rp *RangePattern) PatternNode() {}
+func (rp *RangePattern) String() string {
+	var output string
+
+	output += "Range("
+	output += rp.Low.String()
+	output += rp.High.String()
+	output += ")"
+
+	return output
+}
+
+type VariantPattern struct {
+	Enum    IdentiferLiteral
+	Variant IdentiferLiteral
+	Fields  []Pattern
+}
+
+func (vp *VariantPattern) PatternNode() {}
+func (vp *VariantPattern) String() string {
+	var output string
+
+	output += "Variant("
+	output += vp.Enum.Ident + "." + vp.Variant.Ident
+	for _, field := range vp.Fields {
+		output += field.String()
+	}
+	output += ")"
+
+	return output
+}
+
+type ArrayPattern struct {
+	Elements []Pattern
+	// binds the elements after the others, nil when the length must match
+	Rest *IdentiferLiteral
+}
+
+func (ap *ArrayPattern) PatternNode() {}
+func (ap *ArrayPattern) String() string {
+	var output string
+
+	output += "ArrayPattern("
+	for _, element := range ap.Elements {
+		output += element.String()
+	}
+	if ap.Rest != nil {
+		output += "Rest(" + ap.Rest.Ident + ")"
+	}
+	output += ")"
+
+	return output
+}
+
+// matches hashes that have the keys, other keys are ignored
+type HashPattern struct {
+	Keys   []AstExpression
+	Values []Pattern
+}
+
+func (hp *HashPattern) PatternNode() {}
+func (hp *HashPattern) String() string {
+	var output string
+
+	output += "HashPattern("
+	for i, key := range hp.Keys {
+		output += key.String()
+		output += hp.Values[i].String()
+	}
+	output += ")"
+
+	return output
+}
@@ -0,0 +1,218 @@
+package builtins
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["assert"] = objects.Builtin{Fn: assert, MinArgs: 1, MaxArgs: 2}
+	BuiltIns["assert_eq"] = objects.Builtin{Fn: assertEq, MinArgs: 2, MaxArgs: 3}
+	BuiltIns["assert_raises"] = objects.Builtin{Native: assertRaises, MinArgs: 1, MaxArgs: 2}
+}
+
+// assert(condition, message?)
+func assert(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	condition, ok := args[0].(*objects.Boolean)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `assert` must be BOOLEAN, got %s", typeOf(args[0]))
+	}
+	if !condition.Value {
+		return objects.NewErrorKind(objects.ASSERTION_ERROR, "assertion failed%s", message(args[1:]))
+	}
+	return &objects.Null{}
+}
+
+// assert_eq(got, want, message?)
+func assertEq(args ...objects.Object) objects.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	got, want := args[0], args[1]
+	if objects.Equal(got, want) {
+		return &objects.Null{}
+	}
+
+	var out string
+	out += "assert_eq failed" + message(args[2:]) + "\n"
+	out += "    got:  " + repr(got) + "\n"
+	out += "    want: " + repr(want)
+	if path, detail := diff(got, want, ""); path != "" {
+		out += "\n    diff: at " + path + ": " + detail
+	}
+	return objects.NewErrorKind(objects.ASSERTION_ERROR, "%s", out)
+}
+
+// assert_raises(fn, message?) calls fn and passes if it fails, with an
+// error containing message when given
+func assertRaises(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	var expected string
+	if len(args) == 2 {
+		str, ok := args[1].(*objects.String)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `assert_raises` must be STRING, got %s", typeOf(args[1]))
+		}
+		expected = str.Value
+	}
+
+	result := interp.Call(args[0])
+	err, ok := result.(*objects.Error)
+	if ok && err.Fatal {
+		return err
+	}
+	if !ok {
+		return objects.NewErrorKind(objects.ASSERTION_ERROR, "assert_raises failed: no error was raised")
+	}
+	if !strings.Contains(err.Message, expected) {
+		return objects.NewErrorKind(objects.ASSERTION_ERROR, "assert_raises failed: error %q does not contain %q", err.Message, expected)
+	}
+	return &objects.Null{}
+}
+
+func message(args []objects.Object) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return ": " + args[0].Inspect()
+}
+
+func typeOf(obj objects.Object) objects.ObjectType {
+	if obj == nil {
+		return objects.NULL
+	}
+	return obj.Type()
+}
+
+// a description of a value that tells strings and numbers apart
+func repr(obj objects.Object) string {
+	return represent(obj, map[*objects.Struct]bool{})
+}
+
+// a struct met again inside itself is written as Name{...}
+func represent(obj objects.Object, visiting map[*objects.Struct]bool) string {
+	switch obj := obj.(type) {
+	case nil:
+		return "null"
+	case *objects.String:
+		return strconv.Quote(obj.Value)
+	case *objects.Float:
+		text := obj.Inspect()
+		if !strings.Contains(text, ".") && !math.IsInf(obj.Value, 0) && !math.IsNaN(obj.Value) {
+			// tells 2.0 apart from 2
+			text += ".0"
+		}
+		return text
+	case *objects.Array:
+		var elements []string
+		for _, element := range obj.Elements {
+			elements = append(elements, represent(element, visiting))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *objects.Hash:
+		var pairs []string
+		for _, pair := range obj.Ordered() {
+			pairs = append(pairs, represent(pair.Key, visiting)+": "+represent(pair.Value, visiting))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case *objects.Struct:
+		if visiting[obj] {
+			return obj.Def.Name + "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+		var fields []string
+		for _, name := range obj.Def.Fields {
+			fields = append(fields, name+": "+represent(obj.Fields[name], visiting))
+		}
+		return obj.Def.Name + "{" + strings.Join(fields, ", ") + "}"
+	}
+	return obj.Inspect()
+}
+
+func sortedPairs(hash *objects.Hash) []objects.HashPair {
+	var pairs []objects.HashPair
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return repr(pairs[i].Key) < repr(pairs[j].Key) })
+	return pairs
+}
+
+// finds the first place two unequal values differ, returning its path and
+// what is different there
+func diff(got, want objects.Object, path string) (string, string) {
+	if typeOf(got) != typeOf(want) {
+		if path == "" {
+			return "", ""
+		}
+		return path, fmt.Sprintf("got %s, want %s", repr(got), repr(want))
+	}
+
+	switch got := got.(type) {
+	case *objects.Array:
+		want := want.(*objects.Array)
+		for i := 0; i < len(got.Elements) && i < len(want.Elements); i++ {
+			if !objects.Equal(got.Elements[i], want.Elements[i]) {
+				return diff(got.Elements[i], want.Elements[i], fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+		return pathOrRoot(path), fmt.Sprintf("got %d elements, want %d", len(got.Elements), len(want.Elements))
+	case *objects.Hash:
+		want := want.(*objects.Hash)
+		for _, pair := range sortedPairs(want) {
+			gotPair, ok := got.Pairs[pair.Key.(objects.Hashable).HashKey()]
+			if !ok {
+				return pathOrRoot(path), "missing key " + repr(pair.Key)
+			}
+			if !objects.Equal(gotPair.Value, pair.Value) {
+				return diff(gotPair.Value, pair.Value, path+"["+repr(pair.Key)+"]")
+			}
+		}
+		for _, pair := range sortedPairs(got) {
+			if _, ok := want.Pairs[pair.Key.(objects.Hashable).HashKey()]; !ok {
+				return pathOrRoot(path), "unexpected key " + repr(pair.Key)
+			}
+		}
+	case *objects.Struct:
+		want := want.(*objects.Struct)
+		if got.Def != want.Def {
+			break
+		}
+		for _, name := range got.Def.Fields {
+			if !objects.Equal(got.Fields[name], want.Fields[name]) {
+				return diff(got.Fields[name], want.Fields[name], path+"."+name)
+			}
+		}
+	case *objects.String:
+		want := want.(*objects.String)
+		i := 0
+		for i < len(got.Value) && i < len(want.Value) && got.Value[i] == want.Value[i] {
+			i++
+		}
+		if path == "" {
+			return "byte " + strconv.Itoa(i), fmt.Sprintf("got %s, want %s", strconv.Quote(got.Value[i:]), strconv.Quote(want.Value[i:]))
+		}
+	}
+	if path == "" {
+		return "", ""
+	}
+	return path, fmt.Sprintf("got %s, want %s", repr(got), repr(want))
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "top level"
+	}
+	return path
+}
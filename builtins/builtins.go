@@ -1,22 +1,38 @@
 package builtins
 
 import (
-	"bufio"
 	"fmt"
-	"math/rand"
-	"os"
+	"math"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/EVFUBS/AlphaLang/objects"
 )
 
+// modules like fs, each registered by the file that implements it
+var Modules = map[string]*objects.Module{}
+
 var BuiltIns = map[string]objects.Builtin{
+	"args": {
+		Returns: objects.ARRAY_TYPE,
+		Native: func(interp objects.Interpreter, args ...objects.Object) objects.Object {
+			if len(args) != 0 {
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+			}
+			elements := []objects.Object{}
+			for _, arg := range interp.ScriptArgs() {
+				elements = append(elements, &objects.String{Value: arg})
+			}
+			return &objects.Array{Elements: elements}
+		},
+	},
 	"len": {
+		MinArgs: 1,
+		MaxArgs: 1,
+		Returns: objects.INT_TYPE,
 		Fn: func(args ...objects.Object) objects.Object {
 			if len(args) != 1 {
-				return objects.NewError("wrong number of arguments. got=%d, want=1", len(args))
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 
 			switch arg := args[0].(type) {
@@ -25,11 +41,13 @@ var BuiltIns = map[string]objects.Builtin{
 			case *objects.Array:
 				return &objects.Integer{Value: int64(len(arg.Elements))}
 			default:
-				return objects.NewError("argument to `len` not supported, got %s", args[0].Type())
+				return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `len` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 	"append": {
+		MinArgs: 2,
+		MaxArgs: 3,
 		Fn: func(args ...objects.Object) objects.Object {
 			if len(args) == 2 {
 				//append array
@@ -42,57 +60,69 @@ var BuiltIns = map[string]objects.Builtin{
 				if arg, ok := args[0].(*objects.Hash); ok {
 					key, ok := args[1].(objects.Hashable)
 					if !ok {
-						return objects.NewError("unusable as hash key: %s", args[1].Type())
+						return objects.NewErrorKind(objects.TYPE_ERROR, "unusable as hash key: %s", args[1].Type())
 					}
-					hashKey := key.HashKey()
-					arg.Pairs[hashKey] = objects.HashPair{Key: args[1], Value: args[2]}
+					arg.Set(key.HashKey(), objects.HashPair{Key: args[1], Value: args[2]})
 				}
 				return nil
 			} else {
-				return objects.NewError("arguement to `append` not supported, got %s, %s", args[0].Type(), args[1].Type())
+				return objects.NewErrorKind(objects.TYPE_ERROR, "arguement to `append` not supported, got %s, %s", args[0].Type(), args[1].Type())
 			}
 		},
 	},
 	"pop": {
+		MinArgs: 1,
+		MaxArgs: 1,
 		Fn: func(args ...objects.Object) objects.Object {
 			if len(args) != 1 {
-				return objects.NewError("wrong number of arguments. got=%d, want=1", len(args))
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 			if arg, ok := args[0].(*objects.Array); ok {
 				last := arg.Elements[len(arg.Elements)-1]
 				arg.Elements = arg.Elements[:len(arg.Elements)-1]
 				return last
 			} else {
-				return objects.NewError("argument to `pop` not supported, got %s", args[0].Type())
+				return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `pop` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 
 	"println": {
-		Fn: func(args ...objects.Object) objects.Object {
+		MaxArgs: -1,
+		Native: func(interp objects.Interpreter, args ...objects.Object) objects.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(interp.Output(), arg.Inspect())
 			}
 			return nil
 		},
 	},
 	"print": {
-		Fn: func(args ...objects.Object) objects.Object {
+		MaxArgs: -1,
+		Native: func(interp objects.Interpreter, args ...objects.Object) objects.Object {
 			for _, arg := range args {
-				fmt.Print(arg.Inspect())
+				fmt.Fprint(interp.Output(), arg.Inspect())
 			}
 			return nil
 		},
 	},
 
 	"int": {
+		MinArgs: 1,
+		MaxArgs: 1,
+		Returns: objects.INT_TYPE,
 		Fn: func(args ...objects.Object) objects.Object {
 			if len(args) != 1 {
-				return objects.NewError("wrong number of arguments. got=%d, want=1", len(args))
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *objects.Integer:
 				return arg
+			case *objects.Float:
+				//drops the fraction, rounding toward zero
+				if math.IsNaN(arg.Value) || arg.Value <= math.MinInt64-1 || arg.Value >= math.MaxInt64 {
+					return objects.NewErrorKind(objects.VALUE_ERROR, "could not convert %s to integer", arg.Inspect())
+				}
+				return &objects.Integer{Value: int64(arg.Value)}
 			case *objects.Boolean:
 				if arg.Value {
 					return &objects.Integer{Value: 1}
@@ -101,53 +131,39 @@ var BuiltIns = map[string]objects.Builtin{
 			case *objects.String:
 				value, err := strconv.ParseInt(arg.Value, 0, 64)
 				if err != nil {
-					return objects.NewError("could not convert %q to integer", arg.Value)
+					return objects.NewErrorKind(objects.VALUE_ERROR, "could not convert %q to integer", arg.Value)
 				}
 				return &objects.Integer{Value: value}
 			default:
-				return objects.NewError("argument to `int` not supported, got %s", args[0].Type())
+				return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `int` not supported, got %s", args[0].Type())
 			}
 		},
 	},
 
 	"input": {
-		Fn: func(args ...objects.Object) objects.Object {
+		MinArgs: 1,
+		MaxArgs: 1,
+		Returns: objects.STRING_TYPE,
+		Native: func(interp objects.Interpreter, args ...objects.Object) objects.Object {
 			if len(args) != 1 {
-				return objects.NewError("wrong number of arguments. got=%d, want=1", len(args))
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
 			}
 			switch arg := args[0].(type) {
 			case *objects.String:
-				fmt.Println(arg.Value)
-				reader := bufio.NewReader(os.Stdin)
-				input, err := reader.ReadString('\n')
+				input, err := interp.ReadLine(arg.Value)
 				if err != nil {
-					fmt.Println(err)
+					return objects.NewErrorKind(objects.IO_ERROR, "input: %s", err)
 				}
-				input = strings.TrimSpace(input)
-				return &objects.String{Value: input}
+				return &objects.String{Value: strings.TrimSpace(input)}
 			default:
-				return objects.NewError("argument to `input` not supported, got %s", args[0].Type())
+				return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `input` not supported, got %s", args[0].Type())
 			}
 		},
 	},
+}
 
-	"rand": {
-		Fn: func(args ...objects.Object) objects.Object {
-			//rand rumber between two integers
-			source := rand.NewSource(time.Now().UnixNano())
-			rand := rand.New(source)
-			if len(args) == 2 {
-				if arg1, ok := args[0].(*objects.Integer); ok {
-					if arg2, ok := args[1].(*objects.Integer); ok {
-						return &objects.Integer{Value: int64(rand.Intn(int(arg2.Value-arg1.Value)) + int(arg1.Value))}
-					}
-				}
-			} else if len(args) == 1 {
-				if arg1, ok := args[0].(*objects.Integer); ok {
-					return &objects.Integer{Value: int64(rand.Intn(int(arg1.Value)))}
-				}
-			}
-			return objects.NewError("argument to `rand` not supported, got %s, %s", args[0].Type(), args[1].Type())
-		},
-	},
+// sets the value under a string key
+func setString(hash *objects.Hash, key string, value objects.Object) {
+	str := &objects.String{Value: key}
+	hash.Set(str.HashKey(), objects.HashPair{Key: str, Value: value})
 }
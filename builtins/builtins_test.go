@@ -0,0 +1,91 @@
+package builtins_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+// runs the program with the capabilities and returns the value of its last
+// statement
+func run(t *testing.T, input string, capabilities ...string) objects.Object {
+	t.Helper()
+	program, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("parsing %q failed: %s", input, err)
+	}
+	e := evaluator.New()
+	e.Capabilities = map[string]bool{}
+	for _, capability := range capabilities {
+		e.Capabilities[capability] = true
+	}
+	e.Stdout = io.Discard
+	e.Stderr = io.Discard
+	e.Stdin = evaluator.NoInput
+	return e.Eval(program, e.Env)
+}
+
+type builtinTest struct {
+	input string
+	// repr of the result, or part of the message when kind is set
+	want string
+	kind string
+}
+
+func runTests(t *testing.T, tests []builtinTest, capabilities ...string) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			checkResult(t, run(t, tt.input, capabilities...), tt.want, tt.kind)
+		})
+	}
+}
+
+// runs each program in a new directory holding files, the program sees the
+// directory as dir and DIR in want stands for it
+func runFileTests(t *testing.T, tests []builtinTest, files map[string]string, capabilities ...string) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := run(t, "var dir = \""+dir+"\"\n"+tt.input, capabilities...)
+			checkResult(t, got, strings.ReplaceAll(tt.want, "DIR", dir), tt.kind)
+		})
+	}
+}
+
+func checkResult(t *testing.T, got objects.Object, want, kind string) {
+	t.Helper()
+	err, isErr := got.(*objects.Error)
+	if kind != "" {
+		if !isErr || err.Kind != kind || !strings.Contains(err.Message, want) {
+			t.Fatalf("got %s, want %s containing %q", repr(got), kind, want)
+		}
+		return
+	}
+	if isErr {
+		t.Fatalf("got %s: %s, want %s", err.Kind, err.Message, want)
+	}
+	if got := repr(got); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func repr(obj objects.Object) string {
+	if err, ok := obj.(*objects.Error); ok {
+		return err.Kind + ": " + err.Message
+	}
+	return builtins.BuiltIns["repr"].Fn(obj).Inspect()
+}
@@ -0,0 +1,634 @@
+package builtins
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["map"] = objects.Builtin{Native: mapBuiltin, MinArgs: 2, MaxArgs: 2}
+	BuiltIns["filter"] = objects.Builtin{Native: filter, MinArgs: 2, MaxArgs: 2}
+	BuiltIns["reduce"] = objects.Builtin{Native: reduce, MinArgs: 2, MaxArgs: 3}
+	BuiltIns["sort"] = objects.Builtin{Native: sortBuiltin, MinArgs: 1, MaxArgs: 2, Returns: objects.ARRAY_TYPE}
+	BuiltIns["sort_by"] = objects.Builtin{Native: sortBy, MinArgs: 2, MaxArgs: 2, Returns: objects.ARRAY_TYPE}
+	BuiltIns["reverse"] = objects.Builtin{Fn: reverse, MinArgs: 1, MaxArgs: 1}
+	BuiltIns["zip"] = objects.Builtin{Fn: zip, MinArgs: 1, MaxArgs: -1, Returns: objects.ARRAY_TYPE}
+	BuiltIns["enumerate"] = objects.Builtin{Fn: enumerate, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE}
+	BuiltIns["any"] = objects.Builtin{Native: anyBuiltin, MinArgs: 1, MaxArgs: 2, Returns: objects.BOOL_TYPE}
+	BuiltIns["all"] = objects.Builtin{Native: all, MinArgs: 1, MaxArgs: 2, Returns: objects.BOOL_TYPE}
+	BuiltIns["sum"] = objects.Builtin{Fn: sum, MinArgs: 1, MaxArgs: 1}
+	BuiltIns["min"] = objects.Builtin{Fn: minBuiltin, MinArgs: 1, MaxArgs: -1}
+	BuiltIns["max"] = objects.Builtin{Fn: maxBuiltin, MinArgs: 1, MaxArgs: -1}
+	BuiltIns["unique"] = objects.Builtin{Fn: unique, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE}
+	BuiltIns["flatten"] = objects.Builtin{Fn: flatten, MinArgs: 1, MaxArgs: 2, Returns: objects.ARRAY_TYPE}
+	BuiltIns["chunk"] = objects.Builtin{Fn: chunk, MinArgs: 2, MaxArgs: 2, Returns: objects.ARRAY_TYPE}
+	BuiltIns["group_by"] = objects.Builtin{Native: groupBy, MinArgs: 2, MaxArgs: 2, Returns: objects.HASH_TYPE}
+}
+
+// map(collection, fn) calls fn with each element of an array, or with the
+// key and value of each pair of a hash, and collects the results
+func mapBuiltin(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	if err := checkFunction("map", args[1]); err != nil {
+		return err
+	}
+	switch collection := args[0].(type) {
+	case *objects.Array:
+		elements := make([]objects.Object, len(collection.Elements))
+		for i, element := range collection.Elements {
+			result, err := call(interp, args[1], element)
+			if err != nil {
+				return err
+			}
+			elements[i] = result
+		}
+		return &objects.Array{Elements: elements}
+	case *objects.Hash:
+		hash := objects.NewHash()
+		for _, pair := range collection.Ordered() {
+			result, err := call(interp, args[1], pair.Key, pair.Value)
+			if err != nil {
+				return err
+			}
+			hash.Set(pair.Key.(objects.Hashable).HashKey(), objects.HashPair{Key: pair.Key, Value: result})
+		}
+		return hash
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `map` must be ARRAY or HASH, got %s", typeOf(args[0]))
+}
+
+// filter(collection, fn) keeps the elements, or the pairs of a hash, that
+// fn returns true for
+func filter(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	if err := checkFunction("filter", args[1]); err != nil {
+		return err
+	}
+	switch collection := args[0].(type) {
+	case *objects.Array:
+		elements := []objects.Object{}
+		for _, element := range collection.Elements {
+			keep, err := test(interp, "filter", args[1], element)
+			if err != nil {
+				return err
+			}
+			if keep {
+				elements = append(elements, element)
+			}
+		}
+		return &objects.Array{Elements: elements}
+	case *objects.Hash:
+		hash := objects.NewHash()
+		for _, pair := range collection.Ordered() {
+			keep, err := test(interp, "filter", args[1], pair.Key, pair.Value)
+			if err != nil {
+				return err
+			}
+			if keep {
+				hash.Set(pair.Key.(objects.Hashable).HashKey(), pair)
+			}
+		}
+		return hash
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `filter` must be ARRAY or HASH, got %s", typeOf(args[0]))
+}
+
+// reduce(array, fn, initial?) folds the elements from the left with
+// fn(accumulator, element), starting from the first element when no initial
+// value is given
+func reduce(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `reduce` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	if err := checkFunction("reduce", args[1]); err != nil {
+		return err
+	}
+	elements := arr.Elements
+	var acc objects.Object
+	if len(args) == 3 {
+		acc = args[2]
+	} else {
+		if len(elements) == 0 {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "reduce of an empty array with no initial value")
+		}
+		acc, elements = elements[0], elements[1:]
+	}
+	for _, element := range elements {
+		result, err := call(interp, args[1], acc, element)
+		if err != nil {
+			return err
+		}
+		acc = result
+	}
+	return acc
+}
+
+// sort(array, compare?) returns the elements in ascending order, compare(a, b)
+// returns a negative integer when a goes first, zero when they are equal and a
+// positive integer when b goes first
+func sortBuiltin(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `sort` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	elements := copyElements(arr.Elements)
+	if len(args) == 1 {
+		return sortElements(elements, elements)
+	}
+	if err := checkFunction("sort", args[1]); err != nil {
+		return err
+	}
+
+	var failed objects.Object
+	sort.SliceStable(elements, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		result, err := call(interp, args[1], elements[i], elements[j])
+		if err != nil {
+			failed = err
+			return false
+		}
+		order, ok := result.(*objects.Integer)
+		if !ok {
+			failed = objects.NewErrorKind(objects.TYPE_ERROR, "comparator of `sort` must return INTEGER, got %s", typeOf(result))
+			return false
+		}
+		return order.Value < 0
+	})
+	if failed != nil {
+		return failed
+	}
+	return &objects.Array{Elements: elements}
+}
+
+// sort_by(array, fn) returns the elements in ascending order of fn(element)
+func sortBy(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `sort_by` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	if err := checkFunction("sort_by", args[1]); err != nil {
+		return err
+	}
+	keys := make([]objects.Object, len(arr.Elements))
+	for i, element := range arr.Elements {
+		key, err := call(interp, args[1], element)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+	return sortElements(copyElements(arr.Elements), keys)
+}
+
+// reverse(collection) returns the elements of an array or the characters of
+// a string in reverse order
+func reverse(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *objects.Array:
+		elements := make([]objects.Object, len(arg.Elements))
+		for i, element := range arg.Elements {
+			elements[len(elements)-1-i] = element
+		}
+		return &objects.Array{Elements: elements}
+	case *objects.String:
+		runes := []rune(arg.Value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return &objects.String{Value: string(runes)}
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `reverse` must be ARRAY or STRING, got %s", typeOf(args[0]))
+}
+
+// zip(arrays...) pairs up the elements at the same index, stopping at the
+// end of the shortest array
+func zip(args ...objects.Object) objects.Object {
+	if len(args) < 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or more", len(args))
+	}
+	arrays := make([]*objects.Array, len(args))
+	length := -1
+	for i, arg := range args {
+		arr, ok := arg.(*objects.Array)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "arguments to `zip` must be ARRAY, got %s", typeOf(arg))
+		}
+		arrays[i] = arr
+		if length < 0 || len(arr.Elements) < length {
+			length = len(arr.Elements)
+		}
+	}
+	elements := make([]objects.Object, length)
+	for i := range elements {
+		tuple := make([]objects.Object, len(arrays))
+		for j, arr := range arrays {
+			tuple[j] = arr.Elements[i]
+		}
+		elements[i] = &objects.Array{Elements: tuple}
+	}
+	return &objects.Array{Elements: elements}
+}
+
+// enumerate(array) pairs each element with its index
+func enumerate(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `enumerate` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	elements := make([]objects.Object, len(arr.Elements))
+	for i, element := range arr.Elements {
+		elements[i] = &objects.Array{Elements: []objects.Object{&objects.Integer{Value: int64(i)}, element}}
+	}
+	return &objects.Array{Elements: elements}
+}
+
+// any(collection, fn?) reports whether fn returns true for some element, or
+// without fn whether some element is true
+func anyBuiltin(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	return quantify(interp, "any", true, args)
+}
+
+// all(collection, fn?) reports whether fn returns true for every element, or
+// without fn whether every element is true
+func all(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	return quantify(interp, "all", false, args)
+}
+
+// stops at the first element the test gives stop for
+func quantify(interp objects.Interpreter, name string, stop bool, args []objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	if len(args) == 2 {
+		if err := checkFunction(name, args[1]); err != nil {
+			return err
+		}
+	}
+	var tuples [][]objects.Object
+	switch collection := args[0].(type) {
+	case *objects.Array:
+		for _, element := range collection.Elements {
+			tuples = append(tuples, []objects.Object{element})
+		}
+	case *objects.Hash:
+		if len(args) == 1 {
+			return objects.NewErrorKind(objects.ARGUMENT_ERROR, "`%s` of a HASH needs a function", name)
+		}
+		for _, pair := range collection.Ordered() {
+			tuples = append(tuples, []objects.Object{pair.Key, pair.Value})
+		}
+	default:
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `%s` must be ARRAY or HASH, got %s", name, typeOf(args[0]))
+	}
+	for _, tuple := range tuples {
+		var value bool
+		if len(args) == 2 {
+			result, err := test(interp, name, args[1], tuple...)
+			if err != nil {
+				return err
+			}
+			value = result
+		} else {
+			b, ok := tuple[0].(*objects.Boolean)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "elements of `%s` must be BOOLEAN, got %s", name, typeOf(tuple[0]))
+			}
+			value = b.Value
+		}
+		if value == stop {
+			return &objects.Boolean{Value: stop}
+		}
+	}
+	return &objects.Boolean{Value: !stop}
+}
+
+// sum(array) adds up the numbers, the result is a FLOAT when any of them is
+func sum(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `sum` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	var total int64
+	var floatTotal float64
+	isFloat := false
+	for _, element := range arr.Elements {
+		switch element := element.(type) {
+		case *objects.Integer:
+			total += element.Value
+		case *objects.Float:
+			floatTotal += element.Value
+			isFloat = true
+		default:
+			return objects.NewErrorKind(objects.TYPE_ERROR, "elements of `sum` must be INTEGER or FLOAT, got %s", typeOf(element))
+		}
+	}
+	if isFloat {
+		return &objects.Float{Value: floatTotal + float64(total)}
+	}
+	return &objects.Integer{Value: total}
+}
+
+// min(array) or min(values...)
+func minBuiltin(args ...objects.Object) objects.Object {
+	return extreme("min", -1, args)
+}
+
+// max(array) or max(values...)
+func maxBuiltin(args ...objects.Object) objects.Object {
+	return extreme("max", 1, args)
+}
+
+// the first value that compares as sign against all the others
+func extreme(name string, sign int, args []objects.Object) objects.Object {
+	if len(args) < 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or more", len(args))
+	}
+	values := args
+	if len(args) == 1 {
+		arr, ok := args[0].(*objects.Array)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `%s` must be ARRAY, got %s", name, typeOf(args[0]))
+		}
+		if len(arr.Elements) == 0 {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "`%s` of an empty array", name)
+		}
+		values = arr.Elements
+	}
+	result := values[0]
+	for _, value := range values[1:] {
+		order, err := compare(value, result)
+		if err != nil {
+			return err
+		}
+		if order == sign {
+			result = value
+		}
+	}
+	return result
+}
+
+// unique(array) drops the elements equal to one before them
+func unique(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `unique` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	seen := make(map[objects.HashKey]bool)
+	elements := []objects.Object{}
+	for _, element := range arr.Elements {
+		if key, ok := hashKey(element); ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		} else if contains(elements, element) {
+			continue
+		}
+		elements = append(elements, element)
+	}
+	return &objects.Array{Elements: elements}
+}
+
+// flatten(array, depth?) replaces nested arrays with their elements, one
+// level deep unless depth says otherwise
+func flatten(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `flatten` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	depth := int64(1)
+	if len(args) == 2 {
+		d, ok := args[1].(*objects.Integer)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `flatten` must be INTEGER, got %s", typeOf(args[1]))
+		}
+		depth = d.Value
+	}
+	return &objects.Array{Elements: flattenElements(arr.Elements, depth)}
+}
+
+func flattenElements(elements []objects.Object, depth int64) []objects.Object {
+	result := []objects.Object{}
+	for _, element := range elements {
+		if inner, ok := element.(*objects.Array); ok && depth > 0 {
+			result = append(result, flattenElements(inner.Elements, depth-1)...)
+			continue
+		}
+		result = append(result, element)
+	}
+	return result
+}
+
+// chunk(array, size) splits the elements into arrays of size, the last one
+// holds what is left
+func chunk(args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `chunk` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	size, ok := args[1].(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `chunk` must be INTEGER, got %s", typeOf(args[1]))
+	}
+	if size.Value <= 0 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "chunk size must be positive, got %d", size.Value)
+	}
+	chunks := []objects.Object{}
+	for start := 0; start < len(arr.Elements); start += int(size.Value) {
+		end := start + int(size.Value)
+		if end > len(arr.Elements) {
+			end = len(arr.Elements)
+		}
+		chunks = append(chunks, &objects.Array{Elements: copyElements(arr.Elements[start:end])})
+	}
+	return &objects.Array{Elements: chunks}
+}
+
+// group_by(array, fn) builds a hash from each fn(element) to the elements
+// that gave it, in their original order
+func groupBy(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `group_by` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	if err := checkFunction("group_by", args[1]); err != nil {
+		return err
+	}
+	groups := objects.NewHash()
+	for _, element := range arr.Elements {
+		key, err := call(interp, args[1], element)
+		if err != nil {
+			return err
+		}
+		hashKey, ok := hashKey(key)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "unusable as hash key: %s", typeOf(key))
+		}
+		pair, ok := groups.Pairs[hashKey]
+		if !ok {
+			pair = objects.HashPair{Key: key, Value: &objects.Array{}}
+			groups.Set(hashKey, pair)
+		}
+		group := pair.Value.(*objects.Array)
+		group.Elements = append(group.Elements, element)
+	}
+	return groups
+}
+
+// calls fn, a nil result is returned as null and errors are returned apart
+func call(interp objects.Interpreter, fn objects.Object, args ...objects.Object) (objects.Object, objects.Object) {
+	result := interp.Call(fn, args...)
+	if _, ok := result.(*objects.Error); ok {
+		return nil, result
+	}
+	if result == nil {
+		return &objects.Null{}, nil
+	}
+	return result, nil
+}
+
+// calls fn and expects a BOOLEAN back
+func test(interp objects.Interpreter, name string, fn objects.Object, args ...objects.Object) (bool, objects.Object) {
+	result, err := call(interp, fn, args...)
+	if err != nil {
+		return false, err
+	}
+	b, ok := result.(*objects.Boolean)
+	if !ok {
+		return false, objects.NewErrorKind(objects.TYPE_ERROR, "function passed to `%s` must return BOOLEAN, got %s", name, typeOf(result))
+	}
+	return b.Value, nil
+}
+
+func checkFunction(name string, obj objects.Object) objects.Object {
+	switch obj.(type) {
+	case *objects.Function, *objects.Builtin, *objects.StructType:
+		return nil
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `%s` must be FUNCTION, got %s", name, typeOf(obj))
+}
+
+// orders two numbers or two strings, ints and floats compare by value
+func compare(a, b objects.Object) (int, objects.Object) {
+	if x, ok := a.(*objects.String); ok {
+		if y, ok := b.(*objects.String); ok {
+			return strings.Compare(x.Value, y.Value), nil
+		}
+	}
+	if x, ok := a.(*objects.Integer); ok {
+		if y, ok := b.(*objects.Integer); ok {
+			switch {
+			case x.Value < y.Value:
+				return -1, nil
+			case x.Value > y.Value:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	x, xok := objects.Number(a)
+	y, yok := objects.Number(b)
+	if !xok || !yok {
+		return 0, objects.NewErrorKind(objects.TYPE_ERROR, "cannot compare %s with %s", typeOf(a), typeOf(b))
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// sorts the elements by the keys at the same index
+func sortElements(elements, keys []objects.Object) objects.Object {
+	indexes := make([]int, len(elements))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var failed objects.Object
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		order, err := compare(keys[indexes[i]], keys[indexes[j]])
+		if err != nil {
+			failed = err
+			return false
+		}
+		return order < 0
+	})
+	if failed != nil {
+		return failed
+	}
+	sorted := make([]objects.Object, len(elements))
+	for i, index := range indexes {
+		sorted[i] = elements[index]
+	}
+	return &objects.Array{Elements: sorted}
+}
+
+func copyElements(elements []objects.Object) []objects.Object {
+	return append([]objects.Object{}, elements...)
+}
+
+// the key of a value that can be looked up by it, hashes compare by content
+// so they have none
+func hashKey(obj objects.Object) (objects.HashKey, bool) {
+	if _, ok := obj.(*objects.Hash); ok {
+		return objects.HashKey{}, false
+	}
+	hashable, ok := obj.(objects.Hashable)
+	if !ok {
+		return objects.HashKey{}, false
+	}
+	return hashable.HashKey(), true
+}
+
+func contains(elements []objects.Object, obj objects.Object) bool {
+	for _, element := range elements {
+		if objects.Equal(element, obj) {
+			return true
+		}
+	}
+	return false
+}
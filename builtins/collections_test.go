@@ -0,0 +1,46 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestCollections(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "map([3, 1, 2], x => x * 2)", want: "[6, 2, 4]"},
+		{input: "map({\"ann\": 30, \"bob\": 20}, (name, age) => age + 1)", want: "{\"ann\": 31, \"bob\": 21}"},
+		{input: "filter([3, 1, 2], x => x > 1)", want: "[3, 2]"},
+		{input: "filter({\"ann\": 30, \"bob\": 20}, (name, age) => age > 25)", want: "{\"ann\": 30}"},
+		{input: "reduce([3, 1, 2], (acc, x) => acc + x)", want: "6"},
+		{input: "reduce([3, 1, 2], (acc, x) => acc + x, 10)", want: "16"},
+		{input: "sort([3, 1, 2])", want: "[1, 2, 3]"},
+		{input: "var xs = [3, 1, 2]\nsort(xs)\nxs", want: "[3, 1, 2]"},
+		{input: "sort([3, 1, 2], (a, b) => b - a)", want: "[3, 2, 1]"},
+		{input: "sort([\"b\", \"a\", \"c\"])", want: "[\"a\", \"b\", \"c\"]"},
+		{input: "sort_by([\"ccc\", \"a\", \"bb\"], len)", want: "[\"a\", \"bb\", \"ccc\"]"},
+		{input: "reverse([3, 1, 2])", want: "[2, 1, 3]"},
+		{input: "reverse(\"abc\")", want: "\"cba\""},
+		{input: "zip([3, 1, 2], [\"a\", \"b\"])", want: "[[3, \"a\"], [1, \"b\"]]"},
+		{input: "enumerate([\"a\", \"b\"])", want: "[[0, \"a\"], [1, \"b\"]]"},
+		{input: "any([3, 1, 2], x => x > 2)", want: "true"},
+		{input: "any({\"ann\": 30}, (name, age) => name == \"bob\")", want: "false"},
+		{input: "all([3, 1, 2], x => x > 2)", want: "false"},
+		{input: "all([])", want: "true"},
+		{input: "sum([3, 1, 2])", want: "6"},
+		{input: "sum([1, 0.5])", want: "1.5"},
+		{input: "min([3, 1, 2])", want: "1"},
+		{input: "max(4, 9, 2)", want: "9"},
+		{input: "unique([1, 2, 1, [3], [3]])", want: "[1, 2, [3]]"},
+		{input: "flatten([1, [2, [3]]])", want: "[1, 2, [3]]"},
+		{input: "flatten([1, [2, [3]]], 2)", want: "[1, 2, 3]"},
+		{input: "chunk([1, 2, 3, 4, 5], 2)", want: "[[1, 2], [3, 4], [5]]"},
+		{input: "group_by([\"ab\", \"cd\", \"efg\"], len)", want: "{2: [\"ab\", \"cd\"], 3: [\"efg\"]}"},
+		{input: "reduce([], (a, b) => a)", kind: objects.VALUE_ERROR, want: "empty array"},
+		{input: "min([1, \"a\"])", kind: objects.TYPE_ERROR, want: "cannot compare"},
+		{input: "map([1], 2)", kind: objects.TYPE_ERROR, want: "must be"},
+		{input: "chunk([1], 0)", kind: objects.VALUE_ERROR, want: ""},
+		{input: "sort()", kind: objects.ARGUMENT_ERROR, want: "wrong number of arguments"},
+		{input: "map([1, 2], x => x + \"a\")", kind: objects.TYPE_ERROR, want: "type mismatch: INTEGER + STRING"},
+	})
+}
@@ -0,0 +1,278 @@
+package builtins
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["float"] = objects.Builtin{Fn: float, MinArgs: 1, MaxArgs: 1, Returns: objects.FLOAT_TYPE}
+	BuiltIns["str"] = objects.Builtin{Fn: str, MinArgs: 1, MaxArgs: 1, Returns: objects.STRING_TYPE}
+	BuiltIns["bool"] = objects.Builtin{Fn: boolean, MinArgs: 1, MaxArgs: 1, Returns: objects.BOOL_TYPE}
+	BuiltIns["array"] = objects.Builtin{Fn: array, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE}
+	BuiltIns["hash"] = objects.Builtin{Fn: hash, MinArgs: 1, MaxArgs: 1, Returns: objects.HASH_TYPE}
+	BuiltIns["type"] = objects.Builtin{Fn: typeName, MinArgs: 1, MaxArgs: 1, Returns: objects.STRING_TYPE}
+	BuiltIns["repr"] = objects.Builtin{Fn: reprOf, MinArgs: 1, MaxArgs: 1, Returns: objects.STRING_TYPE}
+	BuiltIns["dir"] = objects.Builtin{Fn: dir, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE}
+
+	for name, types := range predicates {
+		BuiltIns[name] = objects.Builtin{Fn: predicate(types), MinArgs: 1, MaxArgs: 1, Returns: objects.BOOL_TYPE}
+	}
+}
+
+// the types each is_ predicate accepts
+var predicates = map[string][]objects.ObjectType{
+	"is_int":      {objects.INT},
+	"is_float":    {objects.FLOAT},
+	"is_number":   {objects.INT, objects.FLOAT},
+	"is_string":   {objects.STRING},
+	"is_bool":     {objects.BOOLEAN},
+	"is_null":     {objects.NULL},
+	"is_array":    {objects.ARRAY},
+	"is_hash":     {objects.HASH},
+	"is_function": {objects.FUNCTION, objects.BUILTIN, objects.STRUCT_TYPE},
+	"is_error":    {objects.ERROR_VALUE},
+}
+
+// float(x) converts an integer, a boolean or a string like "2.5"
+func float(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *objects.Float:
+		return arg
+	case *objects.Integer:
+		return &objects.Float{Value: float64(arg.Value)}
+	case *objects.Boolean:
+		if arg.Value {
+			return &objects.Float{Value: 1}
+		}
+		return &objects.Float{Value: 0}
+	case *objects.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "could not convert %q to float", arg.Value)
+		}
+		return &objects.Float{Value: value}
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `float` not supported, got %s", typeOf(args[0]))
+}
+
+// str(x) is x as println writes it, strings are left as they are
+func str(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case nil:
+		return &objects.String{Value: "null"}
+	case *objects.String:
+		return arg
+	}
+	return &objects.String{Value: args[0].Inspect()}
+}
+
+// bool(x) is false for false, null, zero, the empty string and empty arrays
+// and hashes, and true for everything else
+func boolean(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	var value bool
+	switch arg := args[0].(type) {
+	case nil, *objects.Null:
+		value = false
+	case *objects.Boolean:
+		value = arg.Value
+	case *objects.Integer:
+		value = arg.Value != 0
+	case *objects.Float:
+		value = arg.Value != 0
+	case *objects.String:
+		value = arg.Value != ""
+	case *objects.Array:
+		value = len(arg.Elements) > 0
+	case *objects.Hash:
+		value = len(arg.Pairs) > 0
+	default:
+		value = true
+	}
+	return &objects.Boolean{Value: value}
+}
+
+// array(x) copies an array, splits a string into its characters, turns a hash
+// into [key, value] pairs and reads what is left of an iterator
+func array(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch arg := args[0].(type) {
+	case *objects.Array:
+		return &objects.Array{Elements: copyElements(arg.Elements)}
+	case *objects.String:
+		elements := []objects.Object{}
+		for _, char := range arg.Value {
+			elements = append(elements, &objects.String{Value: string(char)})
+		}
+		return &objects.Array{Elements: elements}
+	case *objects.Hash:
+		elements := []objects.Object{}
+		for _, pair := range arg.Ordered() {
+			elements = append(elements, &objects.Array{Elements: []objects.Object{pair.Key, pair.Value}})
+		}
+		return &objects.Array{Elements: elements}
+	case *objects.Iterator:
+		return drain(arg)
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `array` not supported, got %s", typeOf(args[0]))
+}
+
+// calls next() until done() is true
+func drain(iter *objects.Iterator) objects.Object {
+	done, doneOk := iter.Methods["done"].(*objects.Builtin)
+	next, nextOk := iter.Methods["next"].(*objects.Builtin)
+	if !doneOk || !nextOk {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "%s can not be read into an array", iter.Inspect())
+	}
+	elements := []objects.Object{}
+	for {
+		finished := done.Fn()
+		if err, ok := finished.(*objects.Error); ok {
+			return err
+		}
+		if b, ok := finished.(*objects.Boolean); ok && b.Value {
+			return &objects.Array{Elements: elements}
+		}
+		element := next.Fn()
+		if err, ok := element.(*objects.Error); ok {
+			return err
+		}
+		elements = append(elements, element)
+	}
+}
+
+// hash(x) copies a hash, builds one from an array of [key, value] pairs and
+// turns a struct into a hash of its fields
+func hash(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	result := objects.NewHash()
+	switch arg := args[0].(type) {
+	case *objects.Hash:
+		for _, pair := range arg.Ordered() {
+			key, _ := hashKey(pair.Key)
+			result.Set(key, pair)
+		}
+	case *objects.Array:
+		for _, element := range arg.Elements {
+			pair, ok := element.(*objects.Array)
+			if !ok || len(pair.Elements) != 2 {
+				return objects.NewErrorKind(objects.VALUE_ERROR, "elements passed to `hash` must be [key, value] pairs, got %s", repr(element))
+			}
+			key, ok := hashKey(pair.Elements[0])
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "unusable as hash key: %s", typeOf(pair.Elements[0]))
+			}
+			result.Set(key, objects.HashPair{Key: pair.Elements[0], Value: pair.Elements[1]})
+		}
+	case *objects.Struct:
+		for _, name := range arg.Def.Fields {
+			setString(result, name, arg.Fields[name])
+		}
+	default:
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `hash` not supported, got %s", typeOf(args[0]))
+	}
+	return result
+}
+
+// type(x) is the name of the type of x, like INTEGER or STRUCT
+func typeName(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &objects.String{Value: string(typeOf(args[0]))}
+}
+
+// repr(x) describes x the way assertion failures do, with strings in quotes
+func reprOf(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	return &objects.String{Value: repr(args[0])}
+}
+
+// dir(x) lists the names that can follow x and a dot, fields in the order
+// they are declared and then methods in alphabetical order
+func dir(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	var names []string
+	switch arg := args[0].(type) {
+	case *objects.Struct:
+		names = append(names, arg.Def.Fields...)
+		names = append(names, structMethods(arg.Def)...)
+	case *objects.StructType:
+		names = structMethods(arg)
+	case *objects.Enum:
+		names = arg.Variants
+	case *objects.EnumValue:
+		names = arg.Enum.Fields[arg.Variant]
+	case *objects.ErrorValue:
+		names = objects.ErrorFields
+	case *objects.Module:
+		names = sortedNames(arg.Members)
+	case *objects.File:
+		names = sortedNames(arg.Methods)
+	case *objects.Iterator:
+		names = sortedNames(arg.Methods)
+	case *objects.Regex:
+		names = append([]string{"pattern"}, sortedNames(arg.Methods)...)
+	case *objects.Time:
+		names = objects.TimeFields
+	case *objects.Duration:
+		names = objects.DurationFields
+	}
+	elements := []objects.Object{}
+	for _, name := range names {
+		elements = append(elements, &objects.String{Value: name})
+	}
+	return &objects.Array{Elements: elements}
+}
+
+func sortedNames(members map[string]objects.Object) []string {
+	names := []string{}
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func structMethods(def *objects.StructType) []string {
+	names := []string{}
+	for name := range def.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func predicate(types []objects.ObjectType) objects.BuiltinFunction {
+	return func(args ...objects.Object) objects.Object {
+		if len(args) != 1 {
+			return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+		}
+		for _, t := range types {
+			if typeOf(args[0]) == t {
+				return &objects.Boolean{Value: true}
+			}
+		}
+		return &objects.Boolean{Value: false}
+	}
+}
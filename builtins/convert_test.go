@@ -0,0 +1,65 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+const point = `struct Point {
+    x, y
+    func norm(self) {
+        return self.x * self.x + self.y * self.y
+    }
+}
+`
+
+func TestConvert(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "int(2.9)", want: "2"},
+		{input: "int(-2.9)", want: "-2"},
+		{input: "float(2)", want: "2.0"},
+		{input: "float(\" 2.5 \")", want: "2.5"},
+		{input: "float(true)", want: "1.0"},
+		{input: "str(12)", want: `"12"`},
+		{input: "str([1, \"a\"])", want: `"[1, a]"`},
+		{input: "bool(0)", want: "false"},
+		{input: "bool(\"\")", want: "false"},
+		{input: "bool([])", want: "false"},
+		{input: "bool({})", want: "false"},
+		{input: "bool(\"no\")", want: "true"},
+		{input: "bool(0.5)", want: "true"},
+		{input: "array(\"héllo\")", want: `["h", "é", "l", "l", "o"]`},
+		{input: "array({\"a\": 1, \"b\": 2})", want: `[["a", 1], ["b", 2]]`},
+		{input: "hash([[\"a\", 1], [2, \"b\"]])", want: `{"a": 1, 2: "b"}`},
+		{input: "hash(array({\"x\": 1}))", want: `{"x": 1}`},
+		{input: point + "hash(Point(1, 2))", want: `{"x": 1, "y": 2}`},
+		{input: "float(\"two\")", kind: objects.VALUE_ERROR, want: "could not convert"},
+		{input: "hash([1])", kind: objects.VALUE_ERROR, want: "pairs"},
+		{input: "int([])", kind: objects.TYPE_ERROR, want: ""},
+	})
+}
+
+func TestInspect(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "type(1)", want: `"INTEGER"`},
+		{input: "type(\"a\")", want: `"STRING"`},
+		{input: point + "type(Point(1, 2))", want: `"STRUCT"`},
+		{input: "is_int(1)", want: "true"},
+		{input: "is_int(1.0)", want: "false"},
+		{input: "is_number(1.0)", want: "true"},
+		{input: "is_string(\"a\")", want: "true"},
+		{input: "is_function(len)", want: "true"},
+		{input: "is_function(x => x)", want: "true"},
+		{input: "is_hash({})", want: "true"},
+		{input: "is_null(println())", want: "true"},
+		{input: "repr([1, 2.0])", want: `"[1, 2.0]"`},
+		{input: "repr(\"a\")", want: `"\"a\""`},
+		{input: "repr([\"a\"]) == json_stringify([\"a\"])", want: "true"},
+		{input: point + "dir(Point(1, 2))", want: `["x", "y", "norm"]`},
+		{input: "dir(regex(\"a\"))", want: `["pattern", "captures", "find_all", "match", "replace", "split"]`},
+		{input: "dir(duration(\"1s\"))[0]", want: `"hours"`},
+		{input: "dir(1)", want: "[]"},
+		{input: "is_int()", kind: objects.ARGUMENT_ERROR, want: "wrong number of arguments"},
+	})
+}
@@ -0,0 +1,361 @@
+package builtins
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["csv_read"] = objects.Builtin{Native: csvRead, MinArgs: 1, MaxArgs: 2, Returns: objects.ARRAY_TYPE}
+	BuiltIns["csv_rows"] = objects.Builtin{Native: csvRows, MinArgs: 1, MaxArgs: 2, Returns: objects.ITER_TYPE}
+	BuiltIns["csv_write"] = objects.Builtin{Fn: csvWrite, MinArgs: 1, MaxArgs: 2, Returns: objects.STRING_TYPE}
+}
+
+// options csv_read and csv_rows take
+type csvOptions struct {
+	delimiter rune
+	comment   rune
+	// the first row names the columns, rows are returned as hashes
+	header     bool
+	lazyQuotes bool
+	trimSpace  bool
+	// the source is csv text rather than a path
+	text bool
+}
+
+// csv_read(path_or_text, options?) reads every row, as arrays of strings or
+// as hashes keyed by the header row when options has "header": true, the
+// source is csv text when it has a line break or options has "text": true
+func csvRead(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	reader, closer, options, err := openCSV(interp, "csv_read", args)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	var header []string
+	rows := []objects.Object{}
+	for {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "invalid csv: %s", readErr)
+		}
+		if options.header && header == nil {
+			header = record
+			continue
+		}
+		rows = append(rows, csvRow(record, header))
+	}
+	return &objects.Array{Elements: rows}
+}
+
+// csv_rows(path_or_text, options?) takes the same options as csv_read but
+// reads one row each time next() is called, next() returns null and done()
+// true once every row has been read
+func csvRows(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	reader, closer, options, err := openCSV(interp, "csv_rows", args)
+	if err != nil {
+		return err
+	}
+
+	var header []string
+	var pending []string
+	finished := false
+	// reads ahead so done() knows whether there is another row
+	fetch := func() objects.Object {
+		for pending == nil && !finished {
+			record, readErr := reader.Read()
+			if readErr == io.EOF {
+				finished = true
+				closer.Close()
+				break
+			}
+			if readErr != nil {
+				return objects.NewErrorKind(objects.VALUE_ERROR, "invalid csv: %s", readErr)
+			}
+			if options.header && header == nil {
+				header = record
+				continue
+			}
+			pending = record
+		}
+		return nil
+	}
+
+	name := "csv rows"
+	if str, ok := args[0].(*objects.String); ok && !options.text {
+		name += " of " + str.Value
+	}
+	return &objects.Iterator{Name: name, Methods: map[string]objects.Object{
+		"next": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+			if len(args) != 0 {
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+			}
+			if err := fetch(); err != nil {
+				return err
+			}
+			if pending == nil {
+				return &objects.Null{}
+			}
+			row := csvRow(pending, header)
+			pending = nil
+			return row
+		}},
+		"done": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+			if len(args) != 0 {
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+			}
+			if err := fetch(); err != nil {
+				return err
+			}
+			return &objects.Boolean{Value: pending == nil}
+		}},
+		"close": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+			if len(args) != 0 {
+				return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+			}
+			if !finished {
+				finished = true
+				pending = nil
+				closer.Close()
+			}
+			return &objects.Null{}
+		}},
+	}}
+}
+
+// a row as an array, or as a hash when there is a header, cells the row is
+// missing are empty strings
+func csvRow(record []string, header []string) objects.Object {
+	if header == nil {
+		cells := make([]objects.Object, len(record))
+		for i, cell := range record {
+			cells[i] = &objects.String{Value: cell}
+		}
+		return &objects.Array{Elements: cells}
+	}
+	hash := objects.NewHash()
+	for i, column := range header {
+		cell := ""
+		if i < len(record) {
+			cell = record[i]
+		}
+		setString(hash, column, &objects.String{Value: cell})
+	}
+	return hash
+}
+
+// checks the arguments of csv_read and csv_rows and opens the source, a
+// path can only be read with the fs capability
+func openCSV(interp objects.Interpreter, name string, args []objects.Object) (*csv.Reader, io.Closer, *csvOptions, *objects.Error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, nil, nil, objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	source, ok := args[0].(*objects.String)
+	if !ok {
+		return nil, nil, nil, objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `%s` must be STRING, got %s", name, typeOf(args[0]))
+	}
+	options := &csvOptions{delimiter: ','}
+	if len(args) == 2 {
+		if err := readCSVOptions(name, args[1], options); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var input io.ReadCloser
+	if options.text || strings.ContainsAny(source.Value, "\r\n") {
+		options.text = true
+		input = io.NopCloser(strings.NewReader(source.Value))
+	} else {
+		if !interp.Allows(objects.FS_CAPABILITY) {
+			return nil, nil, nil, objects.NewErrorKind(objects.PERMISSION_ERROR, "%s of a file needs the %s capability, run with -allow %s",
+				name, objects.FS_CAPABILITY, objects.FS_CAPABILITY)
+		}
+		file, err := os.Open(source.Value)
+		if err != nil {
+			return nil, nil, nil, ioError(err)
+		}
+		input = file
+	}
+
+	reader := csv.NewReader(input)
+	reader.Comma = options.delimiter
+	reader.Comment = options.comment
+	reader.LazyQuotes = options.lazyQuotes
+	reader.TrimLeadingSpace = options.trimSpace
+	// rows may have different numbers of cells
+	reader.FieldsPerRecord = -1
+	return reader, input, options, nil
+}
+
+func readCSVOptions(name string, obj objects.Object, options *csvOptions) *objects.Error {
+	hash, ok := obj.(*objects.Hash)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `%s` must be HASH, got %s", name, typeOf(obj))
+	}
+	for _, pair := range hash.Ordered() {
+		key := pair.Key.Inspect()
+		var err *objects.Error
+		switch key {
+		case "delimiter":
+			options.delimiter, err = optionRune(name, key, pair.Value)
+		case "comment":
+			options.comment, err = optionRune(name, key, pair.Value)
+		case "header":
+			options.header, err = optionBool(name, key, pair.Value)
+		case "lazy_quotes":
+			options.lazyQuotes, err = optionBool(name, key, pair.Value)
+		case "trim_space":
+			options.trimSpace, err = optionBool(name, key, pair.Value)
+		case "text":
+			options.text, err = optionBool(name, key, pair.Value)
+		default:
+			err = objects.NewErrorKind(objects.VALUE_ERROR, "unknown option %s for `%s`", key, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csv_write(rows, options?) returns the rows as csv text, rows are arrays of
+// cells or hashes whose keys name the columns, options takes a "delimiter"
+// and a "header" array naming the columns, which is written first and picks
+// the cells of hashes
+func csvWrite(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	rows, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `csv_write` must be ARRAY, got %s", typeOf(args[0]))
+	}
+
+	delimiter := ','
+	var header []string
+	if len(args) == 2 {
+		hash, ok := args[1].(*objects.Hash)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `csv_write` must be HASH, got %s", typeOf(args[1]))
+		}
+		for _, pair := range hash.Ordered() {
+			key := pair.Key.Inspect()
+			switch key {
+			case "delimiter":
+				d, err := optionRune("csv_write", key, pair.Value)
+				if err != nil {
+					return err
+				}
+				delimiter = d
+			case "header":
+				columns, ok := pair.Value.(*objects.Array)
+				if !ok {
+					return objects.NewErrorKind(objects.TYPE_ERROR, "option header of `csv_write` must be ARRAY, got %s", typeOf(pair.Value))
+				}
+				header = []string{}
+				for _, column := range columns.Elements {
+					header = append(header, csvCell(column))
+				}
+			default:
+				return objects.NewErrorKind(objects.VALUE_ERROR, "unknown option %s for `csv_write`", key)
+			}
+		}
+	}
+
+	// hashes without a header are written under the keys of the first one
+	if header == nil && len(rows.Elements) > 0 {
+		if first, ok := rows.Elements[0].(*objects.Hash); ok {
+			header = []string{}
+			for _, pair := range first.Ordered() {
+				header = append(header, pair.Key.Inspect())
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	writer := csv.NewWriter(&out)
+	writer.Comma = delimiter
+	if header != nil {
+		if err := writer.Write(header); err != nil {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+		}
+	}
+	for _, row := range rows.Elements {
+		var record []string
+		switch row := row.(type) {
+		case *objects.Array:
+			for _, cell := range row.Elements {
+				if err := checkCell(cell); err != nil {
+					return err
+				}
+				record = append(record, csvCell(cell))
+			}
+		case *objects.Hash:
+			for _, column := range header {
+				key := &objects.String{Value: column}
+				pair, ok := row.Pairs[key.HashKey()]
+				if !ok {
+					record = append(record, "")
+					continue
+				}
+				if err := checkCell(pair.Value); err != nil {
+					return err
+				}
+				record = append(record, csvCell(pair.Value))
+			}
+		default:
+			return objects.NewErrorKind(objects.TYPE_ERROR, "rows of `csv_write` must be ARRAY or HASH, got %s", typeOf(row))
+		}
+		if err := writer.Write(record); err != nil {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+	}
+	return &objects.String{Value: out.String()}
+}
+
+func checkCell(obj objects.Object) *objects.Error {
+	switch obj.(type) {
+	case nil, *objects.Null, *objects.String, *objects.Integer, *objects.Float, *objects.Boolean:
+		return nil
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "cannot write %s to csv", typeOf(obj))
+}
+
+// null is written as an empty cell
+func csvCell(obj objects.Object) string {
+	if obj == nil || obj.Type() == objects.NULL {
+		return ""
+	}
+	return obj.Inspect()
+}
+
+func optionRune(name, key string, obj objects.Object) (rune, *objects.Error) {
+	str, ok := obj.(*objects.String)
+	if !ok || utf8.RuneCountInString(str.Value) != 1 {
+		return 0, objects.NewErrorKind(objects.TYPE_ERROR, "option %s of `%s` must be a single character, got %s", key, name, obj.Inspect())
+	}
+	r, _ := utf8.DecodeRuneInString(str.Value)
+	return r, nil
+}
+
+func optionBool(name, key string, obj objects.Object) (bool, *objects.Error) {
+	b, ok := obj.(*objects.Boolean)
+	if !ok {
+		return false, objects.NewErrorKind(objects.TYPE_ERROR, "option %s of `%s` must be BOOLEAN, got %s", key, name, typeOf(obj))
+	}
+	return b.Value, nil
+}
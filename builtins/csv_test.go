@@ -0,0 +1,59 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestCSV(t *testing.T) {
+	tests := []builtinTest{
+		{input: "csv_read(dir + \"/people.csv\", {\"delimiter\": \";\", \"header\": true})",
+			want: `[{"name": "ann", "age": "30"}, {"name": "smith; bob", "age": "20"}]`},
+		{input: "csv_read(dir + \"/people.csv\", {\"delimiter\": \";\"})[0]", want: `["name", "age"]`},
+		{input: "var rows = csv_rows(dir + \"/people.csv\", {\"delimiter\": \";\", \"header\": true})\nvar names = []\nwhile (!rows.done()) {\n    append(names, rows.next()[\"name\"])\n}\nrows.close()\nnames",
+			want: `["ann", "smith; bob"]`},
+		{input: "var people = csv_read(dir + \"/people.csv\", {\"delimiter\": \";\", \"header\": true})\ncsv_read(csv_write(people), {\"header\": true})",
+			want: `[{"name": "ann", "age": "30"}, {"name": "smith; bob", "age": "20"}]`},
+		{input: "csv_write([[1, 2.5, true]])", want: `"1,2.5,true\n"`},
+		{input: "csv_write([{\"name\": \"ann\", \"age\": 30}], {\"header\": [\"age\"], \"delimiter\": \"|\"})", want: `"age\n30\n"`},
+		{input: "csv_read(\"a,b\", {\"text\": true})", want: `[["a", "b"]]`},
+		{input: "csv_read(\"a,b\n1,2\")", want: `[["a", "b"], ["1", "2"]]`},
+		{input: "csv_read(dir + \"/missing.csv\")", kind: objects.IO_ERROR, want: "no such file"},
+		{input: "csv_read(dir + \"/people.csv\", {\"sep\": \",\"})", kind: objects.VALUE_ERROR, want: "unknown option sep"},
+		{input: "csv_write([[[1]]])", kind: objects.TYPE_ERROR, want: "cannot write ARRAY to csv"},
+	}
+	runFileTests(t, tests, map[string]string{"people.csv": "name;age\nann;30\n\"smith; bob\";20\n"}, objects.FS_CAPABILITY)
+}
+
+// a quote or newline delimiter cannot be written in alpha source
+func TestCSVWriteErrors(t *testing.T) {
+	rows := &objects.Array{Elements: []objects.Object{
+		&objects.Array{Elements: []objects.Object{&objects.Integer{Value: 1}, &objects.Integer{Value: 2}}},
+	}}
+	tests := []struct {
+		name    string
+		options *objects.Hash
+	}{
+		{"quote delimiter", optionHash("delimiter", &objects.String{Value: "\""})},
+		{"newline delimiter", optionHash("delimiter", &objects.String{Value: "\n"})},
+		{"header only", optionHash("delimiter", &objects.String{Value: "\r"}, "header", &objects.Array{Elements: []objects.Object{&objects.String{Value: "a"}}})},
+	}
+	write := builtins.BuiltIns["csv_write"].Fn
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkResult(t, write(rows, tt.options), "invalid field or comment delimiter", objects.VALUE_ERROR)
+		})
+	}
+}
+
+// a hash of alternating string keys and values
+func optionHash(pairs ...interface{}) *objects.Hash {
+	hash := objects.NewHash()
+	for i := 0; i < len(pairs); i += 2 {
+		key := &objects.String{Value: pairs[i].(string)}
+		hash.Set(key.HashKey(), objects.HashPair{Key: key, Value: pairs[i+1].(objects.Object)})
+	}
+	return hash
+}
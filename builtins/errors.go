@@ -0,0 +1,27 @@
+package builtins
+
+import "github.com/EVFUBS/AlphaLang/objects"
+
+func init() {
+	BuiltIns["error"] = objects.Builtin{Fn: newError, MinArgs: 1, MaxArgs: 2, Returns: objects.ERROR_TYPE}
+}
+
+// error(message, kind?) makes an error value to throw
+func newError(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	kind := objects.GENERIC_ERROR
+	if len(args) == 2 {
+		str, ok := args[1].(*objects.String)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `error` must be STRING, got %s", typeOf(args[1]))
+		}
+		kind = str.Value
+	}
+	message, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `error` must be STRING, got %s", typeOf(args[0]))
+	}
+	return &objects.ErrorValue{Err: objects.NewErrorKind(kind, "%s", message.Value)}
+}
@@ -0,0 +1,370 @@
+package builtins
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	Modules["fs"] = &objects.Module{
+		Name:       "fs",
+		Capability: objects.FS_CAPABILITY,
+		Members: map[string]objects.Object{
+			"read_file":   &objects.Builtin{Fn: readFile, MinArgs: 1, MaxArgs: 1, Returns: objects.STRING_TYPE},
+			"write_file":  &objects.Builtin{Fn: writeFile, MinArgs: 2, MaxArgs: 2},
+			"append_file": &objects.Builtin{Fn: appendFile, MinArgs: 2, MaxArgs: 2},
+			"read_lines":  &objects.Builtin{Fn: readLines, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE},
+			"exists":      &objects.Builtin{Fn: exists, MinArgs: 1, MaxArgs: 1, Returns: objects.BOOL_TYPE},
+			"list_dir":    &objects.Builtin{Fn: listDir, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE},
+			"mkdir":       &objects.Builtin{Fn: mkdir, MinArgs: 1, MaxArgs: 1},
+			"remove":      &objects.Builtin{Fn: remove, MinArgs: 1, MaxArgs: 2},
+			"rename":      &objects.Builtin{Fn: rename, MinArgs: 2, MaxArgs: 2},
+			"stat":        &objects.Builtin{Fn: stat, MinArgs: 1, MaxArgs: 1, Returns: objects.HASH_TYPE},
+			"glob":        &objects.Builtin{Fn: glob, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE},
+			"open":        &objects.Builtin{Fn: open, MinArgs: 1, MaxArgs: 2, Returns: objects.FILE_TYPE},
+		},
+	}
+}
+
+// fs.read_file(path)
+func readFile(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("read_file", 1, args)
+	if err != nil {
+		return err
+	}
+	content, readErr := os.ReadFile(paths[0])
+	if readErr != nil {
+		return ioError(readErr)
+	}
+	return &objects.String{Value: string(content)}
+}
+
+// fs.write_file(path, content) replaces the file, creating it when missing
+func writeFile(args ...objects.Object) objects.Object {
+	strs, err := stringArgs("write_file", 2, args)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(strs[0], []byte(strs[1]), 0644); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// fs.append_file(path, content) adds to the end of the file, creating it
+// when missing
+func appendFile(args ...objects.Object) objects.Object {
+	strs, err := stringArgs("append_file", 2, args)
+	if err != nil {
+		return err
+	}
+	file, openErr := os.OpenFile(strs[0], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return ioError(openErr)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(strs[1]); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// fs.read_lines(path) returns the lines without their line endings
+func readLines(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("read_lines", 1, args)
+	if err != nil {
+		return err
+	}
+	content, readErr := os.ReadFile(paths[0])
+	if readErr != nil {
+		return ioError(readErr)
+	}
+	lines := []objects.Object{}
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return &objects.Array{Elements: lines}
+	}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, &objects.String{Value: strings.TrimSuffix(line, "\r")})
+	}
+	return &objects.Array{Elements: lines}
+}
+
+// fs.exists(path)
+func exists(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("exists", 1, args)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(paths[0])
+	if os.IsNotExist(statErr) {
+		return &objects.Boolean{Value: false}
+	}
+	if statErr != nil {
+		return ioError(statErr)
+	}
+	return &objects.Boolean{Value: true}
+}
+
+// fs.list_dir(path) returns the names of the entries in order
+func listDir(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("list_dir", 1, args)
+	if err != nil {
+		return err
+	}
+	entries, readErr := os.ReadDir(paths[0])
+	if readErr != nil {
+		return ioError(readErr)
+	}
+	names := []objects.Object{}
+	for _, entry := range entries {
+		names = append(names, &objects.String{Value: entry.Name()})
+	}
+	return &objects.Array{Elements: names}
+}
+
+// fs.mkdir(path) creates the directory and any parents it is missing
+func mkdir(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("mkdir", 1, args)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(paths[0], 0755); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// fs.remove(path, recursive?) removes a file or an empty directory, or
+// everything under the path when recursive is true
+func remove(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	path, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `remove` must be STRING, got %s", typeOf(args[0]))
+	}
+	recursive := false
+	if len(args) == 2 {
+		b, ok := args[1].(*objects.Boolean)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `remove` must be BOOLEAN, got %s", typeOf(args[1]))
+		}
+		recursive = b.Value
+	}
+	var removeErr error
+	if recursive {
+		removeErr = os.RemoveAll(path.Value)
+	} else {
+		removeErr = os.Remove(path.Value)
+	}
+	if removeErr != nil {
+		return ioError(removeErr)
+	}
+	return &objects.Null{}
+}
+
+// fs.rename(from, to)
+func rename(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("rename", 2, args)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(paths[0], paths[1]); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// fs.stat(path) returns a hash with the name, size, is_dir, mode and
+// modified time in unix seconds
+func stat(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("stat", 1, args)
+	if err != nil {
+		return err
+	}
+	info, statErr := os.Stat(paths[0])
+	if statErr != nil {
+		return ioError(statErr)
+	}
+	hash := objects.NewHash()
+	setString(hash, "name", &objects.String{Value: info.Name()})
+	setString(hash, "size", &objects.Integer{Value: info.Size()})
+	setString(hash, "is_dir", &objects.Boolean{Value: info.IsDir()})
+	setString(hash, "mode", &objects.String{Value: info.Mode().String()})
+	setString(hash, "modified", &objects.Integer{Value: info.ModTime().Unix()})
+	return hash
+}
+
+// fs.glob(pattern) returns the paths matching the pattern in order
+func glob(args ...objects.Object) objects.Object {
+	patterns, err := stringArgs("glob", 1, args)
+	if err != nil {
+		return err
+	}
+	matches, globErr := filepath.Glob(patterns[0])
+	if globErr != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "bad pattern %q", patterns[0])
+	}
+	sort.Strings(matches)
+	paths := []objects.Object{}
+	for _, match := range matches {
+		paths = append(paths, &objects.String{Value: match})
+	}
+	return &objects.Array{Elements: paths}
+}
+
+// flags of the modes fs.open takes
+var openModes = map[string]int{
+	"r": os.O_RDONLY,
+	"w": os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a": os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+}
+
+// fs.open(path, mode?) opens the file for reading, or for writing with "w"
+// and appending with "a"
+func open(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	strs, err := stringArgs("open", len(args), args)
+	if err != nil {
+		return err
+	}
+	mode := "r"
+	if len(strs) == 2 {
+		mode = strs[1]
+	}
+	flags, ok := openModes[mode]
+	if !ok {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "unknown mode %q, want \"r\", \"w\" or \"a\"", mode)
+	}
+	handle, openErr := os.OpenFile(strs[0], flags, 0644)
+	if openErr != nil {
+		return ioError(openErr)
+	}
+
+	file := &objects.File{Path: strs[0], Handle: handle}
+	if mode == "r" {
+		file.Reader = bufio.NewReader(handle)
+	}
+	file.Methods = map[string]objects.Object{
+		"read_line": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return readLine(file, args) }},
+		"eof":       &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return eof(file, args) }},
+		"write":     &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return write(file, args) }},
+		"close":     &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return closeFile(file, args) }},
+	}
+	return file
+}
+
+// file.read_line() returns the next line without its line ending, null at
+// the end of the file
+func readLine(file *objects.File, args []objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	if err := reader(file); err != nil {
+		return err
+	}
+	line, err := file.Reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return &objects.Null{}
+	}
+	if err != nil && err != io.EOF {
+		return ioError(err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return &objects.String{Value: strings.TrimSuffix(line, "\r")}
+}
+
+// file.eof() reports whether everything has been read
+func eof(file *objects.File, args []objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	if err := reader(file); err != nil {
+		return err
+	}
+	_, err := file.Reader.Peek(1)
+	if err != nil && err != io.EOF {
+		return ioError(err)
+	}
+	return &objects.Boolean{Value: err == io.EOF}
+}
+
+// file.write(str)
+func write(file *objects.File, args []objects.Object) objects.Object {
+	strs, err := stringArgs("write", 1, args)
+	if err != nil {
+		return err
+	}
+	if err := usable(file); err != nil {
+		return err
+	}
+	if file.Reader != nil {
+		return objects.NewErrorKind(objects.IO_ERROR, "file %s is not open for writing", file.Path)
+	}
+	if _, err := file.Handle.WriteString(strs[0]); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// file.close(), closing a file twice does nothing
+func closeFile(file *objects.File, args []objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	if file.Closed {
+		return &objects.Null{}
+	}
+	file.Closed = true
+	if err := file.Handle.Close(); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+func reader(file *objects.File) *objects.Error {
+	if err := usable(file); err != nil {
+		return err
+	}
+	if file.Reader == nil {
+		return objects.NewErrorKind(objects.IO_ERROR, "file %s is not open for reading", file.Path)
+	}
+	return nil
+}
+
+func usable(file *objects.File) *objects.Error {
+	if file.Closed {
+		return objects.NewErrorKind(objects.IO_ERROR, "file %s is closed", file.Path)
+	}
+	return nil
+}
+
+// checks there are n arguments and all of them are strings
+func stringArgs(name string, n int, args []objects.Object) ([]string, *objects.Error) {
+	if len(args) != n {
+		return nil, objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=%d", len(args), n)
+	}
+	strs := make([]string, n)
+	for i, arg := range args {
+		str, ok := arg.(*objects.String)
+		if !ok {
+			return nil, objects.NewErrorKind(objects.TYPE_ERROR, "arguments to `%s` must be STRING, got %s", name, typeOf(arg))
+		}
+		strs[i] = str.Value
+	}
+	return strs, nil
+}
+
+func ioError(err error) *objects.Error {
+	return objects.NewErrorKind(objects.IO_ERROR, "%s", err)
+}
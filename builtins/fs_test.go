@@ -0,0 +1,33 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestFS(t *testing.T) {
+	tests := []builtinTest{
+		{input: "fs.exists(dir + \"/notes.txt\")", want: "true"},
+		{input: "fs.exists(dir + \"/missing.txt\")", want: "false"},
+		{input: "fs.read_file(dir + \"/notes.txt\")", want: `"one\ntwo\nthree\n"`},
+		{input: "fs.read_lines(dir + \"/notes.txt\")", want: `["one", "two", "three"]`},
+		{input: "fs.write_file(dir + \"/a.txt\", \"a,\")\nfs.append_file(dir + \"/a.txt\", \"b\")\nfs.read_file(dir + \"/a.txt\")", want: `"a,b"`},
+		{input: "fs.stat(dir + \"/notes.txt\")[\"size\"]", want: "14"},
+		{input: "fs.stat(dir)[\"is_dir\"]", want: "true"},
+		{input: "var f = fs.open(dir + \"/notes.txt\")\nvar lines = []\nwhile (!f.eof()) {\n    append(lines, f.read_line())\n}\nf.close()\nlines",
+			want: `["one", "two", "three"]`},
+		{input: "var f = fs.open(dir + \"/out.txt\", \"w\")\nf.write(\"a,\")\nf.write(\"b\")\nf.close()\nfs.read_file(dir + \"/out.txt\")", want: `"a,b"`},
+		{input: "fs.mkdir(dir + \"/sub/deeper\")\nfs.list_dir(dir)", want: `["notes.txt", "sub"]`},
+		{input: "fs.mkdir(dir + \"/sub\")\nfs.rename(dir + \"/notes.txt\", dir + \"/sub/notes.txt\")\nfs.glob(dir + \"/*/*.txt\")", want: `["DIR/sub/notes.txt"]`},
+		{input: "fs.remove(dir + \"/notes.txt\")\nfs.list_dir(dir)", want: "[]"},
+		{input: "fs.mkdir(dir + \"/sub/deeper\")\nfs.remove(dir + \"/sub\", true)\nfs.list_dir(dir)", want: `["notes.txt"]`},
+		{input: "var f = fs.open(dir + \"/notes.txt\")\nf.close()\nf.read_line()", kind: objects.IO_ERROR, want: "closed"},
+		{input: "fs.read_file(dir + \"/missing.txt\")", kind: objects.IO_ERROR, want: "no such file"},
+		{input: "fs.mkdir(dir + \"/sub\")\nfs.remove(dir + \"/sub/..\")", kind: objects.IO_ERROR, want: ""},
+		{input: "fs.open(dir + \"/notes.txt\", \"x\")", kind: objects.VALUE_ERROR, want: "mode"},
+		{input: "fs.read_file(1)", kind: objects.TYPE_ERROR, want: "must be STRING"},
+		{input: "fs.write_file(dir)", kind: objects.ARGUMENT_ERROR, want: "wrong number of arguments"},
+	}
+	runFileTests(t, tests, map[string]string{"notes.txt": "one\ntwo\nthree\n"}, objects.FS_CAPABILITY)
+}
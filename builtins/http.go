@@ -0,0 +1,405 @@
+package builtins
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	Modules["http"] = &objects.Module{
+		Name:       "http",
+		Capability: objects.NET_CAPABILITY,
+		Members: map[string]objects.Object{
+			"get":     &objects.Builtin{Fn: httpGet, MinArgs: 1, MaxArgs: 2, Returns: objects.HASH_TYPE},
+			"post":    &objects.Builtin{Fn: httpPost, MinArgs: 2, MaxArgs: 3, Returns: objects.HASH_TYPE},
+			"request": &objects.Builtin{Fn: httpRequest, MinArgs: 2, MaxArgs: 3, Returns: objects.HASH_TYPE},
+			"serve":   &objects.Builtin{Native: serve, MinArgs: 2, MaxArgs: 3},
+		},
+	}
+}
+
+// how long a request may take when the options do not give a timeout, also
+// how long serve waits for a request to arrive
+const HTTP_TIMEOUT = 30 * time.Second
+
+// how long serve waits for the headers of a request
+const HTTP_HEADER_TIMEOUT = 10 * time.Second
+
+// the largest request body serve reads
+const HTTP_MAX_BODY = 10 << 20
+
+// options the client functions take
+type httpOptions struct {
+	headers     map[string]string
+	body        string
+	contentType string
+	timeout     time.Duration
+}
+
+// http.get(url, options?) returns a hash of the response "status",
+// "headers" and "body", options takes "headers", a "timeout" duration and a
+// "body" string or a "json" value to send
+func httpGet(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	return sendRequest("get", http.MethodGet, args[0], args[1:])
+}
+
+// http.post(url, body, options?) sends a string body as it is and any other
+// value as json
+func httpPost(args ...objects.Object) objects.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	key := "json"
+	if _, ok := args[1].(*objects.String); ok {
+		key = "body"
+	}
+	options := objects.NewHash()
+	setString(options, key, args[1])
+	if len(args) == 3 {
+		extra, ok := args[2].(*objects.Hash)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "third argument to `post` must be HASH, got %s", typeOf(args[2]))
+		}
+		for _, pair := range extra.Ordered() {
+			key, _ := hashKey(pair.Key)
+			options.Set(key, pair)
+		}
+	}
+	return sendRequest("post", http.MethodPost, args[0], []objects.Object{options})
+}
+
+// http.request(method, url, options?) sends a request with any method, like
+// PUT or DELETE
+func httpRequest(args ...objects.Object) objects.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	method, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `request` must be STRING, got %s", typeOf(args[0]))
+	}
+	return sendRequest("request", strings.ToUpper(method.Value), args[1], args[2:])
+}
+
+// responses with an error status are returned like any other, only failing
+// to get a response is an error
+func sendRequest(name, method string, urlObj objects.Object, rest []objects.Object) objects.Object {
+	url, ok := urlObj.(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "url passed to `%s` must be STRING, got %s", name, typeOf(urlObj))
+	}
+	options := &httpOptions{timeout: HTTP_TIMEOUT}
+	if len(rest) == 1 {
+		if err := readHTTPOptions(name, rest[0], options); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, url.Value, strings.NewReader(options.body))
+	if err != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+	}
+	if options.contentType != "" {
+		req.Header.Set("Content-Type", options.contentType)
+	}
+	for key, value := range options.headers {
+		req.Header.Set(key, value)
+	}
+	client := &http.Client{Timeout: options.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ioError(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ioError(err)
+	}
+
+	result := objects.NewHash()
+	setString(result, "status", &objects.Integer{Value: int64(resp.StatusCode)})
+	setString(result, "headers", headerHash(resp.Header))
+	setString(result, "body", &objects.String{Value: string(body)})
+	return result
+}
+
+func readHTTPOptions(name string, obj objects.Object, options *httpOptions) *objects.Error {
+	hash, ok := obj.(*objects.Hash)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "options passed to `%s` must be HASH, got %s", name, typeOf(obj))
+	}
+	for _, pair := range hash.Ordered() {
+		key := pair.Key.Inspect()
+		switch key {
+		case "headers":
+			headers, err := stringHash(name, key, pair.Value)
+			if err != nil {
+				return err
+			}
+			options.headers = headers
+		case "body":
+			body, ok := pair.Value.(*objects.String)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "option body of `%s` must be STRING, got %s", name, typeOf(pair.Value))
+			}
+			options.body = body.Value
+		case "json":
+			encoded := jsonStringify(pair.Value)
+			if err, ok := encoded.(*objects.Error); ok {
+				return err
+			}
+			options.body = encoded.(*objects.String).Value
+			options.contentType = "application/json"
+		case "timeout":
+			d, ok := pair.Value.(*objects.Duration)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "option timeout of `%s` must be DURATION, got %s", name, typeOf(pair.Value))
+			}
+			options.timeout = d.Value
+		default:
+			return objects.NewErrorKind(objects.VALUE_ERROR, "unknown option %s for `%s`", key, name)
+		}
+	}
+	return nil
+}
+
+// http.serve(addr, handler, options?) listens on an address like
+// "127.0.0.1:8080" and calls the handler with a hash of each request's
+// "method", "path", "query", "headers" and "body", the handler returns a hash
+// with a "status", "headers" and a "body" string or a "json" value, or just a
+// string for the body, requests are handled one at a time, options takes an
+// "on_listen" function called with the address once it is listening, which
+// tells the port when addr ends in ":0", serve returns null once a handler
+// returns a hash with "stop": true, or an error when the program is stopped
+// by exit() in a handler or the server fails
+func serve(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3", len(args))
+	}
+	addr, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `serve` must be STRING, got %s", typeOf(args[0]))
+	}
+	if err := checkFunction("serve", args[1]); err != nil {
+		return err
+	}
+	var onListen objects.Object
+	if len(args) == 3 {
+		options, ok := args[2].(*objects.Hash)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "options passed to `serve` must be HASH, got %s", typeOf(args[2]))
+		}
+		for _, pair := range options.Ordered() {
+			if key := pair.Key.Inspect(); key != "on_listen" {
+				return objects.NewErrorKind(objects.VALUE_ERROR, "unknown option %s for `serve`", key)
+			}
+			if err := checkFunction("serve", pair.Value); err != nil {
+				return err
+			}
+			onListen = pair.Value
+		}
+	}
+	listener, err := net.Listen("tcp", addr.Value)
+	if err != nil {
+		return ioError(err)
+	}
+	if onListen != nil {
+		if _, err := call(interp, onListen, &objects.String{Value: listener.Addr().String()}); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	// the interpreter runs one call at a time
+	var mutex sync.Mutex
+	done := false
+	stopped := make(chan objects.Object, 1)
+	stop := func(result objects.Object) {
+		done = true
+		select {
+		case stopped <- result:
+		default:
+		}
+	}
+	server := &http.Server{
+		ReadHeaderTimeout: HTTP_HEADER_TIMEOUT,
+		ReadTimeout:       HTTP_TIMEOUT,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// read before waiting for the interpreter so a slow client does
+			// not hold up the others
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, HTTP_MAX_BODY))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			request := requestHash(r, body)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if done {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			result, callErr := call(interp, args[1], request)
+			if callErr != nil {
+				if err, ok := callErr.(*objects.Error); ok && err.Fatal {
+					stop(err)
+				} else {
+					fmt.Fprintf(interp.ErrorOutput(), "%s %s: %s\n", r.Method, r.URL.Path, callErr.Inspect())
+				}
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			last, writeErr := writeResponse(w, result)
+			if writeErr != nil {
+				fmt.Fprintf(interp.ErrorOutput(), "%s %s: %s: %s\n", r.Method, r.URL.Path, writeErr.Kind, writeErr.Message)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if last {
+				stop(&objects.Null{})
+			}
+		}),
+	}
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			mutex.Lock()
+			stop(ioError(err))
+			mutex.Unlock()
+		}
+	}()
+
+	result := <-stopped
+	// lets the last response finish before closing
+	ctx, cancel := context.WithTimeout(context.Background(), HTTP_TIMEOUT)
+	defer cancel()
+	if server.Shutdown(ctx) != nil {
+		server.Close()
+	}
+	return result
+}
+
+func requestHash(r *http.Request, body []byte) *objects.Hash {
+	query := objects.NewHash()
+	values := r.URL.Query()
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		setString(query, name, &objects.String{Value: values.Get(name)})
+	}
+
+	request := objects.NewHash()
+	setString(request, "method", &objects.String{Value: r.Method})
+	setString(request, "path", &objects.String{Value: r.URL.Path})
+	setString(request, "query", query)
+	setString(request, "headers", headerHash(r.Header))
+	setString(request, "body", &objects.String{Value: string(body)})
+	return request
+}
+
+// writes what the handler returned, reporting whether it asked to stop
+func writeResponse(w http.ResponseWriter, result objects.Object) (bool, *objects.Error) {
+	switch result := result.(type) {
+	case nil, *objects.Null:
+		w.WriteHeader(http.StatusNoContent)
+		return false, nil
+	case *objects.String:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, result.Value)
+		return false, nil
+	case *objects.Hash:
+		status := http.StatusOK
+		var body string
+		var stop bool
+		for _, pair := range result.Ordered() {
+			key := pair.Key.Inspect()
+			switch key {
+			case "status":
+				n, ok := pair.Value.(*objects.Integer)
+				if !ok || n.Value < 100 || n.Value > 999 {
+					return false, objects.NewErrorKind(objects.VALUE_ERROR, "status of a response must be an INTEGER from 100 to 999, got %s", repr(pair.Value))
+				}
+				status = int(n.Value)
+			case "headers":
+				headers, err := stringHash("serve", key, pair.Value)
+				if err != nil {
+					return false, err
+				}
+				for name, value := range headers {
+					w.Header().Set(name, value)
+				}
+			case "body":
+				str, ok := pair.Value.(*objects.String)
+				if !ok {
+					return false, objects.NewErrorKind(objects.TYPE_ERROR, "body of a response must be STRING, got %s", typeOf(pair.Value))
+				}
+				body = str.Value
+			case "json":
+				encoded := jsonStringify(pair.Value)
+				if err, ok := encoded.(*objects.Error); ok {
+					return false, err
+				}
+				body = encoded.(*objects.String).Value
+				if w.Header().Get("Content-Type") == "" {
+					w.Header().Set("Content-Type", "application/json")
+				}
+			case "stop":
+				var err *objects.Error
+				if stop, err = optionBool("serve", key, pair.Value); err != nil {
+					return false, err
+				}
+			default:
+				return false, objects.NewErrorKind(objects.VALUE_ERROR, "unknown key %s in a response", key)
+			}
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+		return stop, nil
+	}
+	return false, objects.NewErrorKind(objects.TYPE_ERROR, "handler passed to `serve` must return HASH, STRING or null, got %s", typeOf(result))
+}
+
+// header names in lower case, repeated headers joined by commas
+func headerHash(header http.Header) *objects.Hash {
+	var names []string
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := objects.NewHash()
+	for _, name := range names {
+		setString(hash, strings.ToLower(name), &objects.String{Value: strings.Join(header[name], ", ")})
+	}
+	return hash
+}
+
+func stringHash(name, key string, obj objects.Object) (map[string]string, *objects.Error) {
+	hash, ok := obj.(*objects.Hash)
+	if !ok {
+		return nil, objects.NewErrorKind(objects.TYPE_ERROR, "option %s of `%s` must be HASH, got %s", key, name, typeOf(obj))
+	}
+	values := make(map[string]string)
+	for _, pair := range hash.Ordered() {
+		str, ok := pair.Value.(*objects.String)
+		if !ok {
+			return nil, objects.NewErrorKind(objects.TYPE_ERROR, "values of option %s of `%s` must be STRING, got %s", key, name, typeOf(pair.Value))
+		}
+		values[pair.Key.Inspect()] = str.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,133 @@
+package builtins_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	tests := []builtinTest{
+		{input: "http.get(base + \"/echo\")[\"status\"]", want: "200"},
+		{input: "http.get(base + \"/echo\", {\"headers\": {\"X-Token\": \"secret\"}})[\"headers\"][\"x-token\"]", want: `"secret"`},
+		{input: "http.get(base + \"/echo\")[\"headers\"][\"x-method\"]", want: `"GET"`},
+		{input: "http.post(base + \"/echo\", {\"name\": \"ann\", \"tags\": [1, 2]})[\"headers\"][\"x-content-type\"]", want: `"application/json"`},
+		{input: "json_parse(http.post(base + \"/echo\", {\"name\": \"ann\", \"tags\": [1, 2]})[\"body\"])", want: `{"name": "ann", "tags": [1, 2]}`},
+		{input: "http.post(base + \"/echo\", \"plain\")[\"body\"]", want: `"plain"`},
+		{input: "http.request(\"put\", base + \"/echo\", {\"body\": \"x\"})[\"headers\"][\"x-method\"]", want: `"PUT"`},
+		{input: "http.get(base + \"/missing\")[\"status\"]", want: "404"},
+		{input: "http.get(base + \"/slow\", {\"timeout\": duration(\"20ms\")})", kind: objects.IO_ERROR, want: "Timeout"},
+		{input: "http.get(base, {\"nope\": 1})", kind: objects.VALUE_ERROR, want: "unknown option"},
+		{input: "http.get(1)", kind: objects.TYPE_ERROR, want: "must be STRING"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := run(t, "var base = \""+server.URL+"\"\n"+tt.input, objects.NET_CAPABILITY)
+			checkResult(t, got, tt.want, tt.kind)
+		})
+	}
+}
+
+const serveProgram = `http.serve("127.0.0.1:0", func(req) {
+    if req["path"] == "/hello" {
+        return "hello " + req["query"]["name"]
+    } elif req["path"] == "/json" {
+        return {"status": 201, "json": {"ok": true}}
+    } elif req["path"] == "/broken" {
+        return 1 + "a"
+    } elif req["path"] == "/stop" {
+        return {"body": "bye", "stop": true}
+    }
+    return {"status": 404, "body": "not found"}
+}, {"on_listen": addr => println(addr)})
+`
+
+func TestHTTPServe(t *testing.T) {
+	program, err := parser.Parse(serveProgram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := evaluator.New()
+	e.Capabilities = map[string]bool{objects.NET_CAPABILITY: true}
+	stdout := &lineWriter{lines: make(chan string, 10)}
+	var stderr bytes.Buffer
+	e.Stdout = stdout
+	e.Stderr = &stderr
+	done := make(chan objects.Object)
+	go func() {
+		done <- e.Eval(program, e.Env)
+	}()
+
+	var addr string
+	select {
+	case addr = <-stdout.lines:
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not report its address")
+	}
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	if resp, body := get("/hello?name=ann"); resp.StatusCode != 200 || body != "hello ann" {
+		t.Errorf("GET /hello = %d %q", resp.StatusCode, body)
+	}
+	if resp, body := get("/json"); resp.StatusCode != 201 || body != `{"ok":true}` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("GET /json = %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+	if resp, _ := get("/broken"); resp.StatusCode != 500 {
+		t.Errorf("GET /broken = %d, want 500", resp.StatusCode)
+	}
+	if resp, body := get("/stop"); resp.StatusCode != 200 || body != "bye" {
+		t.Errorf("GET /stop = %d %q", resp.StatusCode, body)
+	}
+	select {
+	case got := <-done:
+		if _, ok := got.(*objects.Null); !ok {
+			t.Errorf("serve returned %s, want null", repr(got))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop")
+	}
+	if !strings.Contains(stderr.String(), "GET /broken: ERROR: type mismatch: INTEGER + STRING") {
+		t.Errorf("stderr = %q, want the handler error", stderr.String())
+	}
+}
+
+// hands each line written to it to the test
+type lineWriter struct {
+	lines chan string
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.lines <- strings.TrimSuffix(string(p), "\n")
+	return len(p), nil
+}
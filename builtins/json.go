@@ -0,0 +1,276 @@
+package builtins
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["json_parse"] = objects.Builtin{Fn: jsonParse, MinArgs: 1, MaxArgs: 1}
+	BuiltIns["json_stringify"] = objects.Builtin{Fn: jsonStringify, MinArgs: 1, MaxArgs: 2, Returns: objects.STRING_TYPE}
+}
+
+// json_parse(str) turns json text into hashes, arrays, strings, numbers,
+// booleans and null, objects keep the order of their keys
+func jsonParse(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	str, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `json_parse` must be STRING, got %s", typeOf(args[0]))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(str.Value))
+	decoder.UseNumber()
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "invalid json: unexpected data after the value at offset %d", decoder.InputOffset())
+	}
+	return value
+}
+
+func invalidJSON(decoder *json.Decoder, err error) *objects.Error {
+	if err == io.EOF {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "invalid json: unexpected end of input")
+	}
+	return objects.NewErrorKind(objects.VALUE_ERROR, "invalid json: %s at offset %d", err, decoder.InputOffset())
+}
+
+// decodes the value starting at the next token
+func decodeJSON(decoder *json.Decoder) (objects.Object, *objects.Error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, invalidJSON(decoder, err)
+	}
+	switch tok := tok.(type) {
+	case nil:
+		return &objects.Null{}, nil
+	case bool:
+		return &objects.Boolean{Value: tok}, nil
+	case string:
+		return &objects.String{Value: tok}, nil
+	case json.Number:
+		return jsonNumber(tok)
+	case json.Delim:
+		switch tok {
+		case '[':
+			elements := []objects.Object{}
+			for decoder.More() {
+				element, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, element)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, invalidJSON(decoder, err)
+			}
+			return &objects.Array{Elements: elements}, nil
+		case '{':
+			hash := objects.NewHash()
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, invalidJSON(decoder, err)
+				}
+				value, decodeErr := decodeJSON(decoder)
+				if decodeErr != nil {
+					return nil, decodeErr
+				}
+				setString(hash, key.(string), value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, invalidJSON(decoder, err)
+			}
+			return hash, nil
+		}
+	}
+	return nil, objects.NewErrorKind(objects.VALUE_ERROR, "invalid json: unexpected %v at offset %d", tok, decoder.InputOffset())
+}
+
+// an INTEGER when the number has no fraction or exponent, integers too big
+// for one are an error rather than a rounded FLOAT
+func jsonNumber(number json.Number) (objects.Object, *objects.Error) {
+	text := number.String()
+	if !strings.ContainsAny(text, ".eE") {
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, objects.NewErrorKind(objects.VALUE_ERROR, "json integer %s does not fit in 64 bits", text)
+		}
+		return &objects.Integer{Value: value}, nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, objects.NewErrorKind(objects.VALUE_ERROR, "json number %s is out of range", text)
+	}
+	return &objects.Float{Value: value}, nil
+}
+
+// json_stringify(value, indent?) turns a value into json text, with nested
+// values on their own lines indented by indent spaces when it is given, times
+// and durations are written as ISO 8601 strings
+func jsonStringify(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	var indent string
+	if len(args) == 2 {
+		n, ok := args[1].(*objects.Integer)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `json_stringify` must be INTEGER, got %s", typeOf(args[1]))
+		}
+		if n.Value < 0 {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "indent must not be negative, got %d", n.Value)
+		}
+		indent = strings.Repeat(" ", int(n.Value))
+	}
+
+	e := &jsonEncoder{indent: indent, visiting: make(map[objects.Object]bool)}
+	if err := e.encode(args[0], ""); err != nil {
+		return err
+	}
+	return &objects.String{Value: e.out.String()}
+}
+
+type jsonEncoder struct {
+	out    bytes.Buffer
+	indent string
+	// arrays, hashes and structs being written, meeting one again is a cycle
+	visiting map[objects.Object]bool
+}
+
+func (e *jsonEncoder) encode(obj objects.Object, prefix string) *objects.Error {
+	switch obj := obj.(type) {
+	case nil, *objects.Null:
+		e.out.WriteString("null")
+	case *objects.Boolean:
+		e.out.WriteString(strconv.FormatBool(obj.Value))
+	case *objects.Integer:
+		e.out.WriteString(strconv.FormatInt(obj.Value, 10))
+	case *objects.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "cannot convert %s to json", obj.Inspect())
+		}
+		text := strconv.FormatFloat(obj.Value, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eE") {
+			// keeps it a FLOAT when it is parsed again
+			text += ".0"
+		}
+		e.out.WriteString(text)
+	case *objects.String:
+		e.quote(obj.Value)
+	case *objects.Time:
+		e.quote(obj.Value.Format(time.RFC3339Nano))
+	case *objects.Duration:
+		e.quote(isoDuration(obj.Value))
+	case *objects.Array:
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		defer delete(e.visiting, obj)
+
+		e.out.WriteByte('[')
+		for i, element := range obj.Elements {
+			e.separate(i, prefix+e.indent)
+			if err := e.encode(element, prefix+e.indent); err != nil {
+				return err
+			}
+		}
+		e.close(len(obj.Elements), prefix, ']')
+	case *objects.Hash:
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		defer delete(e.visiting, obj)
+
+		e.out.WriteByte('{')
+		for i, pair := range obj.Ordered() {
+			e.separate(i, prefix+e.indent)
+			switch key := pair.Key.(type) {
+			case *objects.String:
+				e.quote(key.Value)
+			case *objects.Integer:
+				e.quote(key.Inspect())
+			default:
+				return objects.NewErrorKind(objects.TYPE_ERROR, "cannot convert a %s key to json", typeOf(pair.Key))
+			}
+			e.colon()
+			if err := e.encode(pair.Value, prefix+e.indent); err != nil {
+				return err
+			}
+		}
+		e.close(len(obj.Pairs), prefix, '}')
+	case *objects.Struct:
+		if err := e.enter(obj); err != nil {
+			return err
+		}
+		defer delete(e.visiting, obj)
+
+		e.out.WriteByte('{')
+		for i, name := range obj.Def.Fields {
+			e.separate(i, prefix+e.indent)
+			e.quote(name)
+			e.colon()
+			if err := e.encode(obj.Fields[name], prefix+e.indent); err != nil {
+				return err
+			}
+		}
+		e.close(len(obj.Def.Fields), prefix, '}')
+	default:
+		return objects.NewErrorKind(objects.TYPE_ERROR, "cannot convert %s to json", typeOf(obj))
+	}
+	return nil
+}
+
+// marks the value as being written, failing when it already is
+func (e *jsonEncoder) enter(obj objects.Object) *objects.Error {
+	if e.visiting[obj] {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "cannot convert a value that contains itself to json")
+	}
+	e.visiting[obj] = true
+	return nil
+}
+
+func (e *jsonEncoder) quote(s string) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	e.out.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// starts the element at index i of an array or object
+func (e *jsonEncoder) separate(i int, prefix string) {
+	if i > 0 {
+		e.out.WriteByte(',')
+	}
+	if e.indent != "" {
+		e.out.WriteString("\n" + prefix)
+	}
+}
+
+func (e *jsonEncoder) colon() {
+	e.out.WriteByte(':')
+	if e.indent != "" {
+		e.out.WriteByte(' ')
+	}
+}
+
+// ends an array or object of n elements
+func (e *jsonEncoder) close(n int, prefix string, end byte) {
+	if n > 0 && e.indent != "" {
+		e.out.WriteString("\n" + prefix)
+	}
+	e.out.WriteByte(end)
+}
@@ -0,0 +1,48 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+// json text needs quotes, which alpha strings cannot hold, so json_parse is
+// called directly
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		text string
+		// repr of the result, or part of the message when kind is set
+		want string
+		kind string
+	}{
+		{text: `{"name":"ann","tags":["a","b"],"age":30,"score":1.5,"ok":true}`,
+			want: `{"name": "ann", "tags": ["a", "b"], "age": 30, "score": 1.5, "ok": true}`},
+		{text: `[2.0, {}]`, want: "[2.0, {}]"},
+		{text: `9007199254740993`, want: "9007199254740993"},
+		{text: `"a"`, want: `"a"`},
+		{text: `{"none":null}`, want: `{"none": null}`},
+		{text: `[1, 2`, kind: objects.VALUE_ERROR, want: "invalid json"},
+		{text: `92233720368547758070`, kind: objects.VALUE_ERROR, want: "does not fit in 64 bits"},
+	}
+	parse := builtins.BuiltIns["json_parse"].Fn
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			checkResult(t, parse(&objects.String{Value: tt.text}), tt.want, tt.kind)
+		})
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "json_stringify({\"name\": \"ann\", \"tags\": [\"a\", \"b\"], \"age\": 30, \"score\": 1.5, \"ok\": true})",
+			want: `"{\"name\":\"ann\",\"tags\":[\"a\",\"b\"],\"age\":30,\"score\":1.5,\"ok\":true}"`},
+		{input: "json_stringify({\"b\": 1, \"a\": [2.0, {}]}, 2)", want: `"{\n  \"b\": 1,\n  \"a\": [\n    2.0,\n    {}\n  ]\n}"`},
+		{input: "var shared = [1]\njson_stringify([shared, shared])", want: `"[[1],[1]]"`},
+		{input: "json_parse(json_stringify({\"b\": 1, \"a\": [2.0, {}]}, 2))", want: `{"b": 1, "a": [2.0, {}]}`},
+		{input: "json_stringify([len])", kind: objects.TYPE_ERROR, want: "cannot convert BUILTIN to json"},
+		{input: "var loop = []\nappend(loop, loop)\njson_stringify(loop)", kind: objects.VALUE_ERROR, want: "contains itself"},
+		{input: "json_parse(1)", kind: objects.TYPE_ERROR, want: "must be STRING"},
+		{input: "json_stringify()", kind: objects.ARGUMENT_ERROR, want: "wrong number of arguments"},
+	})
+}
@@ -0,0 +1,304 @@
+package builtins
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	Modules["math"] = &objects.Module{
+		Name: "math",
+		Members: map[string]objects.Object{
+			"PI":       &objects.Float{Value: math.Pi},
+			"E":        &objects.Float{Value: math.E},
+			"TAU":      &objects.Float{Value: 2 * math.Pi},
+			"INF":      &objects.Float{Value: math.Inf(1)},
+			"abs":      &objects.Builtin{Fn: abs, MinArgs: 1, MaxArgs: 1},
+			"floor":    &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return rounded("floor", math.Floor, args) }, MinArgs: 1, MaxArgs: 1, Returns: objects.INT_TYPE},
+			"ceil":     &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return rounded("ceil", math.Ceil, args) }, MinArgs: 1, MaxArgs: 1, Returns: objects.INT_TYPE},
+			"round":    &objects.Builtin{Fn: round, MinArgs: 1, MaxArgs: 2},
+			"sqrt":     floatFunction("sqrt", math.Sqrt),
+			"exp":      floatFunction("exp", math.Exp),
+			"log":      &objects.Builtin{Fn: logarithm, MinArgs: 1, MaxArgs: 2, Returns: objects.FLOAT_TYPE},
+			"log2":     floatFunction("log2", math.Log2),
+			"log10":    floatFunction("log10", math.Log10),
+			"sin":      floatFunction("sin", math.Sin),
+			"cos":      floatFunction("cos", math.Cos),
+			"tan":      floatFunction("tan", math.Tan),
+			"asin":     floatFunction("asin", math.Asin),
+			"acos":     floatFunction("acos", math.Acos),
+			"atan":     floatFunction("atan", math.Atan),
+			"pow":      &objects.Builtin{Fn: pow, MinArgs: 2, MaxArgs: 2, Returns: objects.FLOAT_TYPE},
+			"atan2":    &objects.Builtin{Fn: atan2, MinArgs: 2, MaxArgs: 2, Returns: objects.FLOAT_TYPE},
+			"min":      &objects.Builtin{Fn: minBuiltin, MinArgs: 1, MaxArgs: -1},
+			"max":      &objects.Builtin{Fn: maxBuiltin, MinArgs: 1, MaxArgs: -1},
+			"clamp":    &objects.Builtin{Fn: clamp, MinArgs: 3, MaxArgs: 3},
+			"gcd":      &objects.Builtin{Fn: gcd, MinArgs: 2, MaxArgs: 2, Returns: objects.INT_TYPE},
+			"lcm":      &objects.Builtin{Fn: lcm, MinArgs: 2, MaxArgs: 2, Returns: objects.INT_TYPE},
+			"is_prime": &objects.Builtin{Fn: isPrime, MinArgs: 1, MaxArgs: 1, Returns: objects.BOOL_TYPE},
+		},
+	}
+}
+
+// math.abs(x) keeps the type of x
+func abs(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch x := args[0].(type) {
+	case *objects.Integer:
+		if x.Value < 0 {
+			value, ok := objects.Negate(x.Value)
+			if !ok {
+				return objects.NewErrorKind(objects.VALUE_ERROR, "integer overflow: abs(%d)", x.Value)
+			}
+			return &objects.Integer{Value: value}
+		}
+		return x
+	case *objects.Float:
+		return &objects.Float{Value: math.Abs(x.Value)}
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `abs` must be INTEGER or FLOAT, got %s", typeOf(args[0]))
+}
+
+// math.floor(x) and math.ceil(x) return integers
+func rounded(name string, fn func(float64) float64, args []objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	switch x := args[0].(type) {
+	case *objects.Integer:
+		return x
+	case *objects.Float:
+		return toInteger(name, fn(x.Value))
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `%s` must be INTEGER or FLOAT, got %s", name, typeOf(args[0]))
+}
+
+// math.round(x, digits?) rounds half away from zero, to an integer or to a
+// float with that many digits after the point
+func round(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	x, ok := objects.Number(args[0])
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `round` must be INTEGER or FLOAT, got %s", typeOf(args[0]))
+	}
+	if len(args) == 1 {
+		if n, ok := args[0].(*objects.Integer); ok {
+			return n
+		}
+		return toInteger("round", math.Round(x))
+	}
+	digits, ok := args[1].(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `round` must be INTEGER, got %s", typeOf(args[1]))
+	}
+	scale := math.Pow(10, float64(digits.Value))
+	return &objects.Float{Value: math.Round(x*scale) / scale}
+}
+
+// math.log(x, base?) is the natural logarithm, or the logarithm in base
+func logarithm(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	values, err := floatArgs("log", args)
+	if err != nil {
+		return err
+	}
+	result := math.Log(values[0])
+	if len(values) == 2 {
+		result /= math.Log(values[1])
+	}
+	return finite("log", result, values...)
+}
+
+// math.pow(x, y) is always a float, x ** y keeps integers exact
+func pow(args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	values, err := floatArgs("pow", args)
+	if err != nil {
+		return err
+	}
+	return finite("pow", math.Pow(values[0], values[1]), values...)
+}
+
+// math.atan2(y, x) is the angle of the point (x, y)
+func atan2(args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	values, err := floatArgs("atan2", args)
+	if err != nil {
+		return err
+	}
+	return &objects.Float{Value: math.Atan2(values[0], values[1])}
+}
+
+// math.clamp(x, low, high) is x limited to the range from low to high
+func clamp(args ...objects.Object) objects.Object {
+	if len(args) != 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=3", len(args))
+	}
+	for i, arg := range args {
+		if _, ok := objects.Number(arg); !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "%s argument to `clamp` must be INTEGER or FLOAT, got %s", ordinals[i], typeOf(arg))
+		}
+	}
+	if order, _ := compare(args[1], args[2]); order > 0 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "low of `clamp` must not be more than high, got %s and %s", args[1].Inspect(), args[2].Inspect())
+	}
+	if order, _ := compare(args[0], args[1]); order < 0 {
+		return args[1]
+	}
+	if order, _ := compare(args[0], args[2]); order > 0 {
+		return args[2]
+	}
+	return args[0]
+}
+
+// math.gcd(a, b) is the greatest common divisor, never negative
+func gcd(args ...objects.Object) objects.Object {
+	a, b, err := integerPair("gcd", args)
+	if err != nil {
+		return err
+	}
+	divisor, ok := greatestDivisor(a, b)
+	if !ok {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "integer overflow: gcd(%d, %d)", a, b)
+	}
+	return &objects.Integer{Value: divisor}
+}
+
+// math.lcm(a, b) is the least common multiple, never negative
+func lcm(args ...objects.Object) objects.Object {
+	a, b, err := integerPair("lcm", args)
+	if err != nil {
+		return err
+	}
+	if a == 0 || b == 0 {
+		return &objects.Integer{Value: 0}
+	}
+	overflow := objects.NewErrorKind(objects.VALUE_ERROR, "integer overflow: lcm(%d, %d)", a, b)
+	divisor, ok := greatestDivisor(a, b)
+	if !ok {
+		return overflow
+	}
+	multiple, ok := objects.Multiply(a/divisor, b)
+	if ok && multiple < 0 {
+		multiple, ok = objects.Negate(multiple)
+	}
+	if !ok {
+		return overflow
+	}
+	return &objects.Integer{Value: multiple}
+}
+
+// math.is_prime(n) is exact for every integer
+func isPrime(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	n, ok := args[0].(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `is_prime` must be INTEGER, got %s", typeOf(args[0]))
+	}
+	// the Baillie-PSW test behind ProbablyPrime makes no mistakes below 2^64
+	return &objects.Boolean{Value: n.Value > 1 && big.NewInt(n.Value).ProbablyPrime(0)}
+}
+
+var ordinals = []string{"first", "second", "third"}
+
+// a function of one number that returns a float
+func floatFunction(name string, fn func(float64) float64) *objects.Builtin {
+	return &objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+		if len(args) != 1 {
+			return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+		}
+		values, err := floatArgs(name, args)
+		if err != nil {
+			return err
+		}
+		return finite(name, fn(values[0]), values...)
+	}, MinArgs: 1, MaxArgs: 1, Returns: objects.FLOAT_TYPE}
+}
+
+func floatArgs(name string, args []objects.Object) ([]float64, *objects.Error) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		value, ok := objects.Number(arg)
+		if !ok {
+			if len(args) == 1 {
+				return nil, objects.NewErrorKind(objects.TYPE_ERROR, "argument to `%s` must be INTEGER or FLOAT, got %s", name, typeOf(arg))
+			}
+			return nil, objects.NewErrorKind(objects.TYPE_ERROR, "%s argument to `%s` must be INTEGER or FLOAT, got %s", ordinals[i], name, typeOf(arg))
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// a result that is not a number, or infinite when the arguments were not,
+// means the function is not defined there
+func finite(name string, result float64, inputs ...float64) objects.Object {
+	if math.IsNaN(result) || math.IsInf(result, 0) && !anyInf(inputs) {
+		var out string
+		for i, input := range inputs {
+			if i > 0 {
+				out += ", "
+			}
+			out += (&objects.Float{Value: input}).Inspect()
+		}
+		return objects.NewErrorKind(objects.VALUE_ERROR, "`%s` is not defined for %s", name, out)
+	}
+	return &objects.Float{Value: result}
+}
+
+func anyInf(values []float64) bool {
+	for _, value := range values {
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toInteger(name string, value float64) objects.Object {
+	if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "result of `%s` does not fit in an integer", name)
+	}
+	return &objects.Integer{Value: int64(value)}
+}
+
+func integerPair(name string, args []objects.Object) (int64, int64, *objects.Error) {
+	if len(args) != 2 {
+		return 0, 0, objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	var values [2]int64
+	for i, arg := range args {
+		n, ok := arg.(*objects.Integer)
+		if !ok {
+			return 0, 0, objects.NewErrorKind(objects.TYPE_ERROR, "%s argument to `%s` must be INTEGER, got %s", ordinals[i], name, typeOf(arg))
+		}
+		values[i] = n.Value
+	}
+	return values[0], values[1], nil
+}
+
+// the divisor and whether it fits in an int64, it does not when it is
+// -MinInt64
+func greatestDivisor(a, b int64) (int64, bool) {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return objects.Negate(a)
+	}
+	return a, true
+}
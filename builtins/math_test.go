@@ -0,0 +1,50 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestMath(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "math.abs(-3)", want: "3"},
+		{input: "math.abs(-2.5)", want: "2.5"},
+		{input: "math.sqrt(2)", want: "1.4142135623730951"},
+		{input: "math.floor(2.7)", want: "2"},
+		{input: "math.ceil(-2.7)", want: "-2"},
+		{input: "math.round(2.5)", want: "3"},
+		{input: "math.round(3.14159, 2)", want: "3.14"},
+		{input: "math.pow(2, 3)", want: "8.0"},
+		{input: "math.log(math.E)", want: "1.0"},
+		{input: "math.log(8, 2)", want: "3.0"},
+		{input: "math.log10(1000)", want: "3.0"},
+		{input: "math.sin(0)", want: "0.0"},
+		{input: "math.cos(math.PI)", want: "-1.0"},
+		{input: "math.atan2(1, 1) == math.PI / 4.0", want: "true"},
+		{input: "math.sqrt(4) * 2", want: "4.0"},
+		{input: "var r = 2\nmath.PI * r * r", want: "12.566370614359172"},
+		{input: "math.min(3, 1, 2)", want: "1"},
+		{input: "math.max([3, 1, 2])", want: "3"},
+		{input: "math.clamp(15, 0, 10)", want: "10"},
+		{input: "math.clamp(-1.5, 0.0, 1.0)", want: "0.0"},
+		{input: "math.gcd(12, -18)", want: "6"},
+		{input: "math.lcm(4, 6)", want: "12"},
+		{input: "math.is_prime(2147483647)", want: "true"},
+		{input: "math.is_prime(1)", want: "false"},
+		{input: "math.is_prime(91)", want: "false"},
+		{input: "math.sqrt(-1)", kind: objects.VALUE_ERROR, want: "not defined"},
+		{input: "math.log(0)", kind: objects.VALUE_ERROR, want: "not defined"},
+		{input: "math.clamp(1, 2, 0)", kind: objects.VALUE_ERROR, want: "must not be more than high"},
+		{input: "math.abs(-9223372036854775807 - 1)", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "math.gcd(-9223372036854775807 - 1, 0)", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "math.gcd(-9223372036854775807 - 1, -9223372036854775807 - 1)", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "math.lcm(9223372036854775807, 2)", kind: objects.VALUE_ERROR, want: "integer overflow: lcm(9223372036854775807, 2)"},
+		{input: "math.lcm(-9223372036854775807 - 1, 1)", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "math.gcd(-9223372036854775807 - 1, 6)", want: "2"},
+		{input: "math.lcm(4611686018427387904, 2)", want: "4611686018427387904"},
+		{input: "math.sqrt(\"a\")", kind: objects.TYPE_ERROR, want: ""},
+		{input: "math.gcd(1.5, 2)", kind: objects.TYPE_ERROR, want: ""},
+		{input: "math.sqrt()", kind: objects.ARGUMENT_ERROR, want: "wrong number of arguments"},
+	})
+}
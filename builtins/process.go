@@ -0,0 +1,244 @@
+package builtins
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	Modules["process"] = &objects.Module{
+		Name:       "process",
+		Capability: objects.PROCESS_CAPABILITY,
+		Members: map[string]objects.Object{
+			"exec":    &objects.Builtin{Native: processExec, MinArgs: 1, MaxArgs: 3, Returns: objects.HASH_TYPE},
+			"env_get": &objects.Builtin{Fn: envGet, MinArgs: 1, MaxArgs: 2},
+			"env_set": &objects.Builtin{Fn: envSet, MinArgs: 2, MaxArgs: 2},
+			"cwd":     &objects.Builtin{Fn: cwd, Returns: objects.STRING_TYPE},
+			"chdir":   &objects.Builtin{Fn: chdir, MinArgs: 1, MaxArgs: 1},
+			"exit":    &objects.Builtin{Fn: exit, MaxArgs: 1},
+		},
+	}
+}
+
+// options process.exec takes
+type execOptions struct {
+	dir   string
+	env   []string
+	stdin string
+	// kills the program when it runs longer, 0 waits for it however long
+	timeout time.Duration
+	// also writes the output of the program to the interpreter's stdout and
+	// stderr as it arrives
+	stream bool
+}
+
+// process.exec(cmd, args?, options?) runs a program, without a shell, and
+// waits for it to finish, returning a hash of its "stdout", "stderr" and
+// exit "code", options takes a "cwd", an "env" hash added to the
+// environment, "stdin" text, a "timeout" duration and "stream": true to
+// also write the output to stdout and stderr while it runs
+func processExec(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+	name, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `exec` must be STRING, got %s", typeOf(args[0]))
+	}
+	var cmdArgs []string
+	if len(args) > 1 {
+		arr, ok := args[1].(*objects.Array)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `exec` must be ARRAY, got %s", typeOf(args[1]))
+		}
+		for _, element := range arr.Elements {
+			str, ok := element.(*objects.String)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "arguments passed to `exec` must be STRING, got %s", typeOf(element))
+			}
+			cmdArgs = append(cmdArgs, str.Value)
+		}
+	}
+	options := &execOptions{}
+	if len(args) > 2 {
+		if err := readExecOptions(args[2], options); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, name.Value, cmdArgs...)
+	cmd.Dir = options.dir
+	if options.env != nil {
+		cmd.Env = append(os.Environ(), options.env...)
+	}
+	cmd.Stdin = strings.NewReader(options.stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if options.stream {
+		cmd.Stdout = io.MultiWriter(&stdout, interp.Output())
+		cmd.Stderr = io.MultiWriter(&stderr, interp.ErrorOutput())
+	}
+
+	code := 0
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return objects.NewErrorKind(objects.IO_ERROR, "`exec` of %s timed out after %s", name.Value, options.timeout)
+		}
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return ioError(err)
+		}
+		code = exitErr.ExitCode()
+	}
+
+	result := objects.NewHash()
+	setString(result, "stdout", &objects.String{Value: stdout.String()})
+	setString(result, "stderr", &objects.String{Value: stderr.String()})
+	setString(result, "code", &objects.Integer{Value: int64(code)})
+	return result
+}
+
+func readExecOptions(obj objects.Object, options *execOptions) *objects.Error {
+	hash, ok := obj.(*objects.Hash)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "third argument to `exec` must be HASH, got %s", typeOf(obj))
+	}
+	for _, pair := range hash.Ordered() {
+		key := pair.Key.Inspect()
+		var err *objects.Error
+		switch key {
+		case "cwd":
+			options.dir, err = optionString(key, pair.Value)
+		case "stdin":
+			options.stdin, err = optionString(key, pair.Value)
+		case "env":
+			env, ok := pair.Value.(*objects.Hash)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "option env of `exec` must be HASH, got %s", typeOf(pair.Value))
+			}
+			options.env = []string{}
+			for _, variable := range env.Ordered() {
+				value, err := optionString("env", variable.Value)
+				if err != nil {
+					return err
+				}
+				options.env = append(options.env, variable.Key.Inspect()+"="+value)
+			}
+		case "timeout":
+			d, ok := pair.Value.(*objects.Duration)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "option timeout of `exec` must be DURATION, got %s", typeOf(pair.Value))
+			}
+			options.timeout = d.Value
+		case "stream":
+			options.stream, err = optionBool("exec", key, pair.Value)
+		default:
+			err = objects.NewErrorKind(objects.VALUE_ERROR, "unknown option %s for `exec`", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func optionString(key string, obj objects.Object) (string, *objects.Error) {
+	str, ok := obj.(*objects.String)
+	if !ok {
+		return "", objects.NewErrorKind(objects.TYPE_ERROR, "option %s of `exec` must be STRING, got %s", key, typeOf(obj))
+	}
+	return str.Value, nil
+}
+
+// process.env_get(name, default?) is the environment variable, or default,
+// null unless given, when it is not set
+func envGet(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	name, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `env_get` must be STRING, got %s", typeOf(args[0]))
+	}
+	if value, ok := os.LookupEnv(name.Value); ok {
+		return &objects.String{Value: value}
+	}
+	if len(args) == 2 {
+		return args[1]
+	}
+	return &objects.Null{}
+}
+
+// process.env_set(name, value) sets an environment variable for the program
+// and the programs it runs
+func envSet(args ...objects.Object) objects.Object {
+	strs, err := stringArgs("env_set", 2, args)
+	if err != nil {
+		return err
+	}
+	if err := os.Setenv(strs[0], strs[1]); err != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+	}
+	return &objects.Null{}
+}
+
+// process.cwd() is the working directory
+func cwd(args ...objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return ioError(err)
+	}
+	return &objects.String{Value: dir}
+}
+
+// process.chdir(path) changes the working directory
+func chdir(args ...objects.Object) objects.Object {
+	paths, err := stringArgs("chdir", 1, args)
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(paths[0]); err != nil {
+		return ioError(err)
+	}
+	return &objects.Null{}
+}
+
+// process.exit(code?) stops the program with the status, 0 by default,
+// finally blocks still run but try does not catch it
+func exit(args ...objects.Object) objects.Object {
+	if len(args) > 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0 or 1", len(args))
+	}
+	code := 0
+	if len(args) == 1 {
+		n, ok := args[0].(*objects.Integer)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `exit` must be INTEGER, got %s", typeOf(args[0]))
+		}
+		if n.Value < 0 || n.Value > 255 {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "exit code must be from 0 to 255, got %d", n.Value)
+		}
+		code = int(n.Value)
+	}
+	err := objects.NewErrorKind(objects.EXIT, "exit(%d)", code)
+	err.Fatal = true
+	err.Code = code
+	return err
+}
@@ -0,0 +1,39 @@
+package builtins_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestProcess(t *testing.T) {
+	// the programs set a variable and change directory
+	t.Setenv("ALPHA_TEST_VARIABLE", "")
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	tests := []builtinTest{
+		{input: "process.exec(\"/bin/echo\", [\"hello\", \"world\"])", want: `{"stdout": "hello world\n", "stderr": "", "code": 0}`},
+		{input: "process.exec(\"/bin/sh\", [\"-c\", \"echo oops >&2; exit 3\"])", want: `{"stdout": "", "stderr": "oops\n", "code": 3}`},
+		{input: "process.exec(\"/bin/cat\", [], {\"stdin\": \"piped\"})[\"stdout\"]", want: `"piped"`},
+		{input: "process.exec(\"/bin/sh\", [\"-c\", \"echo $ALPHA_GREETING\"], {\"env\": {\"ALPHA_GREETING\": \"hi\"}})[\"stdout\"]", want: `"hi\n"`},
+		{input: "process.exec(\"/bin/pwd\", [], {\"cwd\": dir})[\"stdout\"]", want: `"DIR\n"`},
+		{input: "process.env_get(\"ALPHA_UNSET_VARIABLE\", \"fallback\")", want: `"fallback"`},
+		{input: "process.env_set(\"ALPHA_TEST_VARIABLE\", \"set\")\nprocess.env_get(\"ALPHA_TEST_VARIABLE\")", want: `"set"`},
+		{input: "process.chdir(dir)\nprocess.cwd()", want: `"DIR"`},
+		{input: "process.exec(\"/bin/sleep\", [\"5\"], {\"timeout\": duration(\"50ms\")})", kind: objects.IO_ERROR, want: "timed out"},
+		{input: "process.exec(\"/nonexistent/program\")", kind: objects.IO_ERROR, want: "no such file"},
+		{input: "process.exec(\"/bin/echo\", [1])", kind: objects.TYPE_ERROR, want: ""},
+		{input: "process.chdir(dir + \"/missing\")", kind: objects.IO_ERROR, want: "no such file"},
+	}
+	runFileTests(t, tests, nil, objects.PROCESS_CAPABILITY)
+}
+
+func TestProcessExit(t *testing.T) {
+	got := run(t, "try {\n    process.exit(4)\n} catch (e) {\n    1\n}", objects.PROCESS_CAPABILITY)
+	err, ok := got.(*objects.Error)
+	if !ok || err.Kind != objects.EXIT || err.Code != 4 || !err.Fatal {
+		t.Fatalf("got %s, want a fatal Exit with code 4", repr(got))
+	}
+}
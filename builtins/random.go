@@ -0,0 +1,154 @@
+package builtins
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["rand"] = objects.Builtin{Native: randInt, MinArgs: 1, MaxArgs: 2, Returns: objects.INT_TYPE}
+	Modules["random"] = &objects.Module{
+		Name: "random",
+		Members: map[string]objects.Object{
+			"seed":    &objects.Builtin{Native: randomSeed, MinArgs: 1, MaxArgs: 1},
+			"int":     &objects.Builtin{Native: randomInt, MinArgs: 2, MaxArgs: 2, Returns: objects.INT_TYPE},
+			"float":   &objects.Builtin{Native: randomFloat, Returns: objects.FLOAT_TYPE},
+			"choice":  &objects.Builtin{Native: randomChoice, MinArgs: 1, MaxArgs: 1},
+			"shuffle": &objects.Builtin{Native: randomShuffle, MinArgs: 1, MaxArgs: 1, Returns: objects.ARRAY_TYPE},
+			"sample":  &objects.Builtin{Native: randomSample, MinArgs: 2, MaxArgs: 2, Returns: objects.ARRAY_TYPE},
+		},
+	}
+}
+
+// rand(high) is an integer from 0 to high and rand(low, high) one from low
+// to high, both ends included
+func randInt(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	switch len(args) {
+	case 1:
+		return randomBetween(interp, "rand", &objects.Integer{Value: 0}, args[0])
+	case 2:
+		return randomBetween(interp, "rand", args[0], args[1])
+	}
+	return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+}
+
+// random.seed(n) starts the generator over, the same seed gives the same
+// numbers
+func randomSeed(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	n, ok := args[0].(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `seed` must be INTEGER, got %s", typeOf(args[0]))
+	}
+	interp.Random().Seed(n.Value)
+	return &objects.Null{}
+}
+
+// random.int(low, high) is an integer from low to high, both included
+func randomInt(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	return randomBetween(interp, "int", args[0], args[1])
+}
+
+// random.float() is a float from 0 up to but not including 1
+func randomFloat(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	return &objects.Float{Value: interp.Random().Float64()}
+}
+
+// random.choice(array) is one of the elements
+func randomChoice(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `choice` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	if len(arr.Elements) == 0 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "`choice` of an empty array")
+	}
+	return arr.Elements[interp.Random().Intn(len(arr.Elements))]
+}
+
+// random.shuffle(array) returns the elements in a random order, the array
+// itself is left as it was
+func randomShuffle(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `shuffle` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	elements := copyElements(arr.Elements)
+	interp.Random().Shuffle(len(elements), func(i, j int) {
+		elements[i], elements[j] = elements[j], elements[i]
+	})
+	return &objects.Array{Elements: elements}
+}
+
+// random.sample(array, k) returns k elements from different places in the
+// array, in a random order
+func randomSample(interp objects.Interpreter, args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	arr, ok := args[0].(*objects.Array)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `sample` must be ARRAY, got %s", typeOf(args[0]))
+	}
+	k, ok := args[1].(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `sample` must be INTEGER, got %s", typeOf(args[1]))
+	}
+	if k.Value < 0 || k.Value > int64(len(arr.Elements)) {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "sample of %d from an array of %d", k.Value, len(arr.Elements))
+	}
+	// the first k steps of a shuffle
+	elements := copyElements(arr.Elements)
+	random := interp.Random()
+	for i := 0; i < int(k.Value); i++ {
+		j := i + random.Intn(len(elements)-i)
+		elements[i], elements[j] = elements[j], elements[i]
+	}
+	return &objects.Array{Elements: elements[:k.Value]}
+}
+
+func randomBetween(interp objects.Interpreter, name string, lowObj, highObj objects.Object) objects.Object {
+	low, ok := lowObj.(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "arguments to `%s` must be INTEGER, got %s", name, typeOf(lowObj))
+	}
+	high, ok := highObj.(*objects.Integer)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "arguments to `%s` must be INTEGER, got %s", name, typeOf(highObj))
+	}
+	if low.Value > high.Value {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "low of `%s` must not be more than high, got %d and %d", name, low.Value, high.Value)
+	}
+	return &objects.Integer{Value: between(interp.Random(), low.Value, high.Value)}
+}
+
+// a number from low to high, both included, without overflowing when the
+// range is wider than an int64
+func between(random *rand.Rand, low, high int64) int64 {
+	span := uint64(high) - uint64(low)
+	if span < math.MaxInt64 {
+		return low + random.Int63n(int64(span)+1)
+	}
+	for {
+		n := int64(random.Uint64())
+		if n >= low && n <= high {
+			return n
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+const draw = "[random.int(1, 100), random.float(), random.choice([\"a\", \"b\", \"c\"])]"
+
+func TestRandom(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: "random.seed(7)\nvar first = " + draw + "\nrandom.seed(7)\nvar second = " + draw + "\nrepr(first) == repr(second)", want: "true"},
+		{input: "rand(5, 5)", want: "5"},
+		{input: "random.int(-3, -3)", want: "-3"},
+		{input: "random.float() >= 0.0", want: "true"},
+		{input: "random.float() < 1.0", want: "true"},
+		{input: "var xs = [1, 2, 3, 4, 5]\nrandom.shuffle(xs)\nxs", want: "[1, 2, 3, 4, 5]"},
+		{input: "sort(random.shuffle([1, 2, 3, 4, 5]))", want: "[1, 2, 3, 4, 5]"},
+		{input: "len(unique(random.sample([1, 2, 3, 4, 5], 3)))", want: "3"},
+		{input: "random.sample([1, 2, 3, 4, 5], 0)", want: "[]"},
+		{input: "rand(5, 1)", kind: objects.VALUE_ERROR, want: "must not be more than high"},
+		{input: "rand(\"a\")", kind: objects.TYPE_ERROR, want: "must be INTEGER"},
+		{input: "random.choice([])", kind: objects.VALUE_ERROR, want: "empty array"},
+		{input: "random.sample([1, 2, 3, 4, 5], 6)", kind: objects.VALUE_ERROR, want: "sample of 6"},
+	})
+}
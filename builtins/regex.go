@@ -0,0 +1,192 @@
+package builtins
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["regex"] = objects.Builtin{Fn: regex, MinArgs: 1, MaxArgs: 1, Returns: objects.REGEX_TYPE}
+}
+
+// how many compiled patterns are kept, the cache starts over when it is full
+const REGEX_CACHE_SIZE = 256
+
+// patterns already compiled, a regex has no state of its own so the same
+// object can be shared
+var regexCache = struct {
+	sync.Mutex
+	patterns map[string]*objects.Regex
+}{patterns: make(map[string]*objects.Regex)}
+
+// regex(pattern) compiles a pattern in the RE2 syntax, matching takes time
+// linear in the length of the input
+func regex(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	pattern, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `regex` must be STRING, got %s", typeOf(args[0]))
+	}
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+	if re, ok := regexCache.patterns[pattern.Value]; ok {
+		return re
+	}
+	compiled, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "invalid regex: %s", err)
+	}
+	re := newRegex(pattern.Value, compiled)
+	if len(regexCache.patterns) >= REGEX_CACHE_SIZE {
+		regexCache.patterns = make(map[string]*objects.Regex)
+	}
+	regexCache.patterns[pattern.Value] = re
+	return re
+}
+
+func newRegex(pattern string, compiled *regexp.Regexp) *objects.Regex {
+	re := &objects.Regex{Pattern: pattern, Regexp: compiled}
+	re.Methods = map[string]objects.Object{
+		"match":    &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return regexMatch(re, args) }},
+		"find_all": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return regexFindAll(re, args) }},
+		"captures": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return regexCaptures(re, args) }},
+		"replace": &objects.Builtin{Native: func(interp objects.Interpreter, args ...objects.Object) objects.Object {
+			return regexReplace(interp, re, args)
+		}},
+		"split": &objects.Builtin{Fn: func(args ...objects.Object) objects.Object { return regexSplit(re, args) }},
+	}
+	return re
+}
+
+// re.match(str) reports whether the pattern matches somewhere in str
+func regexMatch(re *objects.Regex, args []objects.Object) objects.Object {
+	strs, err := stringArgs("match", 1, args)
+	if err != nil {
+		return err
+	}
+	return &objects.Boolean{Value: re.Regexp.MatchString(strs[0])}
+}
+
+// re.find_all(str, limit?) returns the text of every match, or of the first
+// limit matches
+func regexFindAll(re *objects.Regex, args []objects.Object) objects.Object {
+	str, limit, err := stringAndLimit("find_all", args)
+	if err != nil {
+		return err
+	}
+	matches := []objects.Object{}
+	for _, match := range re.Regexp.FindAllString(str, limit) {
+		matches = append(matches, &objects.String{Value: match})
+	}
+	return &objects.Array{Elements: matches}
+}
+
+// re.captures(str) returns the groups of the first match in a hash, under
+// their index with 0 for the whole match and under their name when they have
+// one, groups that did not take part are null, no match gives null
+func regexCaptures(re *objects.Regex, args []objects.Object) objects.Object {
+	strs, err := stringArgs("captures", 1, args)
+	if err != nil {
+		return err
+	}
+	indexes := re.Regexp.FindStringSubmatchIndex(strs[0])
+	if indexes == nil {
+		return &objects.Null{}
+	}
+	hash := objects.NewHash()
+	for i, name := range re.Regexp.SubexpNames() {
+		var group objects.Object = &objects.Null{}
+		if start := indexes[2*i]; start >= 0 {
+			group = &objects.String{Value: strs[0][start:indexes[2*i+1]]}
+		}
+		index := &objects.Integer{Value: int64(i)}
+		hash.Set(index.HashKey(), objects.HashPair{Key: index, Value: group})
+		if name != "" {
+			setString(hash, name, group)
+		}
+	}
+	return hash
+}
+
+// re.replace(str, replacement) replaces every match, $1 and ${name} in the
+// replacement stand for groups, a function is called with the text of each
+// match and returns what replaces it
+func regexReplace(interp objects.Interpreter, re *objects.Regex, args []objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	str, ok := args[0].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `replace` must be STRING, got %s", typeOf(args[0]))
+	}
+	if replacement, ok := args[1].(*objects.String); ok {
+		return &objects.String{Value: re.Regexp.ReplaceAllString(str.Value, replacement.Value)}
+	}
+	if err := checkFunction("replace", args[1]); err != nil {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `replace` must be STRING or FUNCTION, got %s", typeOf(args[1]))
+	}
+
+	var failed objects.Object
+	replaced := re.Regexp.ReplaceAllStringFunc(str.Value, func(match string) string {
+		if failed != nil {
+			return match
+		}
+		result, err := call(interp, args[1], &objects.String{Value: match})
+		if err != nil {
+			failed = err
+			return match
+		}
+		replacement, ok := result.(*objects.String)
+		if !ok {
+			failed = objects.NewErrorKind(objects.TYPE_ERROR, "function passed to `replace` must return STRING, got %s", typeOf(result))
+			return match
+		}
+		return replacement.Value
+	})
+	if failed != nil {
+		return failed
+	}
+	return &objects.String{Value: replaced}
+}
+
+// re.split(str, limit?) returns the text between the matches, at most limit
+// pieces when it is given
+func regexSplit(re *objects.Regex, args []objects.Object) objects.Object {
+	str, limit, err := stringAndLimit("split", args)
+	if err != nil {
+		return err
+	}
+	pieces := []objects.Object{}
+	for _, piece := range re.Regexp.Split(str, limit) {
+		pieces = append(pieces, &objects.String{Value: piece})
+	}
+	return &objects.Array{Elements: pieces}
+}
+
+// a string and an optional count, -1 when the count is left out
+func stringAndLimit(name string, args []objects.Object) (string, int, *objects.Error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", 0, objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	str, ok := args[0].(*objects.String)
+	if !ok {
+		return "", 0, objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `%s` must be STRING, got %s", name, typeOf(args[0]))
+	}
+	limit := -1
+	if len(args) == 2 {
+		n, ok := args[1].(*objects.Integer)
+		if !ok {
+			return "", 0, objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `%s` must be INTEGER, got %s", name, typeOf(args[1]))
+		}
+		if n.Value < 0 {
+			return "", 0, objects.NewErrorKind(objects.VALUE_ERROR, "limit of `%s` must not be negative, got %d", name, n.Value)
+		}
+		limit = int(n.Value)
+	}
+	return str.Value, limit, nil
+}
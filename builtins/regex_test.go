@@ -0,0 +1,29 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+const datePattern = `var date = regex("(?P<year>\d{4})-(?P<month>\d{2})")` + "\n"
+
+func TestRegex(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: datePattern + "date.match(\"on 2024-05\")", want: "true"},
+		{input: datePattern + "date.match(\"on 24-05\")", want: "false"},
+		{input: datePattern + "date.pattern", want: `"(?P<year>\\d{4})-(?P<month>\\d{2})"`},
+		{input: datePattern + "date == regex(date.pattern)", want: "true"},
+		{input: datePattern + "date.find_all(\"2024-05 and 2025-01\")", want: `["2024-05", "2025-01"]`},
+		{input: datePattern + "date.find_all(\"2024-05 and 2025-01\", 1)", want: `["2024-05"]`},
+		{input: datePattern + "date.captures(\"on 2024-05\")", want: `{0: "2024-05", 1: "2024", "year": "2024", 2: "05", "month": "05"}`},
+		{input: datePattern + "date.captures(\"on 24-05\")", want: "null"},
+		{input: datePattern + "date.replace(\"2024-05\", \"${month}/${year}\")", want: `"05/2024"`},
+		{input: "regex(\"[aeiou]\").replace(\"banana\", v => v + v)", want: `"baanaanaa"`},
+		{input: "regex(\",\\s*\").split(\"a, b,c\")", want: `["a", "b", "c"]`},
+		{input: "regex(\",\").split(\"a,b,c\", 2)", want: `["a", "b,c"]`},
+		{input: "regex(\"a(\")", kind: objects.VALUE_ERROR, want: "invalid regex: error parsing regexp: missing closing )"},
+		{input: "regex(1)", kind: objects.TYPE_ERROR, want: "must be STRING"},
+		{input: "regex(\"a\").replace(\"a\", 1)", kind: objects.TYPE_ERROR, want: ""},
+	})
+}
@@ -0,0 +1,209 @@
+package builtins
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// time zones are looked up without relying on the system's database
+	_ "time/tzdata"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func init() {
+	BuiltIns["now"] = objects.Builtin{Fn: now, Returns: objects.TIME_TYPE}
+	BuiltIns["parse_time"] = objects.Builtin{Fn: parseTime, MinArgs: 1, MaxArgs: 3, Returns: objects.TIME_TYPE}
+	BuiltIns["format_time"] = objects.Builtin{Fn: formatTime, MinArgs: 1, MaxArgs: 2, Returns: objects.STRING_TYPE}
+	BuiltIns["in_zone"] = objects.Builtin{Fn: inZone, MinArgs: 2, MaxArgs: 2, Returns: objects.TIME_TYPE}
+	BuiltIns["duration"] = objects.Builtin{Fn: duration, MinArgs: 1, MaxArgs: 2, Returns: objects.DURATION_TYPE}
+	BuiltIns["sleep"] = objects.Builtin{Fn: sleep, MinArgs: 1, MaxArgs: 1}
+}
+
+// layouts that can be given by name, others are written like Go's reference
+// time, 2006-01-02 15:04:05
+var layouts = map[string]string{
+	"rfc3339":  time.RFC3339Nano,
+	"rfc1123":  time.RFC1123,
+	"date":     "2006-01-02",
+	"time":     "15:04:05",
+	"datetime": "2006-01-02 15:04:05",
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// now()
+func now(args ...objects.Object) objects.Object {
+	if len(args) != 0 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0", len(args))
+	}
+	return &objects.Time{Value: time.Now()}
+}
+
+// parse_time(str, layout?, zone?) reads a time written in the layout, rfc3339
+// by default, times without an offset are in zone, UTC by default
+func parseTime(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 to 3", len(args))
+	}
+	strs, err := stringArgs("parse_time", len(args), args)
+	if err != nil {
+		return err
+	}
+	layout := time.RFC3339Nano
+	if len(strs) > 1 {
+		layout = layoutOf(strs[1])
+	}
+	location := time.UTC
+	if len(strs) > 2 {
+		loc, err := zone(strs[2])
+		if err != nil {
+			return err
+		}
+		location = loc
+	}
+	t, parseErr := time.ParseInLocation(layout, strs[0], location)
+	if parseErr != nil {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "%s", parseErr)
+	}
+	return &objects.Time{Value: t}
+}
+
+// format_time(t, layout?) writes the time in the layout, rfc3339 by default
+func formatTime(args ...objects.Object) objects.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+	t, ok := args[0].(*objects.Time)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `format_time` must be TIME, got %s", typeOf(args[0]))
+	}
+	layout := time.RFC3339Nano
+	if len(args) == 2 {
+		str, ok := args[1].(*objects.String)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `format_time` must be STRING, got %s", typeOf(args[1]))
+		}
+		layout = layoutOf(str.Value)
+	}
+	return &objects.String{Value: t.Value.Format(layout)}
+}
+
+// in_zone(t, zone) is the same instant in another time zone, like
+// "Europe/London", "UTC" or "Local"
+func inZone(args ...objects.Object) objects.Object {
+	if len(args) != 2 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2", len(args))
+	}
+	t, ok := args[0].(*objects.Time)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `in_zone` must be TIME, got %s", typeOf(args[0]))
+	}
+	name, ok := args[1].(*objects.String)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `in_zone` must be STRING, got %s", typeOf(args[1]))
+	}
+	location, err := zone(name.Value)
+	if err != nil {
+		return err
+	}
+	return &objects.Time{Value: t.Value.In(location)}
+}
+
+// duration(str) reads a duration like "1h30m" or "250ms", duration(n, unit)
+// is n of a unit, one of ns, us, ms, s, m and h
+func duration(args ...objects.Object) objects.Object {
+	switch len(args) {
+	case 1:
+		str, ok := args[0].(*objects.String)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `duration` must be STRING, got %s", typeOf(args[0]))
+		}
+		d, err := time.ParseDuration(str.Value)
+		if err != nil {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "%s", err)
+		}
+		return &objects.Duration{Value: d}
+	case 2:
+		name, ok := args[1].(*objects.String)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "second argument to `duration` must be STRING, got %s", typeOf(args[1]))
+		}
+		unit, ok := durationUnits[name.Value]
+		if !ok {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "unknown unit %q, want one of ns, us, ms, s, m or h", name.Value)
+		}
+		switch n := args[0].(type) {
+		case *objects.Integer:
+			return &objects.Duration{Value: time.Duration(n.Value) * unit}
+		case *objects.Float:
+			return &objects.Duration{Value: time.Duration(n.Value * float64(unit))}
+		}
+		return objects.NewErrorKind(objects.TYPE_ERROR, "first argument to `duration` must be INTEGER or FLOAT, got %s", typeOf(args[0]))
+	}
+	return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2", len(args))
+}
+
+// sleep(d) pauses the program for the duration
+func sleep(args ...objects.Object) objects.Object {
+	if len(args) != 1 {
+		return objects.NewErrorKind(objects.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1", len(args))
+	}
+	d, ok := args[0].(*objects.Duration)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "argument to `sleep` must be DURATION, got %s", typeOf(args[0]))
+	}
+	time.Sleep(d.Value)
+	return &objects.Null{}
+}
+
+func layoutOf(name string) string {
+	if layout, ok := layouts[name]; ok {
+		return layout
+	}
+	return name
+}
+
+func zone(name string) (*time.Location, *objects.Error) {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, objects.NewErrorKind(objects.VALUE_ERROR, "unknown time zone %q", name)
+	}
+	return location, nil
+}
+
+// the duration in ISO 8601, like PT1H30M or PT0.25S
+func isoDuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var out string
+	// the magnitude as unsigned so the smallest duration can be negated
+	magnitude := uint64(d)
+	if d < 0 {
+		out += "-"
+		magnitude = uint64(-(d + 1)) + 1
+	}
+	out += "PT"
+	hours := magnitude / uint64(time.Hour)
+	magnitude -= hours * uint64(time.Hour)
+	minutes := magnitude / uint64(time.Minute)
+	magnitude -= minutes * uint64(time.Minute)
+	if hours > 0 {
+		out += fmt.Sprintf("%dH", hours)
+	}
+	if minutes > 0 {
+		out += fmt.Sprintf("%dM", minutes)
+	}
+	if magnitude > 0 {
+		seconds := fmt.Sprintf("%d.%09d", magnitude/uint64(time.Second), magnitude%uint64(time.Second))
+		out += strings.TrimSuffix(strings.TrimRight(seconds, "0"), ".") + "S"
+	}
+	return out
+}
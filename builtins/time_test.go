@@ -0,0 +1,37 @@
+package builtins_test
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+const march = "var t = parse_time(\"2024-03-10T06:30:00Z\")\n"
+
+func TestTime(t *testing.T) {
+	runTests(t, []builtinTest{
+		{input: march + "t", want: "2024-03-10T06:30:00Z"},
+		{input: march + "t.year", want: "2024"},
+		{input: march + "t.month", want: "3"},
+		{input: march + "t.minute", want: "30"},
+		{input: march + "t.weekday", want: `"Sunday"`},
+		{input: march + "format_time(t, \"date\")", want: `"2024-03-10"`},
+		{input: march + "format_time(t + duration(\"1h30m\"))", want: `"2024-03-10T08:00:00Z"`},
+		{input: march + "var later = t + duration(\"1h30m\")\nlater - t", want: "1h30m0s"},
+		{input: march + "t + duration(\"1s\") > t", want: "true"},
+		{input: march + "t - duration(\"1s\") < t", want: "true"},
+		{input: "duration(90, \"m\")", want: "1h30m0s"},
+		{input: "duration(\"1h\") / 4", want: "15m0s"},
+		{input: "2 * duration(\"1s\") + duration(\"500ms\")", want: "2.5s"},
+		{input: "duration(\"90s\").minutes", want: "1.5"},
+		{input: march + "in_zone(t, \"America/New_York\").hour", want: "1"},
+		{input: march + "in_zone(t, \"America/New_York\") - t", want: "0s"},
+		{input: "format_time(parse_time(\"10/03/2024 09:15\", \"02/01/2006 15:04\", \"Europe/Paris\"))", want: `"2024-03-10T09:15:00+01:00"`},
+		{input: march + "json_stringify([t, duration(\"1h30m\"), -duration(\"250ms\")])", want: `"[\"2024-03-10T06:30:00Z\",\"PT1H30M\",\"-PT0.25S\"]"`},
+		{input: "var start = now()\nsleep(duration(\"1ms\"))\nnow() - start >= duration(\"1ms\")", want: "true"},
+		{input: march + "in_zone(t, \"Mars/Olympus\")", kind: objects.VALUE_ERROR, want: "unknown time zone"},
+		{input: "parse_time(\"10 March\")", kind: objects.VALUE_ERROR, want: ""},
+		{input: "duration(\"soon\")", kind: objects.VALUE_ERROR, want: ""},
+		{input: "now() + 1", kind: objects.TYPE_ERROR, want: "type mismatch: TIME + INTEGER"},
+	})
+}
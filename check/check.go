@@ -0,0 +1,583 @@
+package check
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+const (
+	ERROR   = "error"
+	WARNING = "warning"
+)
+
+type Finding struct {
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", f.Line, f.Column, f.Severity, f.Message)
+}
+
+const (
+	VARIABLE = iota
+	PARAMETER
+	FUNCTION
+	STRUCT
+	ENUM
+)
+
+// a declared name and every place it is referred to
+type Symbol struct {
+	Name       string
+	Kind       int
+	Token      token.Token
+	Function   *ast.FunctionLiteral
+	Struct     *ast.StructStatement
+	Enum       *ast.EnumStatement
+	References []token.Token
+	// the annotated type, empty when the declaration has none
+	Type string
+	// lines of the program or function body the symbol is visible in
+	ScopeStart int
+	ScopeEnd   int
+
+	used bool
+	// set once the walk has passed the declaration
+	declared bool
+	// the type of the value last assigned to an unannotated variable
+	inferred string
+}
+
+type Analysis struct {
+	Findings []Finding
+	Symbols  []*Symbol
+}
+
+type scope struct {
+	symbols map[string]*Symbol
+	order   []*Symbol
+	outer   *scope
+	// the function whose body this is, nil for the program and match arms
+	fn    *ast.FunctionLiteral
+	start int
+	end   int
+}
+
+type checker struct {
+	findings []Finding
+	symbols  []*Symbol
+}
+
+// checks source code, returning the lexer or parser errors if it is invalid
+func Source(src string) ([]Finding, error) {
+	program, err := parser.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return Check(program), nil
+}
+
+// reports problems in the program ordered by position
+func Check(program *ast.Program) []Finding {
+	return Analyze(program).Findings
+}
+
+// resolves every name in the program to its declaration and reports problems
+func Analyze(program *ast.Program) *Analysis {
+	c := &checker{}
+	c.scope(program.Statements, nil, nil, 1, math.MaxInt)
+
+	sort.SliceStable(c.findings, func(i, j int) bool {
+		if c.findings[i].Line != c.findings[j].Line {
+			return c.findings[i].Line < c.findings[j].Line
+		}
+		return c.findings[i].Column < c.findings[j].Column
+	})
+	return &Analysis{Findings: c.findings, Symbols: c.symbols}
+}
+
+func (c *checker) report(line, column int, severity, format string, a ...interface{}) {
+	c.findings = append(c.findings, Finding{
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// checks the body of the program or a function, blocks share the scope of
+// the function they are in
+func (c *checker) scope(statements []ast.AstStatement, outer *scope, fn *ast.FunctionLiteral, start, end int) {
+	s := &scope{symbols: make(map[string]*Symbol), outer: outer, fn: fn, start: start, end: end}
+	var params []*Symbol
+	if fn != nil {
+		for _, param := range fn.Parameters {
+			sym := &Symbol{Name: param.Ident.Ident, Kind: PARAMETER, Token: param.Ident.Token, declared: true}
+			params = append(params, sym)
+			c.declare(s, sym)
+		}
+	}
+	c.hoist(s, statements)
+	// annotations can name structs and enums declared in the body
+	for i, sym := range params {
+		param := fn.Parameters[i]
+		sym.Type = c.typeName(s, param.Type)
+		if param.Variadic {
+			sym.Type = objects.ARRAY_TYPE
+		}
+		if param.Default != nil {
+			tok := firstToken(param.Default)
+			if tok == nil {
+				tok = &param.Ident.Token
+			}
+			c.assignType(sym, tok, c.expression(s, param.Default), true)
+		}
+	}
+	if fn != nil {
+		c.typeName(s, fn.ReturnType)
+	}
+
+	c.statements(s, statements)
+	c.unused(s)
+}
+
+func (c *checker) unused(s *scope) {
+	for _, sym := range s.order {
+		if sym.used || sym.Kind == FUNCTION || sym.Kind == STRUCT || sym.Kind == ENUM || strings.HasPrefix(sym.Name, "_") {
+			continue
+		}
+		if sym.Kind == PARAMETER {
+			c.report(sym.Token.Line, sym.Token.Column, WARNING, "unused parameter %s", sym.Name)
+		} else {
+			c.report(sym.Token.Line, sym.Token.Column, WARNING, "unused variable %s", sym.Name)
+		}
+	}
+}
+
+func (c *checker) declare(s *scope, sym *Symbol) {
+	if previous, ok := s.symbols[sym.Name]; ok {
+		if previous.Kind == FUNCTION && sym.Kind == FUNCTION {
+			c.report(sym.Token.Line, sym.Token.Column, ERROR, "function %s redeclared, previous declaration at %d:%d",
+				sym.Name, previous.Token.Line, previous.Token.Column)
+		}
+		if previous.Kind == STRUCT && sym.Kind == STRUCT {
+			c.report(sym.Token.Line, sym.Token.Column, ERROR, "struct %s redeclared, previous declaration at %d:%d",
+				sym.Name, previous.Token.Line, previous.Token.Column)
+		}
+		previous.References = append(previous.References, sym.Token)
+		return
+	}
+	sym.ScopeStart = s.start
+	sym.ScopeEnd = s.end
+	s.symbols[sym.Name] = sym
+	s.order = append(s.order, sym)
+	c.symbols = append(c.symbols, sym)
+}
+
+// declares every variable and function of the scope before walking it, so
+// uses ahead of a declaration can be told apart from undefined names
+func (c *checker) hoist(s *scope, statements []ast.AstStatement) {
+	for _, statement := range statements {
+		switch node := statement.(type) {
+		case *ast.VarStatement:
+			c.declare(s, &Symbol{Name: node.Identifer.Ident, Kind: VARIABLE, Token: node.Identifer.Token})
+		case *ast.ExpressionStatement:
+			if fn, ok := node.Expression.(*ast.FunctionLiteral); ok && fn.Name != "" {
+				c.declare(s, &Symbol{Name: fn.Name, Kind: FUNCTION, Token: fn.Token, Function: fn})
+			}
+		case *ast.StructStatement:
+			c.declare(s, &Symbol{Name: node.Name, Kind: STRUCT, Token: node.Token, Struct: node})
+		case *ast.EnumStatement:
+			c.declare(s, &Symbol{Name: node.Name, Kind: ENUM, Token: node.Token, Enum: node})
+		case *ast.IfStatement:
+			c.hoist(s, node.If.Consequence.Statements)
+			for _, elif := range node.Elif {
+				c.hoist(s, elif.Consequence.Statements)
+			}
+			c.hoist(s, node.Else.Statements)
+		case *ast.ForStatement:
+			c.hoist(s, []ast.AstStatement{node.Initializer})
+			if body, ok := node.Body.(*ast.BlockStatement); ok {
+				c.hoist(s, body.Statements)
+			}
+		case *ast.WhileStatement:
+			if body, ok := node.Body.(*ast.BlockStatement); ok {
+				c.hoist(s, body.Statements)
+			}
+		case *ast.TryStatement:
+			c.hoist(s, node.Body.Statements)
+			if node.CatchIdent != nil {
+				// a caught error is often ignored, so it is never reported unused
+				c.declare(s, &Symbol{Name: node.CatchIdent.Ident, Kind: VARIABLE, Token: node.CatchIdent.Token, used: true})
+			}
+			if node.Catch != nil {
+				c.hoist(s, node.Catch.Statements)
+			}
+			if node.Finally != nil {
+				c.hoist(s, node.Finally.Statements)
+			}
+		}
+	}
+}
+
+// finds the symbol for a name, declarations in enclosing scopes count
+// wherever they are as the function body runs after they have been made
+func (c *checker) resolve(s *scope, name string) (*Symbol, bool) {
+	if sym, ok := s.symbols[name]; ok {
+		return sym, sym.declared
+	}
+	for outer := s.outer; outer != nil; outer = outer.outer {
+		if sym, ok := outer.symbols[name]; ok {
+			return sym, true
+		}
+	}
+	return nil, false
+}
+
+func (c *checker) statements(s *scope, statements []ast.AstStatement) {
+	returned := false
+	for _, statement := range statements {
+		if returned {
+			span := statement.Lines()
+			c.report(span.StartLine, span.StartColumn, WARNING, "unreachable code")
+			returned = false
+		}
+		c.statement(s, statement)
+		switch statement.(type) {
+		case *ast.ReturnStatement, *ast.ThrowStatement:
+			returned = true
+		}
+	}
+}
+
+func (c *checker) statement(s *scope, statement ast.AstStatement) {
+	switch node := statement.(type) {
+	case *ast.VarStatement:
+		got := c.expression(s, node.Value)
+		if sym, ok := s.symbols[node.Identifer.Ident]; ok {
+			sym.declared = true
+			if node.Type != nil {
+				sym.Type = c.typeName(s, node.Type)
+			}
+			c.assignType(sym, &node.Identifer.Token, got, true)
+		}
+	case *ast.ReturnStatement:
+		c.returnType(s, node, c.expression(s, node.ReturnValue))
+	case *ast.ExpressionStatement:
+		if fn, ok := node.Expression.(*ast.FunctionLiteral); ok {
+			if sym, ok := s.symbols[fn.Name]; ok {
+				sym.declared = true
+			}
+			c.scope(fn.Body.Statements, s, fn, fn.Body.StartLine, fn.Body.EndLine)
+			return
+		}
+		c.expression(s, node.Expression)
+	case *ast.BlockStatement:
+		c.statements(s, node.Statements)
+	case *ast.IfStatement:
+		c.condition(s, node.If.Condition)
+		c.statements(s, node.If.Consequence.Statements)
+		for _, elif := range node.Elif {
+			c.condition(s, elif.Condition)
+			c.statements(s, elif.Consequence.Statements)
+		}
+		c.statements(s, node.Else.Statements)
+	case *ast.ForStatement:
+		c.statement(s, node.Initializer)
+		c.condition(s, node.Conditional)
+		c.statement(s, node.Body)
+		c.statement(s, node.Increment)
+	case *ast.WhileStatement:
+		c.condition(s, node.Condition)
+		c.statement(s, node.Body)
+	case *ast.TryStatement:
+		c.statements(s, node.Body.Statements)
+		if node.CatchIdent != nil {
+			if sym, ok := s.symbols[node.CatchIdent.Ident]; ok {
+				sym.declared = true
+				sym.inferred = objects.ERROR_TYPE
+			}
+		}
+		if node.Catch != nil {
+			c.statements(s, node.Catch.Statements)
+		}
+		if node.Finally != nil {
+			c.statements(s, node.Finally.Statements)
+		}
+	case *ast.ThrowStatement:
+		c.expression(s, node.Value)
+	case *ast.StructStatement:
+		if sym, ok := s.symbols[node.Name]; ok {
+			sym.declared = true
+		}
+		for _, method := range node.Methods {
+			c.scope(method.Body.Statements, s, method, method.Body.StartLine, method.Body.EndLine)
+		}
+	case *ast.EnumStatement:
+		if sym, ok := s.symbols[node.Name]; ok {
+			sym.declared = true
+		}
+	}
+}
+
+// reports whether the name is a builtin function or module
+func builtin(name string) bool {
+	_, isFunction := builtins.BuiltIns[name]
+	_, isModule := builtins.Modules[name]
+	return isFunction || isModule
+}
+
+// reports members a builtin module does not have
+func (c *checker) moduleMember(s *scope, node *ast.MemberExpression) {
+	module := c.module(s, node)
+	if module == nil {
+		return
+	}
+	if _, ok := module.Members[node.Member.Ident]; !ok {
+		c.report(node.Member.Token.Line, node.Member.Token.Column, ERROR, "module %s has no member %s", module.Name, node.Member.Ident)
+	}
+}
+
+// the builtin module on the left of the member, nil when it is not one or a
+// declaration hides it
+func (c *checker) module(s *scope, node *ast.MemberExpression) *objects.Module {
+	ident, ok := node.Left.(*ast.IdentiferLiteral)
+	if !ok {
+		return nil
+	}
+	if sym, _ := c.resolve(s, ident.Ident); sym != nil {
+		return nil
+	}
+	return builtins.Modules[ident.Ident]
+}
+
+func (c *checker) identifier(s *scope, ident *ast.IdentiferLiteral, use bool) *Symbol {
+	sym, declared := c.resolve(s, ident.Ident)
+	switch {
+	case sym != nil && !declared:
+		c.report(ident.Token.Line, ident.Token.Column, ERROR, "%s used before its declaration", ident.Ident)
+	case sym == nil:
+		if !builtin(ident.Ident) {
+			c.report(ident.Token.Line, ident.Token.Column, ERROR, "undefined: %s", ident.Ident)
+		}
+		return nil
+	}
+	sym.References = append(sym.References, ident.Token)
+	if use {
+		sym.used = true
+	}
+	return sym
+}
+
+// walks the expression and infers its type, empty when it is not known
+func (c *checker) expression(s *scope, expression ast.AstExpression) string {
+	switch node := expression.(type) {
+	case *ast.IntegerLiteral:
+		return objects.INT_TYPE
+	case *ast.FloatLiteral:
+		return objects.FLOAT_TYPE
+	case *ast.StringLiteral:
+		return objects.STRING_TYPE
+	case *ast.BooleanLiteral:
+		return objects.BOOL_TYPE
+	case *ast.IdentiferLiteral:
+		if sym := c.identifier(s, node, true); sym != nil {
+			return c.symbolType(s, sym)
+		}
+	case *ast.InfixExpression:
+		return c.infix(node, c.expression(s, node.Left), c.expression(s, node.Right))
+	case *ast.PrefixExpression:
+		return prefix(node, c.expression(s, node.Expression))
+	case *ast.CallExpression:
+		return c.call(s, node)
+	case *ast.IndexExpression:
+		c.expression(s, node.Left)
+		c.expression(s, node.Index)
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			c.expression(s, element)
+		}
+		return objects.ARRAY_TYPE
+	case *ast.HashLiteral:
+		for _, key := range node.Keys {
+			c.expression(s, key)
+			c.expression(s, node.Pairs[key])
+		}
+		return objects.HASH_TYPE
+	case *ast.Reassignment:
+		got := c.expression(s, node.Value)
+		if sym := c.identifier(s, &node.Ident, false); sym != nil {
+			c.assignType(sym, &node.Ident.Token, got, false)
+		}
+	case *ast.FunctionLiteral:
+		c.scope(node.Body.Statements, s, node, node.Body.StartLine, node.Body.EndLine)
+		return objects.FUNC_TYPE
+	case *ast.MemberExpression:
+		c.expression(s, node.Left)
+		c.moduleMember(s, node)
+		return c.variantType(s, node)
+	case *ast.FieldAssignment:
+		c.expression(s, node.Value)
+		c.expression(s, node.Target.Left)
+	case *ast.NamedArgument:
+		return c.expression(s, node.Value)
+	case *ast.SpreadExpression:
+		c.expression(s, node.Value)
+	case *ast.MatchExpression:
+		c.expression(s, node.Subject)
+		for _, arm := range node.Arms {
+			// the names an arm binds are only visible in it
+			armScope := &scope{symbols: make(map[string]*Symbol), outer: s, start: s.start, end: s.end}
+			c.pattern(armScope, arm.Pattern)
+			c.expression(armScope, arm.Guard)
+			c.expression(armScope, arm.Body)
+			c.unused(armScope)
+		}
+	}
+	return ""
+}
+
+func (c *checker) pattern(s *scope, pattern ast.Pattern) {
+	switch node := pattern.(type) {
+	case *ast.BindingPattern:
+		c.declare(s, &Symbol{Name: node.Ident.Ident, Kind: VARIABLE, Token: node.Ident.Token, declared: true})
+	case *ast.LiteralPattern:
+		c.expression(s, node.Value)
+	case *ast.RangePattern:
+		c.expression(s, node.Low)
+		c.expression(s, node.High)
+	case *ast.VariantPattern:
+		c.identifier(s, &node.Enum, true)
+		for _, field := range node.Fields {
+			c.pattern(s, field)
+		}
+	case *ast.ArrayPattern:
+		for _, element := range node.Elements {
+			c.pattern(s, element)
+		}
+		if node.Rest != nil {
+			c.declare(s, &Symbol{Name: node.Rest.Ident, Kind: VARIABLE, Token: node.Rest.Token, declared: true})
+		}
+	case *ast.HashPattern:
+		for _, value := range node.Values {
+			c.pattern(s, value)
+		}
+	}
+}
+
+// checks the call and returns the type of its result
+func (c *checker) call(s *scope, node *ast.CallExpression) string {
+	var types []string
+	for _, arg := range node.Arguments {
+		types = append(types, c.expression(s, arg))
+	}
+
+	ident, ok := node.Function.(*ast.IdentiferLiteral)
+	if !ok {
+		c.expression(s, node.Function)
+		if member, ok := node.Function.(*ast.MemberExpression); ok {
+			if module := c.module(s, member); module != nil {
+				if fn, ok := module.Members[member.Member.Ident].(*objects.Builtin); ok {
+					return c.builtinCall(member.Member.Token, module.Name+"."+member.Member.Ident, fn, argCount(node.Arguments))
+				}
+			}
+			return c.variantType(s, member)
+		}
+		return ""
+	}
+
+	got := len(node.Arguments)
+	for _, arg := range node.Arguments {
+		switch arg := arg.(type) {
+		case *ast.SpreadExpression:
+			// how many arguments a spread passes is only known when it runs
+			got = -1
+		case *ast.NamedArgument:
+			if sym, _ := c.resolve(s, ident.Ident); sym == nil || sym.Kind != FUNCTION && sym.Kind != STRUCT {
+				c.report(arg.Name.Token.Line, arg.Name.Token.Column, ERROR, "%s takes no named arguments", ident.Ident)
+				return ""
+			}
+		}
+	}
+
+	sym := c.identifier(s, ident, true)
+	if sym != nil {
+		if sym.Kind == FUNCTION {
+			c.arity(ident, objects.FunctionSignature(ident.Ident, sym.Function.Parameters), node.Arguments)
+		}
+		if sym.Kind == STRUCT {
+			signature := &objects.Signature{Name: ident.Ident}
+			for _, field := range sym.Struct.Fields {
+				signature.Params = append(signature.Params, field.Ident)
+			}
+			c.arity(ident, signature, node.Arguments)
+		}
+		return c.callType(s, sym, node, types)
+	}
+	fn, ok := builtins.BuiltIns[ident.Ident]
+	if !ok {
+		return ""
+	}
+	return c.builtinCall(ident.Token, ident.Ident, &fn, got)
+}
+
+// checks the number of arguments passed to a builtin against the arity it
+// was registered with, -1 when a spread makes it unknown, and returns the
+// type of its result
+func (c *checker) builtinCall(tok token.Token, name string, fn *objects.Builtin, got int) string {
+	if got < 0 {
+		return fn.Returns
+	}
+	if message := objects.CountError(name, fn.MinArgs, fn.MaxArgs, got); message != "" {
+		c.report(tok.Line, tok.Column, ERROR, "%s", message)
+	}
+	return fn.Returns
+}
+
+// the number of arguments a call passes, -1 when a spread makes it unknown
+func argCount(args []ast.AstExpression) int {
+	for _, arg := range args {
+		if _, ok := arg.(*ast.SpreadExpression); ok {
+			return -1
+		}
+	}
+	return len(args)
+}
+
+// checks the arguments of a call cover the parameters of a declared function
+// or struct, with the same rules they are bound by when it runs
+func (c *checker) arity(ident *ast.IdentiferLiteral, signature *objects.Signature, args []ast.AstExpression) {
+	positional := 0
+	var named []*ast.NamedArgument
+	var names []string
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case *ast.SpreadExpression:
+			return
+		case *ast.NamedArgument:
+			named = append(named, arg)
+			names = append(names, arg.Name.Ident)
+		default:
+			positional++
+		}
+	}
+	if _, err := signature.Bind(positional, names); err != nil {
+		tok := ident.Token
+		if err.Named >= 0 {
+			tok = named[err.Named].Name.Token
+		}
+		c.report(tok.Line, tok.Column, ERROR, "%s", err.Message)
+	}
+}
@@ -0,0 +1,75 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"clean", "var x = 1\nprintln(x)\n", nil},
+		{"undefined variable", "println(y)\n", []string{"1:9: error: undefined: y"}},
+		{"undefined reassignment", "y = 1\n", []string{"1:1: error: undefined: y"}},
+		{"used before declaration", "println(x)\nvar x = 1\n", []string{"1:9: error: x used before its declaration"}},
+		{"unused variable", "var x = 1\n", []string{"1:5: warning: unused variable x"}},
+		{"assignment is not a use", "var x = 1\nx = 2\n", []string{"1:5: warning: unused variable x"}},
+		{"ignored variable", "var _x = 1\n", nil},
+		{"unused parameter", "func f(a, b) {\n    return a\n}\n", []string{"1:11: warning: unused parameter b"}},
+		{"block shares scope", "if true {\n    var x = 1\n}\nprintln(x)\n", nil},
+		{"outer declared later", "func f() {\n    return g()\n}\nfunc g() {\n    return 1\n}\n", nil},
+		{"recursion", "func f(n) {\n    return f(n)\n}\n", nil},
+		{"function arity", "func f(a) {\n    return a\n}\nf(1, 2)\n", []string{"4:1: error: f expects 1 argument, got 2"}},
+		{"builtin arity", "len()\nprintln(1, 2, 3)\n", []string{"1:1: error: len expects 1 argument, got 0"}},
+		{"builtin range", "rand(1, 2, 3)\n", []string{"1:1: error: rand expects at most 2 arguments, got 3"}},
+		{"predicate arity", "is_int(1, 2)\n", []string{"1:1: error: is_int expects 1 argument, got 2"}},
+		{"module arity", "math.sqrt()\nprocess.cwd(1)\nmath.max(1, 2, 3)\nfs.read_file(...[\"a\"])\n",
+			[]string{"1:6: error: math.sqrt expects 1 argument, got 0", "2:9: error: process.cwd expects 0 arguments, got 1"}},
+		{"module result type", "println(math.floor(1.5) + \"a\")\n", []string{"1:25: error: type mismatch: int + string"}},
+		{"mixed numbers", "var r = 2\nprintln(2.5 * r * r + \"a\")\nprintln(1 & 2.0)\n",
+			[]string{"2:21: error: type mismatch: float + string", "3:11: error: type mismatch: int & float"}},
+		{"shadowed builtin", "func len(a, b) {\n    return a + b\n}\nlen(1, 2)\n", nil},
+		{"unreachable", "func f() {\n    return 1\n    println(2)\n    println(3)\n}\n", []string{"3:5: warning: unreachable code"}},
+		{"caught error", "try {\n    throw \"x\"\n    println(1)\n} catch (e) {\n    println(y)\n}\n",
+			[]string{"3:5: warning: unreachable code", "5:13: error: undefined: y"}},
+		{"struct", "struct P {\n    x\n    func get(self, n) {\n        return self.x\n    }\n}\nvar p = P(1, 2)\np.x = q\n",
+			[]string{"3:20: warning: unused parameter n", "7:9: error: P expects 1 argument, got 2", "8:7: error: undefined: q"}},
+		{"match", "enum E {\n    A(x)\n}\nvar v = match E.A(1) {\n    E.A(x) => y,\n    [a, ...rest] => rest,\n    F.B => 0\n}\nprintln(v)\nprintln(x)\n",
+			[]string{"5:9: warning: unused variable x", "5:15: error: undefined: y", "6:6: warning: unused variable a", "7:5: error: undefined: F", "10:9: error: undefined: x"}},
+		{"duplicate function", "func f() {}\nfunc f() {}\n", []string{"2:6: error: function f redeclared, previous declaration at 1:6"}},
+		{"loop variable", "for var i = 0; i < 3; i += 1 {\n    println(i)\n}\n", nil},
+		{"annotations", "struct P {\n    x\n}\nfunc f(a: int, _p: P) -> string {\n    return a + 1\n}\nvar s: string = f(\"1\", P(1))\nvar n: Q = 1\nprintln(s, n)\n",
+			[]string{"5:5: error: f must return string, got int", "7:19: error: argument a of f must be int, got string", "8:8: error: unknown type Q"}},
+		{"inferred types", "var s = \"a\"\nvar n = len(s) + 1\nprintln(s + 1)\nif n {\n    s = 1\n}\nprintln(s + 1)\n",
+			[]string{"3:11: error: type mismatch: string + int", "4:4: error: condition must be bool, got int"}},
+		{"annotated assignment", "var n: int = 1.5\nn = \"x\"\nvar a: any = n\nprintln(a)\n",
+			[]string{"1:5: error: n must be int, got float", "2:1: error: n must be int, got string"}},
+		{"defaults", "func f(a, b = 1, ...rest) {\n    return [a, b, rest]\n}\nf()\nf(1)\nf(1, 2, 3)\nf(b = 2)\nf(1, c = 2)\nf(...[1])\n",
+			[]string{"4:1: error: f expects at least 1 argument, got 0", "7:1: error: f is missing argument a", "8:6: error: f has no parameter c"}},
+		{"named arguments", "struct P {\n    x, y\n}\nfunc f(n: int) {\n    return n\n}\nprintln(P(y = 1, x = 2), f(n = \"1\"))\nprintln(x = 1)\n",
+			[]string{"7:28: error: argument n of f must be int, got string", "8:9: error: println takes no named arguments"}},
+		{"closures", "func adder(n) {\n    return (x, y) => x + n\n}\nvar f = func(s: string) -> int {\n    return s\n}\nprintln(adder(1)(2), f(\"a\"))\n",
+			[]string{"2:16: warning: unused parameter y", "5:5: error: function must return int, got string"}},
+		{"modules", "println(fs.read_file(\"a\"), fs.nope)\nvar m = {\"nope\": 1}\nprintln(m.nope)\n",
+			[]string{"1:31: error: module fs has no member nope"}},
+		{"outer variables are not inferred", "var x = 1\nfunc f() {\n    return x + \"!\"\n}\nx = \"a\"\nprintln(f())\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := Source(tt.input)
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			var got []string
+			for _, finding := range findings {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
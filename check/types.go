@@ -0,0 +1,291 @@
+package check
+
+import (
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+// the types the runtime reports a mismatch for when they are mixed
+var primitives = map[string]bool{
+	objects.INT_TYPE:    true,
+	objects.FLOAT_TYPE:  true,
+	objects.STRING_TYPE: true,
+	objects.BOOL_TYPE:   true,
+}
+
+// reports whether a value of type got can be used where want is expected,
+// unknown types are assumed to fit
+func compatible(want, got string) bool {
+	return want == "" || got == "" || want == objects.ANY_TYPE || got == objects.ANY_TYPE || want == got
+}
+
+// the struct or enum an annotation names
+func (c *checker) lookupType(s *scope, name string) *Symbol {
+	sym, _ := c.resolve(s, name)
+	if sym != nil && (sym.Kind == STRUCT || sym.Kind == ENUM) {
+		return sym
+	}
+	return nil
+}
+
+// resolves an annotation, empty when there is none or it names no type
+func (c *checker) typeName(s *scope, typ *ast.TypeName) string {
+	if typ == nil {
+		return ""
+	}
+	if objects.IsBuiltinType(typ.Name) {
+		return typ.Name
+	}
+	if sym := c.lookupType(s, typ.Name); sym != nil {
+		sym.References = append(sym.References, typ.Token)
+		return typ.Name
+	}
+	c.report(typ.Token.Line, typ.Token.Column, ERROR, "unknown type %s", typ.Name)
+	return ""
+}
+
+// like typeName but without reporting, for annotations already checked
+// where they were declared
+func (c *checker) annotation(s *scope, typ *ast.TypeName) string {
+	if typ == nil {
+		return ""
+	}
+	if objects.IsBuiltinType(typ.Name) || c.lookupType(s, typ.Name) != nil {
+		return typ.Name
+	}
+	return ""
+}
+
+// the type of the value a name refers to
+func (c *checker) symbolType(s *scope, sym *Symbol) string {
+	if sym.Type != "" {
+		return sym.Type
+	}
+	switch sym.Kind {
+	case FUNCTION, STRUCT:
+		return objects.FUNC_TYPE
+	case VARIABLE:
+		// a function can run after the variable has been given a value of
+		// another type, so only the scope that owns it trusts the inference
+		if local(s, sym) {
+			return sym.inferred
+		}
+	}
+	return ""
+}
+
+// reports whether the symbol belongs to the function the scope is in
+func local(s *scope, sym *Symbol) bool {
+	for ; s != nil; s = s.outer {
+		if s.symbols[sym.Name] == sym {
+			return true
+		}
+		if s.fn != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// checks a value assigned to the symbol against its annotation, without one
+// the variable keeps its type while every assignment agrees on it
+func (c *checker) assignType(sym *Symbol, tok *token.Token, got string, declaration bool) {
+	switch {
+	case sym.Type != "":
+		if !compatible(sym.Type, got) {
+			c.report(tok.Line, tok.Column, ERROR, "%s must be %s, got %s", sym.Name, sym.Type, got)
+		}
+	case declaration:
+		sym.inferred = got
+	case sym.inferred != got:
+		sym.inferred = ""
+	}
+}
+
+func (c *checker) returnType(s *scope, node *ast.ReturnStatement, got string) {
+	for ; s != nil && s.fn == nil; s = s.outer {
+	}
+	if s == nil {
+		return
+	}
+	want := c.annotation(s, s.fn.ReturnType)
+	if node.ReturnValue == nil {
+		got = objects.NULL_TYPE
+	}
+	if !compatible(want, got) {
+		name := s.fn.Name
+		if name == "" {
+			name = "function"
+		}
+		c.report(node.StartLine, node.StartColumn, ERROR, "%s must return %s, got %s", name, want, got)
+	}
+}
+
+func (c *checker) condition(s *scope, condition ast.AstExpression) {
+	got := c.expression(s, condition)
+	if tok := firstToken(condition); tok != nil && !compatible(objects.BOOL_TYPE, got) {
+		c.report(tok.Line, tok.Column, ERROR, "condition must be bool, got %s", got)
+	}
+}
+
+// an integer mixed with a float is promoted to a float, except by the
+// bitwise operators
+func promoted(node *ast.InfixExpression, left, right string) bool {
+	switch node.Operator.Type {
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LSHIFT, token.RSHIFT:
+		return false
+	}
+	return left == objects.INT_TYPE && right == objects.FLOAT_TYPE || left == objects.FLOAT_TYPE && right == objects.INT_TYPE
+}
+
+func (c *checker) infix(node *ast.InfixExpression, left, right string) string {
+	if promoted(node, left, right) {
+		left, right = objects.FLOAT_TYPE, objects.FLOAT_TYPE
+	}
+	if left != right && primitives[left] && primitives[right] {
+		c.report(node.Operator.Line, node.Operator.Column, ERROR, "type mismatch: %s %s %s", left, node.Operator.Literal, right)
+		return ""
+	}
+	switch node.Operator.Type {
+	case token.EQUAL, token.NOTEQUAL, token.LTHAN, token.GTHAN, token.LEQUAL, token.GEQUAL:
+		return objects.BOOL_TYPE
+	case token.INCREMENT, token.DECREMENT:
+		return ""
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LSHIFT, token.RSHIFT:
+		if left == objects.INT_TYPE && right == objects.INT_TYPE {
+			return objects.INT_TYPE
+		}
+		return ""
+	}
+	if left != right {
+		return ""
+	}
+	// an integer to a negative power is a float
+	if node.Operator.Type == token.POWER && left == objects.INT_TYPE {
+		return ""
+	}
+	if left == objects.INT_TYPE || left == objects.FLOAT_TYPE || left == objects.STRING_TYPE && node.Operator.Type == token.PLUS {
+		return left
+	}
+	return ""
+}
+
+func prefix(node *ast.PrefixExpression, operand string) string {
+	switch node.Prefix.Type {
+	case token.MINUS:
+		if operand == objects.INT_TYPE || operand == objects.FLOAT_TYPE {
+			return operand
+		}
+	case token.TILDE:
+		if operand == objects.INT_TYPE {
+			return operand
+		}
+	case token.BANG:
+		return objects.BOOL_TYPE
+	}
+	return ""
+}
+
+// checks the arguments of a call against the annotated parameters and
+// returns the type of its result
+func (c *checker) callType(s *scope, sym *Symbol, node *ast.CallExpression, types []string) string {
+	switch sym.Kind {
+	case FUNCTION:
+		fn := sym.Function
+		for i, arg := range node.Arguments {
+			param := argumentParameter(fn.Parameters, i, arg)
+			if param == nil {
+				break
+			}
+			want := c.annotation(s, param.Type)
+			if compatible(want, types[i]) {
+				continue
+			}
+			tok := firstToken(arg)
+			if tok == nil {
+				tok = firstToken(node.Function)
+			}
+			c.report(tok.Line, tok.Column, ERROR, "argument %s of %s must be %s, got %s",
+				param.Ident.Ident, fn.Name, want, types[i])
+		}
+		return c.annotation(s, fn.ReturnType)
+	case STRUCT:
+		return sym.Name
+	}
+	return ""
+}
+
+// the parameter the argument at index i of a call is bound to, nil when it
+// is not known
+func argumentParameter(params []ast.Parameter, i int, arg ast.AstExpression) *ast.Parameter {
+	switch arg := arg.(type) {
+	case *ast.SpreadExpression:
+		return nil
+	case *ast.NamedArgument:
+		if j := parameter(params, arg.Name.Ident); j >= 0 && !params[j].Variadic {
+			return &params[j]
+		}
+		return nil
+	}
+	if i < len(params) && !params[i].Variadic {
+		return &params[i]
+	}
+	if len(params) > 0 && params[len(params)-1].Variadic {
+		return &params[len(params)-1]
+	}
+	return nil
+}
+
+// the index of the parameter with the name, -1 when there is none
+func parameter(params []ast.Parameter, name string) int {
+	for i, param := range params {
+		if param.Ident.Ident == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// the enum of a variant reached with Shape.Circle
+func (c *checker) variantType(s *scope, node *ast.MemberExpression) string {
+	ident, ok := node.Left.(*ast.IdentiferLiteral)
+	if !ok {
+		return ""
+	}
+	if sym, _ := c.resolve(s, ident.Ident); sym != nil && sym.Kind == ENUM {
+		return sym.Name
+	}
+	return ""
+}
+
+// the first token of an expression, nil for literals that keep none
+func firstToken(node ast.AstExpression) *token.Token {
+	switch node := node.(type) {
+	case *ast.IdentiferLiteral:
+		return &node.Token
+	case *ast.IntegerLiteral:
+		return &node.Token
+	case *ast.FloatLiteral:
+		return &node.Token
+	case *ast.StringLiteral:
+		return &node.Token
+	case *ast.BooleanLiteral:
+		return &node.Token
+	case *ast.CallExpression:
+		return firstToken(node.Function)
+	case *ast.IndexExpression:
+		return firstToken(node.Left)
+	case *ast.InfixExpression:
+		return firstToken(node.Left)
+	case *ast.PrefixExpression:
+		return node.Prefix
+	case *ast.MemberExpression:
+		return firstToken(node.Left)
+	case *ast.NamedArgument:
+		return &node.Name.Token
+	case *ast.SpreadExpression:
+		return &node.Token
+	}
+	return nil
+}
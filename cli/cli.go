@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/lexer"
+	"github.com/EVFUBS/AlphaLang/lineeditor"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+// exit codes
+const (
+	EXIT_OK      = 0
+	EXIT_FAILURE = 1 // runtime errors, unformatted files, failed checks and tests
+	EXIT_USAGE   = 2
+	EXIT_LEX     = 3
+	EXIT_PARSE   = 4
+)
+
+type Cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	usage string
+	help  string
+	run   func(c *Cli, args []string) int
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"run": {
+			usage: "run [-allow list] [-seed n] [-e code] [file.al | -] [args...]",
+			help:  "run a script, reading from stdin when no file is given",
+			run:   (*Cli).runCommand,
+		},
+		"repl": {
+			usage: "repl [-allow list] [-seed n]",
+			help:  "start an interactive session",
+			run:   (*Cli).replCommand,
+		},
+		"debug": {
+			usage: "debug [-dap] [file.al] [args...]",
+			help:  "debug a script in the terminal or serve the debug adapter protocol",
+			run:   (*Cli).debugCommand,
+		},
+		"fmt": {
+			usage: "fmt [-check] [-write] [files or dirs...]",
+			help:  "format source files, reading from stdin when none are given",
+			run:   (*Cli).fmtCommand,
+		},
+		"check": {
+			usage: "check [files or dirs...]",
+			help:  "report problems found without running the code",
+			run:   (*Cli).checkCommand,
+		},
+		"tokens": {
+			usage: "tokens [-e code] [file.al | -]",
+			help:  "print the tokens of a script",
+			run:   (*Cli).tokensCommand,
+		},
+		"ast": {
+			usage: "ast [-e code] [file.al | -]",
+			help:  "print the parsed ast of a script",
+			run:   (*Cli).astCommand,
+		},
+		"lsp": {
+			usage: "lsp",
+			help:  "start a language server on stdin and stdout",
+			run:   (*Cli).lspCommand,
+		},
+		"test": {
+			usage: "test [-allow list] [-seed n] [-run regexp] [-v] [-junit file] [files or dirs...]",
+			help:  "run the test_ functions of _test.al files",
+			run:   (*Cli).testCommand,
+		},
+	}
+}
+
+// runs the alpha command line with args excluding the program name and
+// returns the exit code
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &Cli{stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		return c.replCommand(nil)
+	}
+
+	name := args[0]
+	switch {
+	case name == "-h" || name == "-help" || name == "--help" || name == "help":
+		c.usage(stdout)
+		return EXIT_OK
+	case name == "-e" || name == "-allow" || name == "-seed" || strings.HasSuffix(name, ".al"):
+		return c.runCommand(args)
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "alpha: unknown command %q\n", name)
+		c.usage(stderr)
+		return EXIT_USAGE
+	}
+	return cmd.run(c, args[1:])
+}
+
+func (c *Cli) usage(out io.Writer) {
+	fmt.Fprintln(out, "usage: alpha <command> [arguments]")
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "commands:")
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-40s %s\n", commands[name].usage, commands[name].help)
+	}
+}
+
+func (c *Cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("alpha "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: alpha %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parses the comma separated capabilities given to -allow, all gives every
+// capability
+func capabilities(list string) (map[string]bool, error) {
+	allowed := make(map[string]bool)
+	if list == "" {
+		return allowed, nil
+	}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "all":
+			for _, capability := range objects.Capabilities {
+				allowed[capability] = true
+			}
+		case known(name):
+			allowed[name] = true
+		default:
+			return nil, fmt.Errorf("unknown capability %q, want one of %s or all", name, strings.Join(objects.Capabilities, ", "))
+		}
+	}
+	return allowed, nil
+}
+
+// the -seed flag, nil when it was left out and the generator is seeded from
+// the clock
+func seed(fs *flag.FlagSet, n *int64) *int64 {
+	var given *int64
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			given = n
+		}
+	})
+	return given
+}
+
+func known(capability string) bool {
+	for _, name := range objects.Capabilities {
+		if name == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// source of a script, either inline code, a file or stdin
+type source struct {
+	name string
+	code string
+}
+
+// reads the source named by the flag set, returning the remaining arguments
+func (c *Cli) readSource(fs *flag.FlagSet, inline string) (*source, []string, error) {
+	if inline != "" {
+		return &source{name: "<inline>", code: inline}, fs.Args(), nil
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "-" {
+		if file, ok := c.stdin.(*os.File); ok && lineeditor.IsTerminal(file.Fd()) && fs.NArg() == 0 {
+			return nil, nil, fmt.Errorf("no script given")
+		}
+		code, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return nil, nil, err
+		}
+		var rest []string
+		if fs.NArg() > 0 {
+			rest = fs.Args()[1:]
+		}
+		return &source{name: "<stdin>", code: string(code)}, rest, nil
+	}
+
+	code, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &source{name: fs.Arg(0), code: string(code)}, fs.Args()[1:], nil
+}
+
+// expands directories into the .al files they contain
+func expandPaths(paths []string, suffix string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, suffix) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// lexes and parses the source, reporting errors to stderr, a non zero exit
+// code is returned when the source is invalid
+func (c *Cli) parse(src *source) (*ast.Program, int) {
+	l := lexer.New(src.code)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+	if len(l.Errors()) > 0 {
+		for _, err := range l.Errors() {
+			fmt.Fprintf(c.stderr, "%s: %s\n", src.name, err)
+		}
+		return nil, EXIT_LEX
+	}
+
+	p := parser.New(lexer.New(src.code))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintf(c.stderr, "%s: %s\n", src.name, err)
+		}
+		return nil, EXIT_PARSE
+	}
+	for _, warning := range p.Warnings() {
+		fmt.Fprintf(c.stderr, "%s: %d:%d: warning: %s\n", src.name, warning.Line, warning.Column, warning.Message)
+	}
+	return program, EXIT_OK
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.al")
+	os.WriteFile(script, []byte("var x = 1\nvar y = x + 1\n"), 0644)
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"run file", []string{"run", script}, "", EXIT_OK, "", ""},
+		{"run file shorthand", []string{script}, "", EXIT_OK, "", ""},
+		{"run inline", []string{"run", "-e", "var x = 1"}, "", EXIT_OK, "", ""},
+		{"inline shorthand", []string{"-e", "var x = 1"}, "", EXIT_OK, "", ""},
+		{"run stdin", []string{"run"}, "var x = 1", EXIT_OK, "", ""},
+		{"run stdin dash", []string{"run", "-", "a"}, "var x = args()", EXIT_OK, "", ""},
+		{"missing file", []string{"run", filepath.Join(dir, "missing.al")}, "", EXIT_USAGE, "", "no such file"},
+		{"lex error", []string{"run", "-e", "var x = 1 @ 2"}, "", EXIT_LEX, "", `illegal character "@"`},
+		{"unterminated string", []string{"run", "-e", `var x = "abc`}, "", EXIT_LEX, "", "unterminated string"},
+		{"parse error", []string{"run", "-e", "var = 1"}, "", EXIT_PARSE, "", "Expected identifier"},
+		{"runtime error", []string{"run", "-e", `var x = 1 + "a"`}, "", EXIT_FAILURE, "", "type mismatch: INTEGER + STRING"},
+		{"args", []string{"run", "-e", `var x = args()[1] + 1`, "a", "b"}, "", EXIT_FAILURE, "", "type mismatch: STRING + INTEGER"},
+		{"output", []string{"run", "-e", "println(args())\nprint(1, 2)", "a", "b"}, "", EXIT_OK, "[a, b]\n12", ""},
+		{"unknown field", []string{"run", "-e", "struct P { x }\nprintln(P(1).y)"}, "", EXIT_FAILURE, "", "<inline>:2:14: NameError: P has no field y"},
+		{"argument type", []string{"run", "-e", "func f(n: int) {\n    return n\n}\nf(\"1\")"}, "", EXIT_FAILURE, "",
+			"<inline>:4:1: TypeError: argument n of f must be int, got string"},
+		{"missing argument", []string{"run", "-e", "func f(a, b) {\n    return a\n}\nf(1)"}, "", EXIT_FAILURE, "",
+			"<inline>:4:1: ArgumentError: f expects 2 arguments, got 1"},
+		{"fs not allowed", []string{"run", "-e", "fs.read_file(\"x\")"}, "", EXIT_FAILURE, "",
+			"<inline>:1:4: PermissionError: fs.read_file needs the fs capability, run with -allow fs"},
+		{"unknown capability", []string{"run", "-allow", "disk", "-e", "1"}, "", EXIT_USAGE, "", `unknown capability "disk"`},
+		{"csv file not allowed", []string{"run", "-e", "csv_read(\"people.csv\")"}, "", EXIT_FAILURE, "",
+			"PermissionError: csv_read of a file needs the fs capability, run with -allow fs"},
+		{"time mismatch", []string{"run", "-e", "now() + 1"}, "", EXIT_FAILURE, "", "<inline>:1:7: TypeError: type mismatch: TIME + INTEGER"},
+		{"input", []string{"run", "-e", "println(input(\"name? \"))\nprintln(input(\"age? \"))"}, "ann\n 30 \n", EXIT_OK, "name? ann\nage? 30\n", ""},
+		{"input at end", []string{"run", "-e", "input(\"name? \")"}, "", EXIT_FAILURE, "name? ", "IOError: input: EOF"},
+		{"seed", []string{"run", "-seed", "42", "-e", "assert_eq(rand(1, 1000000), 278676)"}, "", EXIT_OK, "", ""},
+		{"process exit", []string{"run", "-allow", "process", "-e", "process.exit(4)"}, "", 4, "", ""},
+		{"process stream", []string{"run", "-allow", "process", "-e", "var r = process.exec(\"/bin/sh\", [\"-c\", \"echo out; echo err >&2\"], {\"stream\": true})\nassert_eq(r[\"stdout\"], \"out\n\")"},
+			"", EXIT_OK, "out\n", "err\n"},
+		{"process not allowed", []string{"run", "-e", "process.exec(\"/bin/echo\")"}, "", EXIT_FAILURE, "", "PermissionError: process.exec needs the process capability"},
+		{"http not allowed", []string{"run", "-e", "http.get(\"http://127.0.0.1:1\")"}, "", EXIT_FAILURE, "", "PermissionError: http.get needs the net capability"},
+		{"callback error", []string{"run", "-e", "map([1, 2], x => x + \"a\")"}, "", EXIT_FAILURE, "",
+			"<inline>:1:20: TypeError: type mismatch: INTEGER + STRING\n    at <anonymous> (<inline>:1)"},
+		{"match warning", []string{"run", "-e", "enum E { A, B(x) }\nvar v = match E.B(1) { E.A => 1 }"}, "", EXIT_FAILURE, "",
+			"<inline>: 2:9: warning: match on E is not exhaustive, missing B\n<inline>:2:9: ValueError: no match arm for E.B(1)"},
+		{"throw", []string{"run", "-e", "func f() {\n    throw \"boom\"\n}\nf()"}, "", EXIT_FAILURE, "",
+			"<inline>:2:5: Error: boom\n    at f (<inline>:2)\n    at <main> (<inline>:4)\n"},
+		{"tokens", []string{"tokens", "-e", "x += 1"}, "", EXIT_OK, "Type: IDENT Literal: x\nType: INCREMENT Literal: +=\nType: INTEGER Literal: 1\n", ""},
+		{"ast", []string{"ast", "-e", "var x = 1"}, "", EXIT_OK, "VarStatement(var Ident(x) = Integer(1))\n", ""},
+		{"debug", []string{"debug", script}, "step\nprint x\nquit\n", EXIT_OK,
+			"stopped at " + script + ":1 (entry)\n   1  var x = 1\n(debug) stopped at " + script + ":2 (step)\n   2  var y = x + 1\n(debug) 1\n(debug) ", ""},
+		{"debug without script", []string{"debug"}, "", EXIT_USAGE, "", "no script given"},
+		{"repl", []string{"repl"}, "1 + 1\n", EXIT_OK, ">> 2\n>> ", ""},
+		{"unknown command", []string{"nope"}, "", EXIT_USAGE, "", `unknown command "nope"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("Run() = %d, want %d, stderr %q", code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("Run() stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("Run() stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunRecovers(t *testing.T) {
+	builtins.BuiltIns["test_panic"] = objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+		panic("broken builtin")
+	}}
+	defer delete(builtins.BuiltIns, "test_panic")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"run", "-e", "test_panic()"}, strings.NewReader(""), &stdout, &stderr)
+	if code != EXIT_FAILURE {
+		t.Errorf("Run() = %d, want %d", code, EXIT_FAILURE)
+	}
+	if want := "<inline>: internal error: broken builtin\n"; stderr.String() != want {
+		t.Errorf("Run() stderr = %q, want %q", stderr.String(), want)
+	}
+}
@@ -0,0 +1,410 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/check"
+	"github.com/EVFUBS/AlphaLang/debugger"
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/format"
+	"github.com/EVFUBS/AlphaLang/lexer"
+	"github.com/EVFUBS/AlphaLang/lineeditor"
+	"github.com/EVFUBS/AlphaLang/lsp"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/repl"
+	"github.com/EVFUBS/AlphaLang/testrunner"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+func (c *Cli) runCommand(args []string) int {
+	fs := c.flagSet("run")
+	inline := fs.String("e", "", "evaluate `code` instead of a file")
+	allow := fs.String("allow", "", "give the program the capabilities in the comma separated `list`, like fs or all")
+	seedFlag := fs.Int64("seed", 0, "seed the random number generator with `n` so runs repeat")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+	allowed, err := capabilities(*allow)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha run: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	src, rest, err := c.readSource(fs, *inline)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha run: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	program, code := c.parse(src)
+	if code != EXIT_OK {
+		return code
+	}
+
+	e := evaluator.New()
+	e.Capabilities = allowed
+	e.Stdout = c.stdout
+	e.Stderr = c.stderr
+	e.Args = rest
+	e.Stdin = lineeditor.New(c.stdin, c.stdout)
+	if n := seed(fs, seedFlag); n != nil {
+		e.Seed(*n)
+	}
+	return c.evaluate(src.name, e, program)
+}
+
+// runs the program, a panic in the interpreter is reported as an internal
+// error instead of crashing with a goroutine dump
+func (c *Cli) evaluate(name string, e *evaluator.Evaluator, program *ast.Program) (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(c.stderr, "%s: internal error: %v\n", name, r)
+			code = EXIT_FAILURE
+		}
+	}()
+	evaluated := e.Eval(program, e.Env)
+	if err, ok := evaluated.(*objects.Error); ok {
+		if err.Kind == objects.EXIT {
+			return err.Code
+		}
+		c.reportError(name, err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+// prints an uncaught error with where it happened and the calls leading to it
+func (c *Cli) reportError(name string, err *objects.Error) {
+	position := name
+	if err.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", name, err.Line, err.Column)
+	}
+	fmt.Fprintf(c.stderr, "%s: %s: %s\n", position, err.Kind, err.Message)
+	for _, frame := range err.Stack {
+		fmt.Fprintf(c.stderr, "    at %s (%s:%d)\n", frame.Name, name, frame.Line)
+	}
+}
+
+func (c *Cli) debugCommand(args []string) int {
+	fs := c.flagSet("debug")
+	dap := fs.Bool("dap", false, "serve the debug adapter protocol on stdin and stdout")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+
+	if *dap {
+		if err := debugger.NewDAP(c.stdin, c.stdout).Serve(); err != nil {
+			fmt.Fprintf(c.stderr, "alpha debug: %s\n", err)
+			return EXIT_FAILURE
+		}
+		return EXIT_OK
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "alpha debug: no script given")
+		return EXIT_USAGE
+	}
+	code, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha debug: %s\n", err)
+		return EXIT_USAGE
+	}
+	src := &source{name: fs.Arg(0), code: string(code)}
+	program, status := c.parse(src)
+	if status != EXIT_OK {
+		return status
+	}
+
+	terminal := debugger.NewTerminal(src.name, src.code, c.stdin, c.stdout)
+	terminal.Args = fs.Args()[1:]
+	terminal.Stderr = c.stderr
+	result := terminal.Run(program)
+	if err, ok := result.(*objects.Error); ok {
+		c.reportError(src.name, err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+func (c *Cli) fmtCommand(args []string) int {
+	fs := c.flagSet("fmt")
+	check := fs.Bool("check", false, "list files that are not formatted and fail if there are any")
+	write := fs.Bool("write", false, "rewrite files in place")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+
+	if fs.NArg() == 0 {
+		code, err := io.ReadAll(c.stdin)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha fmt: %s\n", err)
+			return EXIT_USAGE
+		}
+		formatted, err := format.Source(string(code))
+		if err != nil {
+			fmt.Fprintf(c.stderr, "<stdin>: %s\n", err)
+			return EXIT_PARSE
+		}
+		if *check {
+			if formatted != string(code) {
+				fmt.Fprintln(c.stdout, "<stdin>")
+				return EXIT_FAILURE
+			}
+			return EXIT_OK
+		}
+		fmt.Fprint(c.stdout, formatted)
+		return EXIT_OK
+	}
+
+	files, err := expandPaths(fs.Args(), ".al")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha fmt: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	result := EXIT_OK
+	for _, file := range files {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha fmt: %s\n", err)
+			result = EXIT_USAGE
+			continue
+		}
+		formatted, err := format.Source(string(code))
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %s\n", file, err)
+			result = EXIT_PARSE
+			continue
+		}
+
+		switch {
+		case *check:
+			if formatted != string(code) {
+				fmt.Fprintln(c.stdout, file)
+				if result == EXIT_OK {
+					result = EXIT_FAILURE
+				}
+			}
+		case *write:
+			if formatted != string(code) {
+				if err := os.WriteFile(file, []byte(formatted), 0644); err != nil {
+					fmt.Fprintf(c.stderr, "alpha fmt: %s\n", err)
+					result = EXIT_USAGE
+				}
+			}
+		default:
+			fmt.Fprint(c.stdout, formatted)
+		}
+	}
+	return result
+}
+
+func (c *Cli) checkCommand(args []string) int {
+	fs := c.flagSet("check")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+
+	var sources []*source
+	if fs.NArg() == 0 {
+		src, _, err := c.readSource(fs, "")
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha check: %s\n", err)
+			return EXIT_USAGE
+		}
+		sources = append(sources, src)
+	} else {
+		files, err := expandPaths(fs.Args(), ".al")
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha check: %s\n", err)
+			return EXIT_USAGE
+		}
+		for _, file := range files {
+			code, err := os.ReadFile(file)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "alpha check: %s\n", err)
+				return EXIT_USAGE
+			}
+			sources = append(sources, &source{name: file, code: string(code)})
+		}
+	}
+
+	result := EXIT_OK
+	for _, src := range sources {
+		program, code := c.parse(src)
+		if code != EXIT_OK {
+			result = code
+			continue
+		}
+		findings := check.Check(program)
+		for _, finding := range findings {
+			fmt.Fprintf(c.stdout, "%s:%s\n", src.name, finding.String())
+		}
+		if len(findings) > 0 && result == EXIT_OK {
+			result = EXIT_FAILURE
+		}
+	}
+	return result
+}
+
+func (c *Cli) testCommand(args []string) int {
+	fs := c.flagSet("test")
+	pattern := fs.String("run", "", "only run tests whose name matches `regexp`")
+	verbose := fs.Bool("v", false, "list every test and its output")
+	junit := fs.String("junit", "", "write a JUnit XML report to `file`")
+	allow := fs.String("allow", "", "give the program the capabilities in the comma separated `list`, like fs or all")
+	seedFlag := fs.Int64("seed", 0, "seed the random number generator with `n` so runs repeat")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+	allowed, err := capabilities(*allow)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	runner := &testrunner.Runner{Verbose: *verbose, Out: c.stdout, Capabilities: allowed, Seed: seed(fs, seedFlag)}
+	if *pattern != "" {
+		filter, err := regexp.Compile(*pattern)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+			return EXIT_USAGE
+		}
+		runner.Filter = filter
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := expandPaths(paths, testrunner.FILE_SUFFIX)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	result := EXIT_OK
+	var results []*testrunner.Result
+	for _, file := range files {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+			return EXIT_USAGE
+		}
+		if _, status := c.parse(&source{name: file, code: string(code)}); status != EXIT_OK {
+			result = status
+			continue
+		}
+		fileResults, err := runner.RunFile(file, string(code))
+		if err != nil {
+			fmt.Fprintf(c.stderr, "%s: %s\n", file, err)
+			result = EXIT_PARSE
+			continue
+		}
+		results = append(results, fileResults...)
+	}
+
+	for _, r := range results {
+		if !r.Passed() && result == EXIT_OK {
+			result = EXIT_FAILURE
+		}
+	}
+
+	if *junit != "" {
+		report, err := os.Create(*junit)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+			return EXIT_USAGE
+		}
+		defer report.Close()
+		if err := testrunner.WriteJUnit(report, results); err != nil {
+			fmt.Fprintf(c.stderr, "alpha test: %s\n", err)
+			return EXIT_USAGE
+		}
+	}
+	return result
+}
+
+func (c *Cli) replCommand(args []string) int {
+	fs := c.flagSet("repl")
+	allow := fs.String("allow", "", "give the program the capabilities in the comma separated `list`, like fs or all")
+	seedFlag := fs.Int64("seed", 0, "seed the random number generator with `n` so runs repeat")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+	allowed, err := capabilities(*allow)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha repl: %s\n", err)
+		return EXIT_USAGE
+	}
+	r := repl.New(c.stdin, c.stdout)
+	r.Capabilities = allowed
+	r.Seed = seed(fs, seedFlag)
+	r.Run()
+	return EXIT_OK
+}
+
+func (c *Cli) lspCommand(args []string) int {
+	fs := c.flagSet("lsp")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+	if err := lsp.New(c.stdin, c.stdout).Run(); err != nil {
+		fmt.Fprintf(c.stderr, "alpha lsp: %s\n", err)
+		return EXIT_FAILURE
+	}
+	return EXIT_OK
+}
+
+func (c *Cli) tokensCommand(args []string) int {
+	fs := c.flagSet("tokens")
+	inline := fs.String("e", "", "read `code` instead of a file")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+
+	src, _, err := c.readSource(fs, *inline)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha tokens: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	l := lexer.New(src.code)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintln(c.stdout, tok.String())
+	}
+	if len(l.Errors()) > 0 {
+		for _, err := range l.Errors() {
+			fmt.Fprintf(c.stderr, "%s: %s\n", src.name, err)
+		}
+		return EXIT_LEX
+	}
+	return EXIT_OK
+}
+
+func (c *Cli) astCommand(args []string) int {
+	fs := c.flagSet("ast")
+	inline := fs.String("e", "", "read `code` instead of a file")
+	if err := fs.Parse(args); err != nil {
+		return EXIT_USAGE
+	}
+
+	src, _, err := c.readSource(fs, *inline)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "alpha ast: %s\n", err)
+		return EXIT_USAGE
+	}
+
+	program, code := c.parse(src)
+	if code != EXIT_OK {
+		return code
+	}
+	for _, statement := range program.Statements {
+		fmt.Fprintln(c.stdout, statement.String())
+	}
+	return EXIT_OK
+}
@@ -0,0 +1,483 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+const THREAD_ID = 1
+
+type dapRequest struct {
+	Seq       int             `json:"seq"`
+	Command   string          `json:"command"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+type dapEvent struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+type dapSource struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+type dapVariable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// a debug adapter protocol server, editors launch it and talk to it over
+// a pair of streams
+type DAP struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// guards writes, events are sent from the running program
+	mu  sync.Mutex
+	seq int
+
+	debugger    *Debugger
+	path        string
+	stopOnEntry bool
+	done        chan struct{}
+	resume      chan struct{}
+	paused      bool
+	// set when a request resumes the program, which happens after replying
+	resuming bool
+	// variable references handed out since the program last stopped
+	handles map[int]interface{}
+}
+
+func NewDAP(in io.Reader, out io.Writer) *DAP {
+	return &DAP{in: bufio.NewReader(in), out: out, resume: make(chan struct{})}
+}
+
+// serves requests until the client disconnects
+func (s *DAP) Serve() error {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			s.stop()
+			return nil
+		}
+		if err != nil {
+			s.stop()
+			return err
+		}
+
+		var req dapRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		result, err := s.handle(&req)
+		if err != nil {
+			s.respond(&req, nil, err)
+		} else {
+			s.respond(&req, result, nil)
+		}
+
+		if s.resuming {
+			s.resuming = false
+			s.resume <- struct{}{}
+		}
+
+		switch req.Command {
+		case "launch":
+			if err == nil {
+				s.event("initialized", nil)
+			}
+		case "disconnect", "terminate":
+			s.stop()
+			if req.Command == "disconnect" {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *DAP) handle(req *dapRequest) (interface{}, error) {
+	switch req.Command {
+	case "initialize":
+		return map[string]bool{
+			"supportsConfigurationDoneRequest": true,
+			"supportsConditionalBreakpoints":   true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, nil
+	case "launch":
+		return nil, s.launch(req.Arguments)
+	case "setBreakpoints":
+		return s.setBreakpoints(req.Arguments)
+	case "configurationDone":
+		if s.debugger == nil {
+			return nil, fmt.Errorf("no program launched")
+		}
+		s.done = make(chan struct{})
+		go s.run()
+		return nil, nil
+	case "threads":
+		return map[string]interface{}{"threads": []map[string]interface{}{{"id": THREAD_ID, "name": "main"}}}, nil
+	case "pause":
+		if s.debugger != nil {
+			s.debugger.Pause()
+		}
+		return nil, nil
+	case "continue", "next", "stepIn", "stepOut":
+		return s.resumeProgram(req.Command)
+	case "stackTrace":
+		return s.stackTrace()
+	case "scopes":
+		return s.scopes(req.Arguments)
+	case "variables":
+		return s.variables(req.Arguments)
+	case "evaluate":
+		return s.evaluate(req.Arguments)
+	case "disconnect", "terminate":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported request %q", req.Command)
+}
+
+func (s *DAP) launch(arguments json.RawMessage) error {
+	var args struct {
+		Program     string   `json:"program"`
+		StopOnEntry bool     `json:"stopOnEntry"`
+		Args        []string `json:"args"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return err
+	}
+	code, err := os.ReadFile(args.Program)
+	if err != nil {
+		return err
+	}
+	program, err := parser.Parse(string(code))
+	if err != nil {
+		return err
+	}
+
+	s.path = args.Program
+	s.stopOnEntry = args.StopOnEntry
+	s.debugger = New(program, s)
+	s.debugger.Evaluator.Args = args.Args
+	s.debugger.Evaluator.Stdout = &outputWriter{s: s, category: "stdout"}
+	s.debugger.Evaluator.Stderr = &outputWriter{s: s, category: "stderr"}
+	// stdin carries the protocol
+	s.debugger.Evaluator.Stdin = evaluator.NoInput
+	return nil
+}
+
+func (s *DAP) run() {
+	result := s.debugger.Run(s.stopOnEntry)
+	exitCode := 0
+	if result != nil && result.Type() == objects.ERROR {
+		s.event("output", map[string]string{"category": "stderr", "output": result.Inspect() + "\n"})
+		exitCode = 1
+	}
+	s.event("exited", map[string]int{"exitCode": exitCode})
+	s.event("terminated", nil)
+	close(s.done)
+}
+
+// ends a running program and waits for it to finish
+func (s *DAP) stop() {
+	if s.done == nil {
+		return
+	}
+	s.debugger.Quit()
+	s.mu.Lock()
+	paused := s.paused
+	s.paused = false
+	s.mu.Unlock()
+	if paused {
+		s.resume <- struct{}{}
+	}
+	<-s.done
+	s.done = nil
+}
+
+func (s *DAP) Stopped(d *Debugger, reason string) {
+	s.mu.Lock()
+	s.paused = true
+	s.handles = make(map[int]interface{})
+	s.mu.Unlock()
+
+	s.event("stopped", map[string]interface{}{"reason": reason, "threadId": THREAD_ID, "allThreadsStopped": true})
+	<-s.resume
+}
+
+func (s *DAP) isPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+func (s *DAP) resumeProgram(command string) (interface{}, error) {
+	s.mu.Lock()
+	if !s.paused {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("program is not paused")
+	}
+	s.paused = false
+	s.mu.Unlock()
+
+	switch command {
+	case "continue":
+		s.debugger.Continue()
+	case "next":
+		s.debugger.StepOver()
+	case "stepIn":
+		s.debugger.StepIn()
+	case "stepOut":
+		s.debugger.StepOut()
+	}
+	s.resuming = true
+	return map[string]bool{"allThreadsContinued": true}, nil
+}
+
+func (s *DAP) setBreakpoints(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Breakpoints []struct {
+			Line      int    `json:"line"`
+			Condition string `json:"condition"`
+		} `json:"breakpoints"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	if s.debugger == nil {
+		return nil, fmt.Errorf("no program launched")
+	}
+
+	// only one source, so the new set replaces every breakpoint
+	s.debugger.ClearBreakpoints()
+	var result []map[string]interface{}
+	for _, requested := range args.Breakpoints {
+		bp, err := s.debugger.SetBreakpoint(requested.Line, requested.Condition)
+		if err != nil {
+			result = append(result, map[string]interface{}{"verified": false, "line": requested.Line, "message": err.Error()})
+			continue
+		}
+		result = append(result, map[string]interface{}{"verified": true, "line": bp.Line})
+	}
+	return map[string]interface{}{"breakpoints": result}, nil
+}
+
+func (s *DAP) stackTrace() (interface{}, error) {
+	if !s.isPaused() {
+		return nil, fmt.Errorf("program is not paused")
+	}
+	source := dapSource{Name: filepath.Base(s.path), Path: s.path}
+	var frames []map[string]interface{}
+	for i, frame := range s.debugger.Frames() {
+		frames = append(frames, map[string]interface{}{
+			"id":     i + 1,
+			"name":   frame.Name,
+			"line":   frame.Line,
+			"column": 1,
+			"source": source,
+		})
+	}
+	return map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+func (s *DAP) scopes(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		FrameID int `json:"frameId"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	if !s.isPaused() {
+		return nil, fmt.Errorf("program is not paused")
+	}
+	frames := s.debugger.Frames()
+	if args.FrameID < 1 || args.FrameID > len(frames) {
+		return nil, fmt.Errorf("no frame %d", args.FrameID)
+	}
+
+	var scopes []map[string]interface{}
+	depth := 0
+	for env := frames[args.FrameID-1].Env; env != nil; env = env.Outer() {
+		name := "Outer " + strconv.Itoa(depth)
+		switch {
+		case depth == 0:
+			name = "Locals"
+		case env.Outer() == nil:
+			name = "Globals"
+		}
+		scopes = append(scopes, map[string]interface{}{
+			"name":               name,
+			"variablesReference": s.reference(env),
+			"expensive":          false,
+		})
+		depth++
+	}
+	return map[string]interface{}{"scopes": scopes}, nil
+}
+
+func (s *DAP) variables(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		VariablesReference int `json:"variablesReference"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	if !s.isPaused() {
+		return nil, fmt.Errorf("program is not paused")
+	}
+	s.mu.Lock()
+	value, ok := s.handles[args.VariablesReference]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown variables reference %d", args.VariablesReference)
+	}
+
+	variables := []dapVariable{}
+	switch value := value.(type) {
+	case *objects.Environment:
+		for _, name := range value.Locals() {
+			object, _ := value.Get(name)
+			variables = append(variables, s.variable(name, object))
+		}
+	case *objects.Array:
+		for i, element := range value.Elements {
+			variables = append(variables, s.variable(strconv.Itoa(i), element))
+		}
+	case *objects.Hash:
+		for _, pair := range value.Ordered() {
+			variables = append(variables, s.variable(pair.Key.Inspect(), pair.Value))
+		}
+	}
+	return map[string]interface{}{"variables": variables}, nil
+}
+
+func (s *DAP) evaluate(arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Expression string `json:"expression"`
+		FrameID    int    `json:"frameId"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, err
+	}
+	if !s.isPaused() {
+		return nil, fmt.Errorf("program is not paused")
+	}
+	frame := 0
+	if args.FrameID > 0 {
+		frame = args.FrameID - 1
+	}
+
+	result := s.debugger.Evaluate(args.Expression, frame)
+	if result != nil && result.Type() == objects.ERROR {
+		return nil, fmt.Errorf("%s", result.(*objects.Error).Message)
+	}
+	v := s.variable("", result)
+	return map[string]interface{}{"result": v.Value, "type": v.Type, "variablesReference": v.VariablesReference}, nil
+}
+
+func (s *DAP) variable(name string, value objects.Object) dapVariable {
+	v := dapVariable{Name: name, Value: inspect(value)}
+	if value != nil {
+		v.Type = string(value.Type())
+	}
+	switch value.(type) {
+	case *objects.Array, *objects.Hash:
+		v.VariablesReference = s.reference(value)
+	}
+	return v
+}
+
+func (s *DAP) reference(value interface{}) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := len(s.handles) + 1
+	s.handles[id] = value
+	return id
+}
+
+func (s *DAP) read() ([]byte, error) {
+	headers, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading headers: %w", err)
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	return body, nil
+}
+
+func (s *DAP) write(message interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	switch message := message.(type) {
+	case *dapResponse:
+		message.Seq = s.seq
+	case *dapEvent:
+		message.Seq = s.seq
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *DAP) respond(req *dapRequest, body interface{}, err error) {
+	resp := &dapResponse{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+	if err != nil {
+		resp.Message = err.Error()
+	}
+	s.write(resp)
+}
+
+func (s *DAP) event(name string, body interface{}) {
+	s.write(&dapEvent{Type: "event", Event: name, Body: body})
+}
+
+// sends what the program prints to the editor as output events
+type outputWriter struct {
+	s        *DAP
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.s.event("output", map[string]string{"category": w.category, "output": string(p)})
+	return len(p), nil
+}
@@ -0,0 +1,157 @@
+package debugger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type dapClient struct {
+	t      *testing.T
+	in     io.Writer
+	out    *bufio.Reader
+	seq    int
+	output string
+}
+
+func (c *dapClient) send(command string, arguments interface{}) {
+	c.seq++
+	body, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+	fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// reads messages until a response to command or an event with that name
+func (c *dapClient) expect(kind, name string) map[string]interface{} {
+	for {
+		var length int
+		if _, err := fmt.Fscanf(c.out, "Content-Length: %d\r\n\r\n", &length); err != nil {
+			c.t.Fatalf("waiting for %s %s: %v", kind, name, err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(c.out, body); err != nil {
+			c.t.Fatal(err)
+		}
+		var message map[string]interface{}
+		json.Unmarshal(body, &message)
+
+		if message["type"] == "event" && message["event"] == "output" {
+			c.output += message["body"].(map[string]interface{})["output"].(string)
+		}
+		if message["type"] != kind {
+			continue
+		}
+		if message["command"] == name || message["event"] == name {
+			if kind == "response" && message["success"] != true {
+				c.t.Fatalf("%s failed: %v", name, message["message"])
+			}
+			return message
+		}
+	}
+}
+
+func body(message map[string]interface{}) map[string]interface{} {
+	return message["body"].(map[string]interface{})
+}
+
+func TestDAP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.al")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clientIn, serverIn := io.Pipe()
+	serverOut, clientOut := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- NewDAP(clientIn, clientOut).Serve()
+		clientOut.Close()
+	}()
+	c := &dapClient{t: t, in: serverIn, out: bufio.NewReader(serverOut)}
+
+	c.send("initialize", map[string]string{"adapterID": "alpha"})
+	if body(c.expect("response", "initialize"))["supportsConditionalBreakpoints"] != true {
+		t.Errorf("initialize does not report conditional breakpoints")
+	}
+	c.send("launch", map[string]interface{}{"program": path})
+	c.expect("response", "launch")
+	c.expect("event", "initialized")
+
+	c.send("setBreakpoints", map[string]interface{}{
+		"source":      map[string]string{"path": path},
+		"breakpoints": []map[string]interface{}{{"line": 3, "condition": "a > 1"}, {"line": 40}},
+	})
+	breakpoints := body(c.expect("response", "setBreakpoints"))["breakpoints"].([]interface{})
+	if verified := breakpoints[0].(map[string]interface{})["verified"]; verified != true {
+		t.Errorf("breakpoint on line 3 verified = %v", verified)
+	}
+	if verified := breakpoints[1].(map[string]interface{})["verified"]; verified != false {
+		t.Errorf("breakpoint on line 40 verified = %v", verified)
+	}
+	c.send("configurationDone", nil)
+	c.expect("response", "configurationDone")
+
+	if reason := body(c.expect("event", "stopped"))["reason"]; reason != "breakpoint" {
+		t.Errorf("stopped reason = %v", reason)
+	}
+
+	c.send("stackTrace", map[string]int{"threadId": THREAD_ID})
+	frames := body(c.expect("response", "stackTrace"))["stackFrames"].([]interface{})
+	top := frames[0].(map[string]interface{})
+	if len(frames) != 2 || top["name"] != "add" || top["line"] != float64(3) {
+		t.Errorf("stackTrace = %v", frames)
+	}
+
+	c.send("scopes", map[string]int{"frameId": 1})
+	scopes := body(c.expect("response", "scopes"))["scopes"].([]interface{})
+	locals := scopes[0].(map[string]interface{})
+	if len(scopes) != 2 || locals["name"] != "Locals" {
+		t.Fatalf("scopes = %v", scopes)
+	}
+
+	c.send("variables", map[string]interface{}{"variablesReference": locals["variablesReference"]})
+	variables := body(c.expect("response", "variables"))["variables"].([]interface{})
+	var names []string
+	for _, v := range variables {
+		v := v.(map[string]interface{})
+		names = append(names, fmt.Sprintf("%s=%s", v["name"], v["value"]))
+	}
+	if got := strings.Join(names, " "); got != "a=3 b=10 sum=13" {
+		t.Errorf("variables = %s", got)
+	}
+
+	c.send("evaluate", map[string]interface{}{"expression": "[sum, sum * 2]", "frameId": 1})
+	evaluated := body(c.expect("response", "evaluate"))
+	if evaluated["result"] != "[13, 26]" || evaluated["variablesReference"] == float64(0) {
+		t.Errorf("evaluate = %v", evaluated)
+	}
+
+	c.send("next", map[string]int{"threadId": THREAD_ID})
+	c.expect("response", "next")
+	c.expect("event", "stopped")
+	c.send("stackTrace", map[string]int{"threadId": THREAD_ID})
+	frames = body(c.expect("response", "stackTrace"))["stackFrames"].([]interface{})
+	if line := frames[0].(map[string]interface{})["line"]; len(frames) != 1 || line != float64(7) {
+		t.Errorf("after next stackTrace = %v", frames)
+	}
+
+	c.send("continue", map[string]int{"threadId": THREAD_ID})
+	c.expect("response", "continue")
+	if code := body(c.expect("event", "exited"))["exitCode"]; code != float64(0) {
+		t.Errorf("exitCode = %v", code)
+	}
+	c.expect("event", "terminated")
+	if c.output != "13\n" {
+		t.Errorf("output = %q, want %q", c.output, "13\n")
+	}
+
+	c.send("disconnect", nil)
+	c.expect("response", "disconnect")
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
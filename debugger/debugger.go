@@ -0,0 +1,339 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+// how far the program runs after resuming
+const (
+	RUN = iota // until a breakpoint
+	STEP_IN
+	STEP_OVER
+	STEP_OUT
+)
+
+// reasons the program stopped
+const (
+	REASON_ENTRY      = "entry"
+	REASON_BREAKPOINT = "breakpoint"
+	REASON_STEP       = "step"
+	REASON_PAUSE      = "pause"
+)
+
+type Breakpoint struct {
+	Line      int
+	Condition string
+	Hits      int
+	condition ast.AstExpression
+}
+
+// a user interface for the debugger
+type Frontend interface {
+	// called from the running program while it is paused, the program
+	// resumes once it returns
+	Stopped(d *Debugger, reason string)
+}
+
+type Debugger struct {
+	Evaluator *evaluator.Evaluator
+	program   *ast.Program
+	frontend  Frontend
+	// first statement starting on each line, where breakpoints stop
+	statements map[int]ast.AstStatement
+	// breakpoints can change while the program runs
+	mu          sync.Mutex
+	breakpoints map[int]*Breakpoint
+
+	mode  int
+	depth int
+	entry bool
+	// set from other goroutines while the program runs
+	pause int32
+	quit  int32
+	// breakpoints and stepping are off while the user evaluates code
+	evaluating bool
+}
+
+func New(program *ast.Program, frontend Frontend) *Debugger {
+	d := &Debugger{
+		Evaluator:   evaluator.New(),
+		program:     program,
+		frontend:    frontend,
+		statements:  make(map[int]ast.AstStatement),
+		breakpoints: make(map[int]*Breakpoint),
+	}
+	d.index(program.Statements)
+	return d
+}
+
+func (d *Debugger) index(statements []ast.AstStatement) {
+	for _, statement := range statements {
+		if statement == nil {
+			continue
+		}
+		if _, ok := d.statements[statement.Lines().StartLine]; !ok {
+			d.statements[statement.Lines().StartLine] = statement
+		}
+		switch node := statement.(type) {
+		case *ast.ExpressionStatement:
+			d.indexFunctions(node.Expression)
+		case *ast.VarStatement:
+			d.indexFunctions(node.Value)
+		case *ast.ReturnStatement:
+			d.indexFunctions(node.ReturnValue)
+		case *ast.BlockStatement:
+			d.index(node.Statements)
+		case *ast.IfStatement:
+			d.index(node.If.Consequence.Statements)
+			for _, elif := range node.Elif {
+				d.index(elif.Consequence.Statements)
+			}
+			d.index(node.Else.Statements)
+		case *ast.ForStatement:
+			d.index([]ast.AstStatement{node.Body})
+		case *ast.WhileStatement:
+			d.index([]ast.AstStatement{node.Body})
+		case *ast.StructStatement:
+			for _, method := range node.Methods {
+				d.index(method.Body.Statements)
+			}
+		case *ast.TryStatement:
+			d.index(node.Body.Statements)
+			if node.Catch != nil {
+				d.index(node.Catch.Statements)
+			}
+			if node.Finally != nil {
+				d.index(node.Finally.Statements)
+			}
+		}
+	}
+}
+
+// indexes the bodies of the functions written in an expression, so lines of
+// anonymous functions can have breakpoints
+func (d *Debugger) indexFunctions(expression ast.AstExpression) {
+	switch node := expression.(type) {
+	case *ast.FunctionLiteral:
+		d.index(node.Body.Statements)
+	case *ast.CallExpression:
+		d.indexFunctions(node.Function)
+		for _, arg := range node.Arguments {
+			d.indexFunctions(arg)
+		}
+	case *ast.NamedArgument:
+		d.indexFunctions(node.Value)
+	case *ast.ArrayLiteral:
+		for _, element := range node.Elements {
+			d.indexFunctions(element)
+		}
+	case *ast.HashLiteral:
+		for _, key := range node.Keys {
+			d.indexFunctions(node.Pairs[key])
+		}
+	case *ast.Reassignment:
+		d.indexFunctions(node.Value)
+	case *ast.FieldAssignment:
+		d.indexFunctions(node.Value)
+	}
+}
+
+// runs the program to the end, returning nil if the user quit
+func (d *Debugger) Run(stopOnEntry bool) objects.Object {
+	d.entry = stopOnEntry
+	d.Evaluator.Trace = d.trace
+	defer func() { d.Evaluator.Trace = nil }()
+
+	result := d.Evaluator.Eval(d.program, d.Evaluator.Env)
+	if atomic.LoadInt32(&d.quit) == 1 {
+		return nil
+	}
+	return result
+}
+
+func (d *Debugger) trace(statement ast.AstStatement, env *objects.Environment) objects.Object {
+	if d.evaluating {
+		return nil
+	}
+	if atomic.LoadInt32(&d.quit) == 1 {
+		return stopped()
+	}
+
+	depth := len(d.Evaluator.Stack)
+	var reason string
+	switch {
+	case d.entry:
+		d.entry = false
+		reason = REASON_ENTRY
+	case atomic.SwapInt32(&d.pause, 0) == 1:
+		reason = REASON_PAUSE
+	case d.mode == STEP_IN,
+		d.mode == STEP_OVER && depth <= d.depth,
+		d.mode == STEP_OUT && depth < d.depth:
+		reason = REASON_STEP
+	case d.hit(statement, env):
+		reason = REASON_BREAKPOINT
+	default:
+		return nil
+	}
+
+	d.mode = RUN
+	d.frontend.Stopped(d, reason)
+	if atomic.LoadInt32(&d.quit) == 1 {
+		return stopped()
+	}
+	return nil
+}
+
+// ends the program, try can not catch it
+func stopped() *objects.Error {
+	err := evaluator.NewError("debugging stopped")
+	err.Fatal = true
+	return err
+}
+
+func (d *Debugger) hit(statement ast.AstStatement, env *objects.Environment) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line := statement.Lines().StartLine
+	bp, ok := d.breakpoints[line]
+	if !ok || d.statements[line] != statement {
+		return false
+	}
+	if bp.condition != nil {
+		d.evaluating = true
+		result := d.Evaluator.Eval(bp.condition, env)
+		d.evaluating = false
+		// a broken condition stops so the user can see what is wrong
+		if boolean, ok := result.(*objects.Boolean); ok && !boolean.Value {
+			return false
+		}
+	}
+	bp.Hits++
+	return true
+}
+
+func (d *Debugger) Continue() { d.resume(RUN) }
+func (d *Debugger) StepIn()   { d.resume(STEP_IN) }
+func (d *Debugger) StepOver() { d.resume(STEP_OVER) }
+func (d *Debugger) StepOut()  { d.resume(STEP_OUT) }
+
+func (d *Debugger) resume(mode int) {
+	d.mode = mode
+	d.depth = len(d.Evaluator.Stack)
+}
+
+// stops the program at the next statement, safe to call while it runs
+func (d *Debugger) Pause() {
+	atomic.StoreInt32(&d.pause, 1)
+}
+
+// ends the program at the next statement, safe to call while it runs
+func (d *Debugger) Quit() {
+	atomic.StoreInt32(&d.quit, 1)
+}
+
+// sets a breakpoint on the first line at or after line that has a statement,
+// the condition is an expression that must be true for it to stop
+func (d *Debugger) SetBreakpoint(line int, condition string) (*Breakpoint, error) {
+	var lines []int
+	for l := range d.statements {
+		if l >= line {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no code at or after line %d", line)
+	}
+	sort.Ints(lines)
+
+	bp := &Breakpoint{Line: lines[0], Condition: condition}
+	if condition != "" {
+		program, err := parser.Parse(condition)
+		if err != nil {
+			return nil, err
+		}
+		if len(program.Statements) != 1 {
+			return nil, fmt.Errorf("condition must be an expression")
+		}
+		statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			return nil, fmt.Errorf("condition must be an expression")
+		}
+		bp.condition = statement.Expression
+	}
+	d.mu.Lock()
+	d.breakpoints[bp.Line] = bp
+	d.mu.Unlock()
+	return bp, nil
+}
+
+func (d *Debugger) ClearBreakpoint(line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.breakpoints[line]
+	delete(d.breakpoints, line)
+	return ok
+}
+
+func (d *Debugger) ClearBreakpoints() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints = make(map[int]*Breakpoint)
+}
+
+// breakpoints ordered by line
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var bps []*Breakpoint
+	for _, bp := range d.breakpoints {
+		bps = append(bps, bp)
+	}
+	sort.Slice(bps, func(i, j int) bool { return bps[i].Line < bps[j].Line })
+	return bps
+}
+
+// the call stack with the innermost frame first
+func (d *Debugger) Frames() []*evaluator.Frame {
+	var frames []*evaluator.Frame
+	for i := len(d.Evaluator.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, d.Evaluator.Stack[i])
+	}
+	return frames
+}
+
+// evaluates code in the environment of a frame, 0 being the innermost
+func (d *Debugger) Evaluate(code string, frame int) objects.Object {
+	frames := d.Frames()
+	if frame < 0 || frame >= len(frames) {
+		return evaluator.NewError("no frame %d", frame)
+	}
+	program, err := parser.Parse(code)
+	if err != nil {
+		return evaluator.NewError("%s", err)
+	}
+
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+
+	var result objects.Object
+	for _, statement := range program.Statements {
+		result = d.Evaluator.Eval(statement, frames[frame].Env)
+		if returnValue, ok := result.(*objects.ReturnValue); ok {
+			return returnValue.Value
+		}
+		if result != nil && result.Type() == objects.ERROR {
+			return result
+		}
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+const script = `func add(a, b) {
+    var sum = a + b
+    return sum
+}
+var x = add(1, 2)
+var y = add(x, 10)
+println(y)
+`
+
+func runTerminal(t *testing.T, input string) string {
+	program, err := parser.Parse(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+
+	result := NewTerminal("test.al", script, strings.NewReader(input), &out).Run(program)
+	if result != nil && result.Type() == "ERROR" {
+		t.Fatalf("Run() = %s", result.Inspect())
+	}
+	return strings.ReplaceAll(out.String(), PROMPT, "")
+}
+
+func TestTerminal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"entry", "continue\n", []string{"stopped at test.al:1 (entry)\n   1  func add(a, b) {\n", "13\n"}},
+		{"conditional breakpoint", "break 2 if a == 3\ncontinue\nprint a + b\ncontinue\n", []string{
+			"breakpoint at test.al:2\n",
+			"stopped at test.al:2 (breakpoint)\n",
+			"13\n13\n",
+		}},
+		{"breakpoint moves to code", "break 4\nc\n", []string{"breakpoint at test.al:5\n", "stopped at test.al:5 (breakpoint)"}},
+		{"stack and env", "b 3\nc\nstack\nenv\nframe 1\nprint a\nq\n", []string{
+			"* #0 add at test.al:3\n  #1 <main> at test.al:5\n",
+			"locals:\n  a = 1\n  b = 2\n  sum = 3\nglobals:\n  add = func add(a, b)\n",
+			"#1 <main> at test.al:5\n",
+			"ERROR: identifier not found: a\n",
+		}},
+		{"step into and out", "next\nstep\nstep\nout\n", []string{
+			"stopped at test.al:5 (step)",
+			"stopped at test.al:2 (step)",
+			"stopped at test.al:3 (step)",
+			"stopped at test.al:6 (step)",
+		}},
+		{"repeat last command", "n\n\n\n\n", []string{"stopped at test.al:7 (step)", "13\n"}},
+		{"quit", "quit\n", []string{"stopped at test.al:1 (entry)\n   1  func add(a, b) {\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runTerminal(t, tt.input)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
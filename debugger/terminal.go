@@ -0,0 +1,237 @@
+package debugger
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/format"
+	"github.com/EVFUBS/AlphaLang/lineeditor"
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+const PROMPT = "(debug) "
+
+var HELP = []string{
+	"break LINE [if COND]   stop at a line, only when COND is true if given (b)",
+	"clear LINE             remove the breakpoint on a line",
+	"breakpoints            list breakpoints",
+	"continue               run until the next breakpoint (c)",
+	"step                   run to the next statement, entering calls (s)",
+	"next                   run to the next statement in this function (n)",
+	"out                    run until the current function returns (o)",
+	"print EXPR             evaluate an expression in the selected frame (p)",
+	"env                    show the environment chain of the selected frame",
+	"stack                  show the call stack (bt)",
+	"frame N                select a frame of the call stack",
+	"list                   show the code around the current line (l)",
+	"quit                   stop the program (q)",
+}
+
+// a debugger front end reading commands from a terminal
+type Terminal struct {
+	// the arguments the program sees, and where it reports errors
+	Args   []string
+	Stderr io.Writer
+	editor *lineeditor.Editor
+	out    io.Writer
+	name   string
+	lines  []string
+	// frame selected for inspection, 0 is the innermost
+	frame int
+	last  string
+}
+
+func NewTerminal(name, code string, in io.Reader, out io.Writer) *Terminal {
+	return &Terminal{
+		editor: lineeditor.New(in, out),
+		out:    out,
+		name:   name,
+		lines:  strings.Split(code, "\n"),
+	}
+}
+
+// debugs the program, stopping before the first statement so breakpoints can
+// be set, returns the result of the program or nil if the user quit
+func (t *Terminal) Run(program *ast.Program) objects.Object {
+	d := New(program, t)
+	d.Evaluator.Stdout = t.out
+	d.Evaluator.Stderr = t.Stderr
+	d.Evaluator.Stdin = t.editor
+	d.Evaluator.Args = t.Args
+	return d.Run(true)
+}
+
+func (t *Terminal) Stopped(d *Debugger, reason string) {
+	t.frame = 0
+	line := d.Frames()[0].Line
+	fmt.Fprintf(t.out, "stopped at %s:%d (%s)\n", t.name, line, reason)
+	t.show(line)
+
+	for {
+		input, err := t.editor.ReadLine(PROMPT)
+		if err == lineeditor.ErrInterrupted {
+			continue
+		}
+		if err != nil {
+			d.Quit()
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		// an empty line repeats the last command
+		if input == "" {
+			input = t.last
+		} else {
+			t.editor.AddHistory(input)
+			t.last = input
+		}
+		if t.command(d, input) {
+			return
+		}
+	}
+}
+
+// runs a command, returning true when the program should resume
+func (t *Terminal) command(d *Debugger, input string) bool {
+	name, arg := input, ""
+	if i := strings.IndexByte(input, ' '); i >= 0 {
+		name, arg = input[:i], strings.TrimSpace(input[i+1:])
+	}
+
+	switch name {
+	case "":
+	case "c", "continue":
+		d.Continue()
+		return true
+	case "s", "step":
+		d.StepIn()
+		return true
+	case "n", "next":
+		d.StepOver()
+		return true
+	case "o", "out":
+		d.StepOut()
+		return true
+	case "q", "quit":
+		d.Quit()
+		return true
+	case "b", "break":
+		t.setBreakpoint(d, arg)
+	case "clear":
+		line, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Fprintln(t.out, "usage: clear LINE")
+		} else if !d.ClearBreakpoint(line) {
+			fmt.Fprintf(t.out, "no breakpoint on line %d\n", line)
+		}
+	case "breakpoints":
+		for _, bp := range d.Breakpoints() {
+			fmt.Fprintf(t.out, "%s:%d", t.name, bp.Line)
+			if bp.Condition != "" {
+				fmt.Fprintf(t.out, " if %s", bp.Condition)
+			}
+			fmt.Fprintf(t.out, " (hits %d)\n", bp.Hits)
+		}
+	case "p", "print":
+		result := d.Evaluate(arg, t.frame)
+		if result != nil {
+			fmt.Fprintln(t.out, result.Inspect())
+		}
+	case "env":
+		t.env(d)
+	case "bt", "stack":
+		for i, frame := range d.Frames() {
+			marker := " "
+			if i == t.frame {
+				marker = "*"
+			}
+			fmt.Fprintf(t.out, "%s #%d %s at %s:%d\n", marker, i, frame.Name, t.name, frame.Line)
+		}
+	case "frame":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n >= len(d.Frames()) {
+			fmt.Fprintf(t.out, "no frame %q\n", arg)
+			break
+		}
+		t.frame = n
+		frame := d.Frames()[n]
+		fmt.Fprintf(t.out, "#%d %s at %s:%d\n", n, frame.Name, t.name, frame.Line)
+		t.show(frame.Line)
+	case "l", "list":
+		line := d.Frames()[t.frame].Line
+		for i := line - 5; i <= line+5; i++ {
+			if i < 1 || i > len(t.lines) {
+				continue
+			}
+			marker := " "
+			if i == line {
+				marker = ">"
+			}
+			fmt.Fprintf(t.out, "%s %4d  %s\n", marker, i, t.lines[i-1])
+		}
+	case "h", "help":
+		for _, line := range HELP {
+			fmt.Fprintln(t.out, line)
+		}
+	default:
+		fmt.Fprintf(t.out, "unknown command %q, try help\n", name)
+	}
+	return false
+}
+
+func (t *Terminal) setBreakpoint(d *Debugger, arg string) {
+	lineArg, condition := arg, ""
+	if i := strings.Index(arg, " if "); i >= 0 {
+		lineArg, condition = arg[:i], strings.TrimSpace(arg[i+4:])
+	}
+	line, err := strconv.Atoi(strings.TrimSpace(lineArg))
+	if err != nil {
+		fmt.Fprintln(t.out, "usage: break LINE [if COND]")
+		return
+	}
+	bp, err := d.SetBreakpoint(line, condition)
+	if err != nil {
+		fmt.Fprintf(t.out, "cannot set breakpoint: %s\n", err)
+		return
+	}
+	fmt.Fprintf(t.out, "breakpoint at %s:%d\n", t.name, bp.Line)
+}
+
+// prints each environment from the frame outwards
+func (t *Terminal) env(d *Debugger) {
+	depth := 0
+	for env := d.Frames()[t.frame].Env; env != nil; env = env.Outer() {
+		switch {
+		case depth == 0:
+			fmt.Fprintln(t.out, "locals:")
+		case env.Outer() == nil:
+			fmt.Fprintln(t.out, "globals:")
+		default:
+			fmt.Fprintf(t.out, "outer %d:\n", depth)
+		}
+		for _, name := range env.Locals() {
+			value, _ := env.Get(name)
+			fmt.Fprintf(t.out, "  %s = %s\n", name, inspect(value))
+		}
+		depth++
+	}
+}
+
+func (t *Terminal) show(line int) {
+	if line >= 1 && line <= len(t.lines) {
+		fmt.Fprintf(t.out, "%4d  %s\n", line, t.lines[line-1])
+	}
+}
+
+func inspect(value objects.Object) string {
+	if fn, ok := value.(*objects.Function); ok {
+		return format.Signature(fn.Name, fn.Parameters, nil)
+	}
+	if value == nil {
+		return "null"
+	}
+	return value.Inspect()
+}
@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+// an argument passed by name, f(x = 1)
+type namedArgument struct {
+	name  string
+	value objects.Object
+	token *token.Token
+}
+
+// stands in for the parameters a call leaves to their default
+var omitted = &objects.Null{}
+
+func bindFunction(fn *objects.Function, args []objects.Object, named []namedArgument) ([]objects.Object, objects.Object) {
+	return bind(objects.FunctionSignature(fn.Name, fn.Parameters), args, named)
+}
+
+// matches the arguments of a call to the parameters, the parameters left to
+// their default are omitted and a variadic last parameter gets an array of
+// the arguments left over
+func bind(signature *objects.Signature, args []objects.Object, named []namedArgument) ([]objects.Object, objects.Object) {
+	var names []string
+	for _, arg := range named {
+		names = append(names, arg.name)
+	}
+	bound, err := signature.Bind(len(args), names)
+	if err != nil {
+		argErr := objects.NewErrorKind(objects.ARGUMENT_ERROR, "%s", err.Message)
+		if err.Named >= 0 {
+			return nil, withPosition(argErr, named[err.Named].token)
+		}
+		return nil, argErr
+	}
+
+	values := make([]objects.Object, len(signature.Params))
+	for i, n := range bound {
+		switch {
+		case n < 0:
+			values[i] = omitted
+		case n < len(args):
+			values[i] = args[n]
+		default:
+			values[i] = named[n-len(args)].value
+		}
+	}
+	if signature.Variadic {
+		rest := []objects.Object{}
+		if fixed := signature.Fixed(); len(args) > fixed {
+			rest = append(rest, args[fixed:]...)
+		}
+		values[len(values)-1] = &objects.Array{Elements: rest}
+	}
+	return values, nil
+}
+
+// enforces the annotation of a parameter, each argument collected by a
+// variadic one is checked against it
+func checkArgument(fn *objects.Function, param ast.Parameter, value objects.Object) objects.Object {
+	if param.Type == nil {
+		return nil
+	}
+	values := []objects.Object{value}
+	if array, ok := value.(*objects.Array); ok && param.Variadic {
+		values = array.Elements
+	}
+	for _, value := range values {
+		if !objects.Satisfies(value, param.Type.Name) {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "argument %s of %s must be %s, got %s",
+				param.Ident.Ident, fn.Name, param.Type.Name, objects.TypeName(value))
+		}
+	}
+	return nil
+}
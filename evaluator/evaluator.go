@@ -2,15 +2,57 @@ package evaluator
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"time"
 
 	"github.com/EVFUBS/AlphaLang/ast"
 	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/lineeditor"
 	"github.com/EVFUBS/AlphaLang/objects"
 	"github.com/EVFUBS/AlphaLang/token"
 )
 
 type Evaluator struct {
 	Env *objects.Environment
+	// calls in progress, the program itself is the first frame
+	Stack []*Frame
+	// called before each statement runs, a returned error stops the program
+	Trace func(statement ast.AstStatement, env *objects.Environment) objects.Object
+	// capabilities the program was given, like fs
+	Capabilities map[string]bool
+	// the random number generator, seeded from the clock on first use
+	Rand *rand.Rand
+	// where the program writes, os.Stdout and os.Stderr when nil
+	Stdout io.Writer
+	Stderr io.Writer
+	// the arguments given to the program after its file name
+	Args []string
+	// where input reads lines, os.Stdin when nil
+	Stdin LineReader
+}
+
+// reads a line after showing the prompt, lineeditor.Editor is one
+type LineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// for programs that are given no input, every read is io.EOF
+var NoInput LineReader = noInput{}
+
+type noInput struct{}
+
+func (noInput) ReadLine(prompt string) (string, error) {
+	return "", io.EOF
+}
+
+type Frame struct {
+	Name string
+	// line of the statement running in this frame
+	Line int
+	Env  *objects.Environment
 }
 
 func New() *Evaluator {
@@ -60,16 +102,41 @@ func (e *Evaluator) Eval(node ast.AstNode, env *objects.Environment) objects.Obj
 		return e.evalForStatement(node, env)
 	case *ast.WhileStatement:
 		return e.evalWhileStatement(node, env)
+	case *ast.TryStatement:
+		return e.evalTryStatement(node, env)
+	case *ast.ThrowStatement:
+		return e.evalThrowStatement(node, env)
+	case *ast.StructStatement:
+		return e.evalStructStatement(node, env)
+	case *ast.EnumStatement:
+		return e.evalEnumStatement(node, env)
+	case *ast.MatchExpression:
+		return e.evalMatchExpression(node, env)
+	case *ast.MemberExpression:
+		return e.evalMemberExpression(node, env)
+	case *ast.Reassignment:
+		return e.evalReassignement(node, env)
+	case *ast.FieldAssignment:
+		return e.evalFieldAssignment(node, env)
 	}
 	return nil
 }
 
 func (e *Evaluator) evalProgram(node *ast.Program) objects.Object {
+	e.Stack = append(e.Stack, &Frame{Name: "<main>", Env: e.Env})
+	defer e.popFrame()
+
 	var result objects.Object
 	for _, statement := range node.Statements {
+		if stop := e.step(statement, e.Env); stop != nil {
+			return stop
+		}
 		result = e.Eval(statement, e.Env)
-		if result != nil {
-			return result
+		if returnValue, ok := result.(*objects.ReturnValue); ok {
+			return returnValue.Value
+		}
+		if isError(result) {
+			return e.unwinding(result, statement)
 		}
 	}
 	return result
@@ -77,16 +144,59 @@ func (e *Evaluator) evalProgram(node *ast.Program) objects.Object {
 
 func (e *Evaluator) evalBlockStatement(node *ast.BlockStatement, env *objects.Environment) objects.Object {
 	for _, statement := range node.Statements {
+		if stop := e.step(statement, env); stop != nil {
+			return stop
+		}
 		result := e.Eval(statement, env)
-		if result != nil {
+		if isError(result) {
+			return e.unwinding(result, statement)
+		}
+		if result != nil && result.Type() == objects.RETURN_VALUE {
 			return result
 		}
 	}
 	return nil
 }
 
+// fills in where an error came from the first time it leaves a statement,
+// while the calls it happened in are still on the stack
+func (e *Evaluator) unwinding(obj objects.Object, statement ast.AstStatement) objects.Object {
+	err := obj.(*objects.Error)
+	if err.Line == 0 {
+		err.Line = statement.Lines().StartLine
+		err.Column = statement.Lines().StartColumn
+	}
+	if err.Stack == nil {
+		for i := len(e.Stack) - 1; i >= 0; i-- {
+			err.Stack = append(err.Stack, objects.StackFrame{Name: e.Stack[i].Name, Line: e.Stack[i].Line})
+		}
+	}
+	return err
+}
+
+// records the line about to run and hands the statement to the tracer
+func (e *Evaluator) step(statement ast.AstStatement, env *objects.Environment) objects.Object {
+	if len(e.Stack) > 0 {
+		e.Stack[len(e.Stack)-1].Line = statement.Lines().StartLine
+	}
+	if e.Trace != nil {
+		return e.Trace(statement, env)
+	}
+	return nil
+}
+
+func (e *Evaluator) popFrame() {
+	e.Stack = e.Stack[:len(e.Stack)-1]
+}
+
 func (e *Evaluator) evalVarStatement(node *ast.VarStatement, env *objects.Environment) objects.Object {
 	value := e.Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	if value == nil {
+		value = &objects.Null{}
+	}
 	ident := node.Identifer.Ident
 
 	env.Set(ident, value)
@@ -97,44 +207,64 @@ func (e *Evaluator) evalExprStatement(node *ast.ExpressionStatement, env *object
 	switch node := node.Expression.(type) {
 	case *ast.Reassignment:
 		return e.evalReassignement(node, env)
-	case *ast.FunctionLiteral:
-		return e.evalFunctionLiteral(node, env)
-	case *ast.CallExpression:
-		return e.evalCallExpression(node, env)
-	case *ast.InfixExpression:
-		return e.evalInfixExpression(node, env)
+	case nil:
+		return nil
+	default:
+		return e.Eval(node, env)
 	}
-	return nil
 }
 
 func (e *Evaluator) evalReturnStatement(node *ast.ReturnStatement, env *objects.Environment) objects.Object {
+	if node.ReturnValue == nil {
+		return &objects.ReturnValue{Value: &objects.Null{}}
+	}
 	value := e.Eval(node.ReturnValue, env)
+	if isError(value) {
+		return value
+	}
 	return &objects.ReturnValue{Value: value}
 }
 
 func (e *Evaluator) evalIfStatement(node *ast.IfStatement, env *objects.Environment) objects.Object {
-	if obj := e.Eval(node.If.Condition, env).(*objects.Boolean); obj.Value {
-		return e.Eval(&node.If.Consequence, env)
-	} else {
-		for _, elif := range node.Elif {
-			if e.Eval(elif.Condition, env).(*objects.Boolean).Value {
-				return e.Eval(&elif.Consequence, env)
-			}
+	conditionals := append([]ast.Conditional{node.If}, node.Elif...)
+	for _, conditional := range conditionals {
+		condition := e.Eval(conditional.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		boolean, ok := condition.(*objects.Boolean)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "condition must be a boolean, got %s", condition.Type())
+		}
+		if boolean.Value {
+			return e.Eval(&conditional.Consequence, env)
 		}
-		return e.Eval(&node.Else, env)
 	}
+	return e.Eval(&node.Else, env)
 }
 
 func (e *Evaluator) evalForStatement(node *ast.ForStatement, env *objects.Environment) objects.Object {
-	e.Eval(node.Initializer, env)
+	if result := e.Eval(node.Initializer, env); isError(result) {
+		return result
+	}
 	for {
 		condition := e.Eval(node.Conditional, env)
-		if condition.(*objects.Boolean).Value {
-			e.Eval(node.Body, env)
-		} else {
+		if isError(condition) {
+			return condition
+		}
+		boolean, ok := condition.(*objects.Boolean)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "condition must be a boolean, got %s", condition.Type())
+		}
+		if !boolean.Value {
 			break
 		}
-		e.Eval(node.Increment, env)
+		if result := e.Eval(node.Body, env); result != nil {
+			return result
+		}
+		if result := e.Eval(node.Increment, env); isError(result) {
+			return result
+		}
 	}
 	return nil
 }
@@ -142,25 +272,76 @@ func (e *Evaluator) evalForStatement(node *ast.ForStatement, env *objects.Enviro
 func (e *Evaluator) evalWhileStatement(node *ast.WhileStatement, env *objects.Environment) objects.Object {
 	for {
 		condition := e.Eval(node.Condition, env)
-		if boolean, ok := condition.(*objects.Boolean); ok {
-			if boolean.Value {
-				e.Eval(node.Body, env)
-			} else {
-				break
-			}
+		if isError(condition) {
+			return condition
+		}
+		boolean, ok := condition.(*objects.Boolean)
+		if !ok {
+			return objects.NewErrorKind(objects.TYPE_ERROR, "condition must be a boolean, got %s", condition.Type())
+		}
+		if !boolean.Value {
+			break
+		}
+		if result := e.Eval(node.Body, env); result != nil {
+			return result
 		}
 	}
 	return nil
 }
 
+func (e *Evaluator) evalTryStatement(node *ast.TryStatement, env *objects.Environment) objects.Object {
+	result := e.Eval(&node.Body, env)
+
+	if err, ok := result.(*objects.Error); ok && !err.Fatal && node.Catch != nil {
+		if node.CatchIdent != nil {
+			env.Set(node.CatchIdent.Ident, &objects.ErrorValue{Err: err})
+		}
+		result = e.Eval(node.Catch, env)
+	}
+
+	// an error or return in finally replaces the outcome of the rest
+	if node.Finally != nil {
+		if final := e.Eval(node.Finally, env); final != nil {
+			return final
+		}
+	}
+	return result
+}
+
+func (e *Evaluator) evalThrowStatement(node *ast.ThrowStatement, env *objects.Environment) objects.Object {
+	value := e.Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	switch value := value.(type) {
+	case *objects.ErrorValue:
+		// thrown again as it is, keeping where it first happened
+		return value.Err
+	case *objects.String:
+		return NewError("%s", value.Value)
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "can only throw a string or an error, got %s", typeOf(value))
+}
+
 // eval expression statements
 func (e *Evaluator) evalReassignement(node *ast.Reassignment, env *objects.Environment) objects.Object {
-	if _, ok := env.Get(node.Ident.Ident); ok {
-		env.Set(node.Ident.Ident, e.Eval(node.Value, env))
+	if _, ok := env.Get(node.Ident.Ident); !ok {
+		return objects.NewErrorKind(objects.NAME_ERROR, "identifier not found: %s", node.Ident.Ident)
+	}
+	value := e.Eval(node.Value, env)
+	if isError(value) {
+		return value
 	}
+	if value == nil {
+		value = &objects.Null{}
+	}
+	env.Set(node.Ident.Ident, value)
 	return nil
 }
 
+// a named function is declared in the environment, an anonymous one is a
+// value, both keep the environment they were made in
 func (e *Evaluator) evalFunctionLiteral(node *ast.FunctionLiteral, env *objects.Environment) objects.Object {
 	fn := &objects.Function{
 		Name:       node.Name,
@@ -168,39 +349,497 @@ func (e *Evaluator) evalFunctionLiteral(node *ast.FunctionLiteral, env *objects.
 		Body:       node.Body,
 		Env:        *env,
 	}
+	if node.Name == "" {
+		fn.Name = "<anonymous>"
+		return fn
+	}
 	env.Set(node.Name, fn)
 	return nil
 }
 
-func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *objects.Environment) objects.Object {
-	function := e.Eval(node.Function, env)
+func (e *Evaluator) evalStructStatement(node *ast.StructStatement, env *objects.Environment) objects.Object {
+	def := &objects.StructType{Name: node.Name, Methods: make(map[string]*objects.Function)}
+	for _, field := range node.Fields {
+		def.Fields = append(def.Fields, field.Ident)
+	}
+	for _, method := range node.Methods {
+		def.Methods[method.Name] = &objects.Function{
+			Name:       node.Name + "." + method.Name,
+			Parameters: method.Parameters,
+			Body:       method.Body,
+			Env:        *env,
+		}
+	}
+	env.Set(node.Name, def)
+	return nil
+}
+
+// builds a struct from its field values in declaration order
+func newStruct(def *objects.StructType, args []objects.Object) objects.Object {
+	instance := &objects.Struct{Def: def, Fields: make(map[string]objects.Object)}
+	for i, field := range def.Fields {
+		if args[i] == nil {
+			args[i] = &objects.Null{}
+		}
+		instance.Fields[field] = args[i]
+	}
+	return instance
+}
+
+func (e *Evaluator) evalMemberExpression(node *ast.MemberExpression, env *objects.Environment) objects.Object {
+	left := e.Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	return withPosition(e.member(left, node.Member.Ident), &node.Member.Token)
+}
+
+// like member, but a module can only be used when the program was given its
+// capability
+func (e *Evaluator) member(obj objects.Object, name string) objects.Object {
+	if module, ok := obj.(*objects.Module); ok && !e.Allows(module.Capability) {
+		return objects.NewErrorKind(objects.PERMISSION_ERROR, "%s.%s needs the %s capability, run with -allow %s",
+			module.Name, name, module.Capability, module.Capability)
+	}
+	return member(obj, name)
+}
+
+// a field of a struct, or a method of a struct or its declaration
+func member(obj objects.Object, name string) objects.Object {
+	switch obj := obj.(type) {
+	case *objects.Struct:
+		if value, ok := obj.Fields[name]; ok {
+			return value
+		}
+		if method, ok := obj.Def.Methods[name]; ok {
+			return method
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "%s has no field %s", obj.Def.Name, name)
+	case *objects.StructType:
+		if method, ok := obj.Methods[name]; ok {
+			return method
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "%s has no method %s", obj.Name, name)
+	case *objects.Enum:
+		if obj.HasVariant(name) {
+			return variant(obj, name)
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "%s has no variant %s", obj.Name, name)
+	case *objects.EnumValue:
+		if value, ok := obj.Field(name); ok {
+			return value
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "%s.%s has no field %s", obj.Enum.Name, obj.Variant, name)
+	case *objects.ErrorValue:
+		if field, ok := obj.Field(name); ok {
+			return field
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "error has no field %s", name)
+	case *objects.Module:
+		if value, ok := obj.Members[name]; ok {
+			return value
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "module %s has no member %s", obj.Name, name)
+	case *objects.File:
+		if method, ok := obj.Methods[name]; ok {
+			return method
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "file has no method %s", name)
+	case *objects.Iterator:
+		if method, ok := obj.Methods[name]; ok {
+			return method
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "iterator has no method %s", name)
+	case *objects.Regex:
+		if method, ok := obj.Methods[name]; ok {
+			return method
+		}
+		if name == "pattern" {
+			return &objects.String{Value: obj.Pattern}
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "regex has no method %s", name)
+	case *objects.Time:
+		if field, ok := obj.Field(name); ok {
+			return field
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "time has no field %s", name)
+	case *objects.Duration:
+		if field, ok := obj.Field(name); ok {
+			return field
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "duration has no field %s", name)
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "%s has no fields, tried to access %s", typeOf(obj), name)
+}
+
+func (e *Evaluator) evalEnumStatement(node *ast.EnumStatement, env *objects.Environment) objects.Object {
+	enum := &objects.Enum{Name: node.Name, Fields: make(map[string][]string)}
+	for _, variant := range node.Variants {
+		enum.Variants = append(enum.Variants, variant.Name)
+		fields := []string{}
+		for _, field := range variant.Fields {
+			fields = append(fields, field.Ident)
+		}
+		enum.Fields[variant.Name] = fields
+	}
+	env.Set(node.Name, enum)
+	return nil
+}
 
+// a variant without fields is a value, the others are built by calling them
+func variant(enum *objects.Enum, name string) objects.Object {
+	fields := enum.Fields[name]
+	if len(fields) == 0 {
+		return &objects.EnumValue{Enum: enum, Variant: name}
+	}
+	return &objects.Builtin{Fn: func(args ...objects.Object) objects.Object {
+		if len(args) != len(fields) {
+			return objects.NewErrorKind(objects.ARGUMENT_ERROR, "%s.%s expects %s, got %d", enum.Name, name, objects.Arguments(len(fields)), len(args))
+		}
+		for i := range args {
+			if args[i] == nil {
+				args[i] = &objects.Null{}
+			}
+		}
+		return &objects.EnumValue{Enum: enum, Variant: name, Values: args}
+	}}
+}
+
+// runs the body of the first arm whose pattern fits and whose guard holds,
+// names bound by the pattern are only visible in that arm
+func (e *Evaluator) evalMatchExpression(node *ast.MatchExpression, env *objects.Environment) objects.Object {
+	subject := e.Eval(node.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+	if subject == nil {
+		subject = &objects.Null{}
+	}
+
+	for _, arm := range node.Arms {
+		armEnv := objects.NewEnclosedEnvironment(env)
+		matched, err := e.matchPattern(arm.Pattern, subject, armEnv)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if arm.Guard != nil {
+			guard := e.Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			boolean, ok := guard.(*objects.Boolean)
+			if !ok {
+				return objects.NewErrorKind(objects.TYPE_ERROR, "match guard must be a boolean, got %s", typeOf(guard))
+			}
+			if !boolean.Value {
+				continue
+			}
+		}
+		return e.Eval(arm.Body, armEnv)
+	}
+	return withPosition(objects.NewErrorKind(objects.VALUE_ERROR, "no match arm for %s", subject.Inspect()), &node.Token)
+}
+
+// reports whether the value fits the pattern, binding the names in it to the
+// parts of the value they stand for
+func (e *Evaluator) matchPattern(pattern ast.Pattern, value objects.Object, env *objects.Environment) (bool, objects.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+	case *ast.BindingPattern:
+		env.Set(pattern.Ident.Ident, value)
+		return true, nil
+	case *ast.LiteralPattern:
+		literal := e.Eval(pattern.Value, env)
+		if isError(literal) {
+			return false, literal
+		}
+		return objects.Equal(value, literal), nil
+	case *ast.RangePattern:
+		low := e.Eval(pattern.Low, env)
+		if isError(low) {
+			return false, low
+		}
+		high := e.Eval(pattern.High, env)
+		if isError(high) {
+			return false, high
+		}
+		return inRange(value, low, high), nil
+	case *ast.VariantPattern:
+		evaluated := e.Eval(&pattern.Enum, env)
+		if isError(evaluated) {
+			return false, evaluated
+		}
+		enum, ok := evaluated.(*objects.Enum)
+		if !ok {
+			return false, withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "%s is not an enum", pattern.Enum.Ident), &pattern.Enum.Token)
+		}
+		name := pattern.Variant.Ident
+		if !enum.HasVariant(name) {
+			return false, withPosition(objects.NewErrorKind(objects.NAME_ERROR, "%s has no variant %s", enum.Name, name), &pattern.Variant.Token)
+		}
+		if fields := enum.Fields[name]; len(pattern.Fields) > 0 && len(pattern.Fields) != len(fields) {
+			return false, withPosition(objects.NewErrorKind(objects.ARGUMENT_ERROR, "%s.%s has %d fields, the pattern has %d",
+				enum.Name, name, len(fields), len(pattern.Fields)), &pattern.Variant.Token)
+		}
+		instance, ok := value.(*objects.EnumValue)
+		if !ok || instance.Enum != enum || instance.Variant != name {
+			return false, nil
+		}
+		return e.matchAll(pattern.Fields, instance.Values, env)
+	case *ast.ArrayPattern:
+		array, ok := value.(*objects.Array)
+		if !ok {
+			return false, nil
+		}
+		n := len(pattern.Elements)
+		if len(array.Elements) < n || pattern.Rest == nil && len(array.Elements) != n {
+			return false, nil
+		}
+		if matched, err := e.matchAll(pattern.Elements, array.Elements[:n], env); !matched || err != nil {
+			return matched, err
+		}
+		if pattern.Rest != nil {
+			rest := append([]objects.Object{}, array.Elements[n:]...)
+			env.Set(pattern.Rest.Ident, &objects.Array{Elements: rest})
+		}
+		return true, nil
+	case *ast.HashPattern:
+		hash, ok := value.(*objects.Hash)
+		if !ok {
+			return false, nil
+		}
+		for i, keyNode := range pattern.Keys {
+			key := e.Eval(keyNode, env)
+			if isError(key) {
+				return false, key
+			}
+			hashable, ok := key.(objects.Hashable)
+			if !ok {
+				return false, objects.NewErrorKind(objects.TYPE_ERROR, "unusable as hash key: %s", typeOf(key))
+			}
+			pair, ok := hash.Pairs[hashable.HashKey()]
+			if !ok {
+				return false, nil
+			}
+			if matched, err := e.matchPattern(pattern.Values[i], pair.Value, env); !matched || err != nil {
+				return matched, err
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (e *Evaluator) matchAll(patterns []ast.Pattern, values []objects.Object, env *objects.Environment) (bool, objects.Object) {
+	for i, pattern := range patterns {
+		if matched, err := e.matchPattern(pattern, values[i], env); !matched || err != nil {
+			return matched, err
+		}
+	}
+	return true, nil
+}
+
+// reports whether low <= value <= high, for numbers and strings
+func inRange(value, low, high objects.Object) bool {
+	if value, ok := value.(*objects.String); ok {
+		low, lowOk := low.(*objects.String)
+		high, highOk := high.(*objects.String)
+		return lowOk && highOk && low.Value <= value.Value && value.Value <= high.Value
+	}
+	v, ok := objects.Number(value)
+	l, lowOk := objects.Number(low)
+	h, highOk := objects.Number(high)
+	return ok && lowOk && highOk && l <= v && v <= h
+}
+
+func (e *Evaluator) evalFieldAssignment(node *ast.FieldAssignment, env *objects.Environment) objects.Object {
+	left := e.Eval(node.Target.Left, env)
+	if isError(left) {
+		return left
+	}
+	value := e.Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+	return withPosition(setField(left, node.Target.Member.Ident, value), &node.Target.Member.Token)
+}
+
+func setField(obj objects.Object, name string, value objects.Object) objects.Object {
+	instance, ok := obj.(*objects.Struct)
+	if !ok {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "cannot set field %s of %s", name, typeOf(obj))
+	}
+	if !instance.Def.HasField(name) {
+		return objects.NewErrorKind(objects.NAME_ERROR, "%s has no field %s", instance.Def.Name, name)
+	}
+	if value == nil {
+		value = &objects.Null{}
+	}
+	instance.Fields[name] = value
+	return nil
+}
+
+func (e *Evaluator) evalCallExpression(node *ast.CallExpression, env *objects.Environment) objects.Object {
+	var function objects.Object
 	var args []objects.Object
 
+	// calling a method passes the struct as its receiver
+	if method, ok := node.Function.(*ast.MemberExpression); ok {
+		left := e.Eval(method.Left, env)
+		if isError(left) {
+			return left
+		}
+		if instance, ok := left.(*objects.Struct); ok && !instance.Def.HasField(method.Member.Ident) {
+			args = append(args, instance)
+		}
+		function = withPosition(e.member(left, method.Member.Ident), &method.Member.Token)
+	} else {
+		function = e.Eval(node.Function, env)
+	}
+	if isError(function) {
+		return function
+	}
+
+	var named []namedArgument
 	for _, arg := range node.Arguments {
-		args = append(args, e.Eval(arg, env))
+		switch arg := arg.(type) {
+		case *ast.NamedArgument:
+			evaluated := e.Eval(arg.Value, env)
+			if isError(evaluated) {
+				return evaluated
+			}
+			named = append(named, namedArgument{name: arg.Name.Ident, value: evaluated, token: &arg.Name.Token})
+		case *ast.SpreadExpression:
+			evaluated := e.Eval(arg.Value, env)
+			if isError(evaluated) {
+				return evaluated
+			}
+			array, ok := evaluated.(*objects.Array)
+			if !ok {
+				return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "can only spread an array, got %s", typeOf(evaluated)), &arg.Token)
+			}
+			args = append(args, array.Elements...)
+		default:
+			evaluated := e.Eval(arg, env)
+			if isError(evaluated) {
+				return evaluated
+			}
+			args = append(args, evaluated)
+		}
 	}
 
-	return e.applyFunction(function, args, env)
+	return withPosition(e.applyFunction(function, args, named, env), start(node.Function))
 }
 
-func (e *Evaluator) applyFunction(fn objects.Object, args []objects.Object, env *objects.Environment) objects.Object {
+// calls a function with the global environment, used by builtins that take
+// callbacks
+func (e *Evaluator) Call(fn objects.Object, args ...objects.Object) objects.Object {
+	return e.applyFunction(fn, args, nil, e.Env)
+}
+
+func (e *Evaluator) Allows(capability string) bool {
+	return capability == "" || e.Capabilities[capability]
+}
+
+// seeds the generator so the program draws the same numbers on every run
+func (e *Evaluator) Seed(seed int64) {
+	e.Rand = rand.New(rand.NewSource(seed))
+}
+
+func (e *Evaluator) Random() *rand.Rand {
+	if e.Rand == nil {
+		e.Seed(time.Now().UnixNano())
+	}
+	return e.Rand
+}
+
+func (e *Evaluator) Output() io.Writer {
+	if e.Stdout == nil {
+		return os.Stdout
+	}
+	return e.Stdout
+}
+
+func (e *Evaluator) ErrorOutput() io.Writer {
+	if e.Stderr == nil {
+		return os.Stderr
+	}
+	return e.Stderr
+}
+
+func (e *Evaluator) ScriptArgs() []string {
+	return e.Args
+}
+
+func (e *Evaluator) ReadLine(prompt string) (string, error) {
+	if e.Stdin == nil {
+		e.Stdin = lineeditor.New(os.Stdin, e.Output())
+	}
+	return e.Stdin.ReadLine(prompt)
+}
+
+func (e *Evaluator) applyFunction(fn objects.Object, args []objects.Object, named []namedArgument, env *objects.Environment) objects.Object {
 	switch fn := fn.(type) {
 
 	case *objects.Function:
-		extendedEnv := objects.NewEnclosedEnvironment(env)
+		values, err := bindFunction(fn, args, named)
+		if err != nil {
+			return err
+		}
+		extendedEnv := objects.NewEnclosedEnvironment(&fn.Env)
 		for i, param := range fn.Parameters {
-			extendedEnv.Set(param.Ident, args[i])
+			value := values[i]
+			if value == omitted {
+				// defaults are evaluated on each call and can use the
+				// parameters before them
+				value = e.Eval(param.Default, extendedEnv)
+				if isError(value) {
+					return value
+				}
+			}
+			if err := checkArgument(fn, param, value); err != nil {
+				return err
+			}
+			extendedEnv.Set(param.Ident.Ident, value)
 		}
+		e.Stack = append(e.Stack, &Frame{Name: fn.Name, Line: fn.Body.StartLine, Env: extendedEnv})
+		defer e.popFrame()
 		evaluated := e.Eval(&fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		return orNull(unwrapReturnValue(evaluated))
 
 	case *objects.Builtin:
-		obj := fn.Fn(args...)
-		return obj
+		if len(named) > 0 {
+			return withPosition(objects.NewErrorKind(objects.ARGUMENT_ERROR, "builtin functions take no named arguments"), named[0].token)
+		}
+		var obj objects.Object
+		if fn.Native != nil {
+			obj = fn.Native(e, args...)
+		} else {
+			obj = fn.Fn(args...)
+		}
+		return orNull(obj)
+
+	case *objects.StructType:
+		values, err := bind(&objects.Signature{Name: fn.Name, Params: fn.Fields}, args, named)
+		if err != nil {
+			return err
+		}
+		return newStruct(fn, values)
 	}
 
-	return nil
+	return objects.NewErrorKind(objects.TYPE_ERROR, "not a function: %s", fn.Type())
+}
+
+// calls always give a value, a function that returns nothing gives null
+func orNull(obj objects.Object) objects.Object {
+	if obj == nil {
+		return &objects.Null{}
+	}
+	return obj
 }
 
 func unwrapReturnValue(obj objects.Object) objects.Object {
@@ -214,7 +853,23 @@ func unwrapReturnValue(obj objects.Object) objects.Object {
 func (e *Evaluator) evalInfixExpression(node *ast.InfixExpression, env *objects.Environment) objects.Object {
 
 	leftVal := e.Eval(node.Left, env)
+	if isError(leftVal) {
+		return leftVal
+	}
 	rightVal := e.Eval(node.Right, env)
+	if isError(rightVal) {
+		return rightVal
+	}
+	if leftVal == nil || rightVal == nil {
+		return objects.NewErrorKind(objects.TYPE_ERROR, "operand of %s has no value", node.Operator.Literal)
+	}
+
+	// an integer mixed with a float is worked on as a float
+	if leftVal.Type() == objects.INT && rightVal.Type() == objects.FLOAT {
+		leftVal = &objects.Float{Value: float64(leftVal.(*objects.Integer).Value)}
+	} else if leftVal.Type() == objects.FLOAT && rightVal.Type() == objects.INT {
+		rightVal = &objects.Float{Value: float64(rightVal.(*objects.Integer).Value)}
+	}
 
 	//could take in operator instead of whole node
 	if leftVal.Type() == objects.INT && rightVal.Type() == objects.INT || leftVal.Type() == objects.FLOAT && rightVal.Type() == objects.FLOAT {
@@ -223,50 +878,40 @@ func (e *Evaluator) evalInfixExpression(node *ast.InfixExpression, env *objects.
 		return e.evalStringInfixExpression(node, leftVal, rightVal)
 	} else if leftVal.Type() == objects.BOOLEAN && rightVal.Type() == objects.BOOLEAN {
 		return e.evalBooleanInfixExpression(node, leftVal, rightVal)
+	} else if temporal(leftVal) || temporal(rightVal) {
+		return withPosition(evalTimeInfixExpression(node, leftVal, rightVal), node.Operator)
+	} else if comparable(leftVal) && comparable(rightVal) && node.Operator.Type == token.EQUAL {
+		return &objects.Boolean{Value: objects.Equal(leftVal, rightVal)}
+	} else if comparable(leftVal) && comparable(rightVal) && node.Operator.Type == token.NOTEQUAL {
+		return &objects.Boolean{Value: !objects.Equal(leftVal, rightVal)}
 	} else {
-		return NewError("type mismatch: %s %s %s", leftVal.Type(), node.Operator, rightVal.Type())
+		return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: %s %s %s", leftVal.Type(), node.Operator.Literal, rightVal.Type()), node.Operator)
 	}
 }
 
 func (e *Evaluator) evalNumericInfixExpression(node *ast.InfixExpression, left, right objects.Object, env *objects.Environment) objects.Object {
-
-	var leftVal float64
-	var rightVal float64
-
 	if left.Type() == objects.INT {
-		leftVal = float64(left.(*objects.Integer).Value)
-		rightVal = float64(right.(*objects.Integer).Value)
-	} else {
-		leftVal = left.(*objects.Float).Value
-		rightVal = right.(*objects.Float).Value
+		return e.evalIntegerInfixExpression(node, left.(*objects.Integer).Value, right.(*objects.Integer).Value, env)
 	}
 
+	leftVal := left.(*objects.Float).Value
+	rightVal := right.(*objects.Float).Value
+
 	switch node.Operator.Literal {
 	case "+":
-		if left.Type() == objects.INT {
-			return &objects.Integer{Value: int64(leftVal + rightVal)}
-		}
 		return &objects.Float{Value: leftVal + rightVal}
 	case "-":
-		if left.Type() == objects.INT {
-			return &objects.Integer{Value: int64(leftVal - rightVal)}
-		}
 		return &objects.Float{Value: leftVal - rightVal}
 	case "*":
-		if left.Type() == objects.INT {
-			return &objects.Integer{Value: int64(leftVal * rightVal)}
-		}
 		return &objects.Float{Value: leftVal * rightVal}
 	case "/":
-		if left.Type() == objects.INT {
-			return &objects.Integer{Value: int64(leftVal / rightVal)}
-		}
 		return &objects.Float{Value: leftVal / rightVal}
 	case "%":
-		if left.Type() == objects.INT {
-			return &objects.Integer{Value: int64(leftVal) % int64(rightVal)}
-		}
-		return &objects.Float{Value: leftVal / rightVal}
+		return &objects.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &objects.Float{Value: math.Pow(leftVal, rightVal)}
+	case "&", "|", "^", "<<", ">>":
+		return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), node.Operator.Literal, right.Type()), node.Operator)
 	case "<":
 		return &objects.Boolean{Value: leftVal < rightVal}
 	case ">":
@@ -280,20 +925,144 @@ func (e *Evaluator) evalNumericInfixExpression(node *ast.InfixExpression, left,
 	case ">=":
 		return &objects.Boolean{Value: leftVal >= rightVal}
 	case "+=":
-		if left.Type() == objects.INT {
-			env.Set(node.Left.(*ast.IdentiferLiteral).Ident, &objects.Integer{Value: int64(leftVal) + int64(rightVal)})
-		} else {
-			return NewError("Increment has to be an integer")
-		}
+		return objects.NewErrorKind(objects.TYPE_ERROR, "Increment has to be an integer")
 	case "-=":
-		if left.Type() == objects.INT {
-			env.Set(node.Left.(*ast.IdentiferLiteral).Ident, &objects.Integer{Value: int64(leftVal) - int64(rightVal)})
+		return objects.NewErrorKind(objects.TYPE_ERROR, "decrement has to be an integer")
+	}
+	return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), node.Operator.Literal, right.Type()), node.Operator)
+}
+
+// integers are worked on as int64 so large values stay exact, results that
+// do not fit are an error rather than wrapping around
+func (e *Evaluator) evalIntegerInfixExpression(node *ast.InfixExpression, left, right int64, env *objects.Environment) objects.Object {
+	switch node.Operator.Literal {
+	case "+", "-", "*", "/", "%", "**":
+		return withPosition(integerArithmetic(node.Operator.Literal, left, right), node.Operator)
+	case "&", "|", "^", "<<", ">>":
+		return withPosition(evalBitwise(node.Operator.Literal, left, right), node.Operator)
+	case "<":
+		return &objects.Boolean{Value: left < right}
+	case ">":
+		return &objects.Boolean{Value: left > right}
+	case "==":
+		return &objects.Boolean{Value: left == right}
+	case "!=":
+		return &objects.Boolean{Value: left != right}
+	case "<=":
+		return &objects.Boolean{Value: left <= right}
+	case ">=":
+		return &objects.Boolean{Value: left >= right}
+	case "+=", "-=":
+		result := integerArithmetic(node.Operator.Literal[:1], left, right)
+		if isError(result) {
+			return withPosition(result, node.Operator)
+		}
+		return e.assign(node.Left, result, env)
+	}
+	return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: INTEGER %s INTEGER", node.Operator.Literal), node.Operator)
+}
+
+func integerArithmetic(operator string, left, right int64) objects.Object {
+	var result int64
+	switch operator {
+	case "+":
+		result = left + right
+		if (result > left) != (right > 0) {
+			return objects.IntegerOverflow(left, operator, right)
+		}
+	case "-":
+		result = left - right
+		if (result < left) != (right > 0) {
+			return objects.IntegerOverflow(left, operator, right)
+		}
+	case "*":
+		var ok bool
+		if result, ok = objects.Multiply(left, right); !ok {
+			return objects.IntegerOverflow(left, operator, right)
+		}
+	case "/", "%":
+		if right == 0 {
+			return objects.NewErrorKind(objects.VALUE_ERROR, "division by zero")
+		}
+		if left == math.MinInt64 && right == -1 {
+			if operator == "%" {
+				return &objects.Integer{Value: 0}
+			}
+			return objects.IntegerOverflow(left, operator, right)
+		}
+		if operator == "/" {
+			result = left / right
 		} else {
-			return NewError("decrement has to be an integer")
+			result = left % right
 		}
+	case "**":
+		return integerPower(left, right)
 	}
-	//error here
-	return nil
+	return &objects.Integer{Value: result}
+}
+
+// an exact integer for exponents from zero up, a float for negative ones
+func integerPower(base, exponent int64) objects.Object {
+	if exponent < 0 {
+		return &objects.Float{Value: math.Pow(float64(base), float64(exponent))}
+	}
+	result := int64(1)
+	square := base
+	for n := exponent; n > 0; n >>= 1 {
+		var ok bool
+		if n&1 == 1 {
+			if result, ok = objects.Multiply(result, square); !ok {
+				return objects.IntegerOverflow(base, "**", exponent)
+			}
+		}
+		if n > 1 {
+			if square, ok = objects.Multiply(square, square); !ok {
+				return objects.IntegerOverflow(base, "**", exponent)
+			}
+		}
+	}
+	return &objects.Integer{Value: result}
+}
+
+func evalBitwise(operator string, left, right int64) objects.Object {
+	switch operator {
+	case "&":
+		return &objects.Integer{Value: left & right}
+	case "|":
+		return &objects.Integer{Value: left | right}
+	case "^":
+		return &objects.Integer{Value: left ^ right}
+	}
+	if right < 0 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "negative shift count %d", right)
+	}
+	if right >= 64 {
+		return objects.NewErrorKind(objects.VALUE_ERROR, "shift count %d is 64 or more", right)
+	}
+	if operator == "<<" {
+		result := left << uint64(right)
+		if result>>uint64(right) != left {
+			return objects.IntegerOverflow(left, operator, right)
+		}
+		return &objects.Integer{Value: result}
+	}
+	return &objects.Integer{Value: left >> uint64(right)}
+}
+
+// stores the result of += and -= back where the left side came from
+func (e *Evaluator) assign(target ast.AstExpression, value objects.Object, env *objects.Environment) objects.Object {
+	switch target := target.(type) {
+	case *ast.IdentiferLiteral:
+		env.Set(target.Ident, value)
+		return nil
+	case *ast.MemberExpression:
+		left := e.Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		return withPosition(setField(left, target.Member.Ident, value), &target.Member.Token)
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "cannot assign to %s", target.String())
 }
 
 func (e *Evaluator) evalStringInfixExpression(node *ast.InfixExpression, left, right objects.Object) objects.Object {
@@ -308,8 +1077,7 @@ func (e *Evaluator) evalStringInfixExpression(node *ast.InfixExpression, left, r
 	case "!=":
 		return &objects.Boolean{Value: leftVal != rightVal}
 	}
-	//return error
-	return nil
+	return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), node.Operator.Literal, right.Type()), node.Operator)
 }
 
 func (e *Evaluator) evalBooleanInfixExpression(node *ast.InfixExpression, left, right objects.Object) objects.Object {
@@ -322,34 +1090,54 @@ func (e *Evaluator) evalBooleanInfixExpression(node *ast.InfixExpression, left,
 	case "!=":
 		return &objects.Boolean{Value: leftVal != rightVal}
 	default:
-		return NewError("operator not supported for boolean expression")
+		return objects.NewErrorKind(objects.TYPE_ERROR, "operator not supported for boolean expression")
 	}
 }
 
 func (e *Evaluator) evalPrefixExpression(node *ast.PrefixExpression, env *objects.Environment) objects.Object {
 	expr := e.Eval(node.Expression, env)
+	if isError(expr) {
+		return expr
+	}
 	//minus prefix and bang prefix
 	if node.Prefix.Type == token.MINUS {
 
 		if expr, ok := expr.(*objects.Integer); ok {
 			//convert postive to negative
-			return &objects.Integer{Value: -expr.Value}
+			value, ok := objects.Negate(expr.Value)
+			if !ok {
+				return withPosition(objects.NewErrorKind(objects.VALUE_ERROR, "integer overflow: -(%d)", expr.Value), node.Prefix)
+			}
+			return &objects.Integer{Value: value}
 		}
 
 		if expr, ok := expr.(*objects.Float); ok {
 			return &objects.Float{Value: -expr.Value}
 		}
 
-		return NewError("Expected a float or an integer")
+		if expr, ok := expr.(*objects.Duration); ok {
+			return &objects.Duration{Value: -expr.Value}
+		}
+
+		return objects.NewErrorKind(objects.TYPE_ERROR, "Expected a float or an integer")
+
+	} else if node.Prefix.Type == token.TILDE {
+
+		if expr, ok := expr.(*objects.Integer); ok {
+			return &objects.Integer{Value: ^expr.Value}
+		}
+
+		return objects.NewErrorKind(objects.TYPE_ERROR, "Expected an integer")
 
 	} else if node.Prefix.Type == token.BANG {
 
 		if expr, ok := expr.(*objects.Boolean); ok {
 			return &objects.Boolean{Value: !expr.Value}
 		}
+
+		return objects.NewErrorKind(objects.TYPE_ERROR, "Expected a boolean, got %s", typeOf(expr))
 	}
-	//error
-	return nil
+	return objects.NewErrorKind(objects.TYPE_ERROR, "unknown operator: %s%s", node.Prefix.Literal, typeOf(expr))
 }
 
 func (e *Evaluator) evalIntegerLiteral(node *ast.IntegerLiteral) *objects.Integer {
@@ -381,22 +1169,28 @@ func (e *Evaluator) evalIdentiferLiteral(node *ast.IdentiferLiteral, env *object
 		return val
 	} else if val, ok := builtins.BuiltIns[node.Ident]; ok {
 		return &val
+	} else if module, ok := builtins.Modules[node.Ident]; ok {
+		return module
 	}
-	//error
-	return nil
+	return withPosition(objects.NewErrorKind(objects.NAME_ERROR, "identifier not found: %s", node.Ident), &node.Token)
 }
 
 func (e *Evaluator) evalArrayLiteral(node *ast.ArrayLiteral, env *objects.Environment) objects.Object {
 	var elements []objects.Object
 	for _, elem := range node.Elements {
-		elements = append(elements, e.Eval(elem, env))
+		value := e.Eval(elem, env)
+		if isError(value) {
+			return value
+		}
+		elements = append(elements, value)
 	}
 	return &objects.Array{Elements: elements}
 }
 
 func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *objects.Environment) objects.Object {
-	pairs := make(map[objects.HashKey]objects.HashPair)
-	for keyNode, valueNode := range node.Pairs {
+	hash := objects.NewHash()
+	for _, keyNode := range node.Keys {
+		valueNode := node.Pairs[keyNode]
 		key := e.Eval(keyNode, env)
 		if isError(key) {
 			return key
@@ -409,10 +1203,9 @@ func (e *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *objects.Environm
 		if isError(value) {
 			return value
 		}
-		hashed := hashKey.HashKey()
-		pairs[hashed] = objects.HashPair{Key: key, Value: value}
+		hash.Set(hashKey.HashKey(), objects.HashPair{Key: key, Value: value})
 	}
-	return &objects.Hash{Pairs: pairs}
+	return hash
 }
 
 func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, env *objects.Environment) objects.Object {
@@ -431,9 +1224,13 @@ func (e *Evaluator) evalIndexExpression(node *ast.IndexExpression, env *objects.
 		return e.evalArrayIndexExpression(left, index)
 	case left.Type() == objects.HASH:
 		return e.evalHashIndexExpression(left, index)
+	case left.Type() == objects.ERROR_VALUE && index.Type() == objects.STRING:
+		if field, ok := left.(*objects.ErrorValue).Field(index.(*objects.String).Value); ok {
+			return field
+		}
+		return objects.NewErrorKind(objects.NAME_ERROR, "error has no field %s", index.Inspect())
 	}
-	//error
-	return nil
+	return withPosition(objects.NewErrorKind(objects.TYPE_ERROR, "index operator not supported: %s[%s]", typeOf(left), typeOf(index)), start(node.Left))
 }
 
 func (e *Evaluator) evalArrayIndexExpression(array, index objects.Object) objects.Object {
@@ -451,8 +1248,7 @@ func (e *Evaluator) evalHashIndexExpression(hash, index objects.Object) objects.
 	hashObj := hash.(*objects.Hash)
 	key, ok := index.(objects.Hashable)
 	if !ok {
-		//error
-		return nil
+		return objects.NewErrorKind(objects.TYPE_ERROR, "unusable as hash key: %s", index.Type())
 	}
 	pair, ok := hashObj.Pairs[key.HashKey()]
 	if !ok {
@@ -464,7 +1260,48 @@ func (e *Evaluator) evalHashIndexExpression(hash, index objects.Object) objects.
 func NewError(format string, a ...interface{}) *objects.Error {
 	return &objects.Error{
 		Message: fmt.Sprintf(format, a...),
+		Kind:    objects.GENERIC_ERROR,
+	}
+}
+
+// records where an error happened unless an inner call already did
+func withPosition(obj objects.Object, tok *token.Token) objects.Object {
+	if err, ok := obj.(*objects.Error); ok && err.Line == 0 && tok != nil {
+		err.Line = tok.Line
+		err.Column = tok.Column
+	}
+	return obj
+}
+
+// the first token of an expression, if it is known
+func start(node ast.AstExpression) *token.Token {
+	switch node := node.(type) {
+	case *ast.IdentiferLiteral:
+		return &node.Token
+	case *ast.CallExpression:
+		return start(node.Function)
+	case *ast.IndexExpression:
+		return start(node.Left)
+	case *ast.InfixExpression:
+		return start(node.Left)
+	case *ast.PrefixExpression:
+		return node.Prefix
+	case *ast.MemberExpression:
+		return start(node.Left)
+	}
+	return nil
+}
+
+// values that can only be compared with == and !=
+func comparable(obj objects.Object) bool {
+	return obj.Type() == objects.STRUCT || obj.Type() == objects.ENUM_VALUE || obj.Type() == objects.REGEX
+}
+
+func typeOf(obj objects.Object) objects.ObjectType {
+	if obj == nil {
+		return objects.NULL
 	}
+	return obj.Type()
 }
 
 func isError(obj objects.Object) bool {
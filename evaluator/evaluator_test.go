@@ -0,0 +1,285 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/lexer"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+// runs the program and returns the value of its last statement
+func testEval(t *testing.T, input string) objects.Object {
+	t.Helper()
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(l.Errors()) > 0 || len(p.Errors()) > 0 {
+		t.Fatalf("parsing %q failed: %v %v", input, l.Errors(), p.Errors())
+	}
+	e := New()
+	return e.Eval(program, e.Env)
+}
+
+type evalTest struct {
+	input string
+	// the inspected result, or part of the message when kind is set
+	want string
+	kind string
+}
+
+func runEvalTests(t *testing.T, tests []evalTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := testEval(t, tt.input)
+			err, isErr := got.(*objects.Error)
+			if tt.kind != "" {
+				if !isErr || err.Kind != tt.kind || !strings.Contains(err.Message, tt.want) {
+					t.Fatalf("Eval() = %v, want %s containing %q", got, tt.kind, tt.want)
+				}
+				return
+			}
+			if isErr {
+				t.Fatalf("Eval() error %s: %s", err.Kind, err.Message)
+			}
+			if got == nil || got.Inspect() != tt.want {
+				t.Errorf("Eval() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "9007199254740993 + 0", want: "9007199254740993"},
+		{input: "9223372036854775807 - 1", want: "9223372036854775806"},
+		{input: "9007199254740993 == 9007199254740992", want: "false"},
+		{input: "3037000499 * 3037000499", want: "9223372030926249001"},
+		{input: "7 / 2", want: "3"},
+		{input: "-7 / 2", want: "-3"},
+		{input: "-7 % 3", want: "-1"},
+		{input: "var x = 1\nx += 2\nx", want: "3"},
+		{input: "9223372036854775807 + 1", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "-9223372036854775807 - 2", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "4294967296 * 4294967296", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "var x = 9223372036854775807\nx += 1", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "var x = -9223372036854775807 - 1\nvar y = -x", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "1 / 0", kind: objects.VALUE_ERROR, want: "division by zero"},
+		{input: "1 % 0", kind: objects.VALUE_ERROR, want: "division by zero"},
+	})
+}
+
+func TestPowerAndBitwise(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "2 ** 10", want: "1024"},
+		{input: "2 ** 3 ** 2", want: "512"},
+		{input: "-2 ** 2", want: "-4"},
+		{input: "(-2) ** 3", want: "-8"},
+		{input: "2 ** 62", want: "4611686018427387904"},
+		{input: "(-2) ** 63", want: "-9223372036854775808"},
+		{input: "2 ** -1", want: "0.5"},
+		{input: "2.0 ** 0.5", want: "1.4142135623730951"},
+		{input: "12 & 10", want: "8"},
+		{input: "12 | 10", want: "14"},
+		{input: "12 ^ 10", want: "6"},
+		{input: "5.5 % 2.0", want: "1.5"},
+		{input: "-5.5 % 2.0", want: "-1.5"},
+		{input: "6 & 3 | 8 ^ 1", want: "11"},
+		{input: "1 << 4 | 1", want: "17"},
+		{input: "-16 >> 2", want: "-4"},
+		{input: "~5", want: "-6"},
+		{input: "1 << 62", want: "4611686018427387904"},
+		{input: "-1 >> 63", want: "-1"},
+		{input: "2 ** 64", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "10 ** 19", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "1 << 64", kind: objects.VALUE_ERROR, want: "64 or more"},
+		{input: "1 >> 64", kind: objects.VALUE_ERROR, want: "64 or more"},
+		{input: "1 << 63", kind: objects.VALUE_ERROR, want: "integer overflow"},
+		{input: "1 << -1", kind: objects.VALUE_ERROR, want: "negative shift count"},
+		{input: "1.0 & 2.0", kind: objects.TYPE_ERROR, want: "type mismatch"},
+	})
+}
+
+func TestSeed(t *testing.T) {
+	draw := func(seed int64) []int {
+		e := New()
+		e.Seed(seed)
+		var out []int
+		for i := 0; i < 3; i++ {
+			out = append(out, e.Random().Intn(1000))
+		}
+		return out
+	}
+	first, second := draw(42), draw(42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("seed 42 drew %v then %v", first, second)
+		}
+	}
+	if e := New(); e.Random() == nil || e.Random() != e.Random() {
+		t.Errorf("Random() without a seed should make one generator and keep it")
+	}
+}
+
+func TestErrorsPassOn(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "var a = [int(\"abc\")]", kind: objects.VALUE_ERROR, want: "could not convert"},
+		{input: "[1, 2 + \"a\"]", kind: objects.TYPE_ERROR, want: "type mismatch: INTEGER + STRING"},
+		{input: "!5", kind: objects.TYPE_ERROR, want: "Expected a boolean, got INTEGER"},
+		{input: "\"a\"[0]", kind: objects.TYPE_ERROR, want: "index operator not supported: STRING[INTEGER]"},
+		{input: "{1: 2}[[1]]", kind: objects.TYPE_ERROR, want: "unusable as hash key: ARRAY"},
+		{input: "\"a\" - \"b\"", kind: objects.TYPE_ERROR, want: "type mismatch: STRING - STRING"},
+		{input: "\"a\" < \"b\"", kind: objects.TYPE_ERROR, want: "type mismatch: STRING < STRING"},
+		{input: "var caught = \"\"\ntry {\n    var a = [int(\"abc\")]\n} catch (e) {\n    caught = e.kind\n}\ncaught", want: "ValueError"},
+		{input: "var caught = \"\"\ntry {\n    println(!5)\n} catch (e) {\n    caught = e.kind\n}\ncaught", want: "TypeError"},
+	})
+}
+
+func TestNullResults(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "func f() {}\nvar y = f()\ny", want: "null"},
+		{input: "func f() {}\nvar y = 1\ny = f()\ny", want: "null"},
+		{input: "var x = print()\nx", want: "null"},
+		{input: "func f() {}\nis_null(f())", want: "true"},
+	})
+}
+
+func TestMixedArithmetic(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "1.5 * 2", want: "3"},
+		{input: "2 * 1.5", want: "3"},
+		{input: "7 / 2.0", want: "3.5"},
+		{input: "7.5 % 2", want: "1.5"},
+		{input: "2 ** 0.5", want: "1.4142135623730951"},
+		{input: "1 == 1.0", want: "true"},
+		{input: "2 > 1.5", want: "true"},
+		{input: "var r = 2\n3.0 * r * r", want: "12"},
+		{input: "1 & 2.0", kind: objects.TYPE_ERROR, want: "type mismatch: FLOAT & FLOAT"},
+		{input: "1.5 + \"a\"", kind: objects.TYPE_ERROR, want: "type mismatch: FLOAT + STRING"},
+	})
+}
+
+func TestTry(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: "func f() {\n    try {\n        return 1 + \"a\"\n    } catch (e) {\n        return e[\"kind\"]\n    }\n}\nf()", want: "TypeError"},
+		{input: "var log = []\ntry {\n    throw \"boom\"\n} catch {\n    append(log, \"catch\")\n} finally {\n    append(log, \"finally\")\n}\nlog",
+			want: "[catch, finally]"},
+		{input: "var log = []\ntry {\n    append(log, \"try\")\n} finally {\n    append(log, \"finally\")\n}\nlog", want: "[try, finally]"},
+		{input: "func h() {\n    try {\n        throw error(\"bad\", \"ValueError\")\n    } catch (e) {\n        throw e\n    }\n}\nvar got = \"\"\ntry {\n    h()\n} catch (e) {\n    got = e.kind + \": \" + e.message\n}\ngot",
+			want: "ValueError: bad"},
+		{input: "func k() {\n    try {\n        return 1\n    } finally {\n        return 2\n    }\n}\nk()", want: "2"},
+		{input: "var kind = \"\"\ntry { len(1, 2) } catch (e) { kind = e[\"kind\"] }\nkind", want: "ArgumentError"},
+		{input: "throw \"boom\"", kind: objects.GENERIC_ERROR, want: "boom"},
+		{input: "throw error(\"bad\", \"ValueError\")", kind: objects.VALUE_ERROR, want: "bad"},
+		{input: "throw 1", kind: objects.TYPE_ERROR, want: "can only throw a string or an error, got INTEGER"},
+		{input: "try {\n    throw \"a\"\n} catch (e) {\n    throw \"b\"\n}", kind: objects.GENERIC_ERROR, want: "b"},
+	})
+}
+
+const point = `struct Point {
+    x, y
+
+    func norm(self) {
+        return self.x * self.x + self.y * self.y
+    }
+
+    func moved(self, dx) {
+        return Point(self.x + dx, self.y)
+    }
+}
+var p = Point(3, 4)
+`
+
+func TestStructs(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: point + "p", want: "Point{x: 3, y: 4}"},
+		{input: point + "p.norm()", want: "25"},
+		{input: point + "Point.norm(p)", want: "25"},
+		{input: point + "p.x = 1\np.y += 2\np", want: "Point{x: 1, y: 6}"},
+		{input: point + "p == Point(3, 4)", want: "true"},
+		{input: point + "p != p.moved(1)", want: "true"},
+		{input: point + "p.moved(10).x", want: "13"},
+		{input: point + "Point(y = 1, x = 2)", want: "Point{x: 2, y: 1}"},
+		{input: "struct Node { value, next }\nvar a = Node(1, 0)\na.next = [a]\na", want: "Node{value: 1, next: [Node{...}]}"},
+		{input: "struct Node { value, next }\nvar a = Node(1, 0)\na.next = [a]\nvar b = Node(1, 0)\nb.next = [b]\na == b", want: "true"},
+		{input: point + "p.z = 1", kind: objects.NAME_ERROR, want: "Point has no field z"},
+		{input: point + "p.z", kind: objects.NAME_ERROR, want: "Point has no field z"},
+		{input: point + "Point(1)", kind: objects.ARGUMENT_ERROR, want: "Point expects 2 arguments, got 1"},
+		{input: point + "p.norm(1)", kind: objects.ARGUMENT_ERROR, want: ""},
+	})
+}
+
+const shape = `enum Shape {
+    Circle(r),
+    Rect(w, h),
+    Empty
+}
+
+func area(s) {
+    return match s {
+        Shape.Circle(r) => 3 * r * r,
+        Shape.Rect(w, h) if w == h => w * w,
+        Shape.Rect(w, h) => w * h,
+        Shape.Empty => 0
+    }
+}
+
+func describe(x) {
+    return match x {
+        0 => "zero",
+        1..9 => "small",
+        "a".."z" => "letter",
+        [] => "empty",
+        [first, ...rest] => first + len(rest),
+        {"name": n} => "named " + n,
+        _ => "other"
+    }
+}
+`
+
+func TestMatch(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{input: shape + "area(Shape.Circle(2))", want: "12"},
+		{input: shape + "area(Shape.Rect(3, 3))", want: "9"},
+		{input: shape + "area(Shape.Rect(2, 3))", want: "6"},
+		{input: shape + "area(Shape.Empty)", want: "0"},
+		{input: shape + "Shape.Rect(1, 2).h", want: "2"},
+		{input: shape + "Shape.Circle(1) == Shape.Circle(1)", want: "true"},
+		{input: shape + "Shape.Circle(1) != Shape.Circle(2)", want: "true"},
+		{input: shape + "describe(0)", want: "zero"},
+		{input: shape + "describe(5)", want: "small"},
+		{input: shape + "describe(\"q\")", want: "letter"},
+		{input: shape + "describe([])", want: "empty"},
+		{input: shape + "describe([1, 2, 3])", want: "3"},
+		{input: shape + "describe({\"name\": \"bob\", \"age\": 3})", want: "named bob"},
+		{input: shape + "describe(10)", want: "other"},
+		{input: "match 5 {\n    1 => 1\n}", kind: objects.VALUE_ERROR, want: "no match arm for 5"},
+		{input: shape + "Shape.Circle()", kind: objects.ARGUMENT_ERROR, want: ""},
+	})
+}
+
+func TestParameters(t *testing.T) {
+	const f = "func f(a, b = 2, ...rest) {\n    return [a, b, rest]\n}\n"
+	runEvalTests(t, []evalTest{
+		{input: f + "f(1)", want: "[1, 2, []]"},
+		{input: f + "f(b = 3, a = 1)", want: "[1, 3, []]"},
+		{input: f + "f(...[1, 2, 3, 4])", want: "[1, 2, [3, 4]]"},
+		{input: "func g(a, b = a * 2) {\n    return b\n}\ng(4)", want: "8"},
+		{input: "func g(n: int) {\n    return n\n}\ng(\"1\")", kind: objects.TYPE_ERROR, want: "argument n of g must be int, got string"},
+		{input: "func g(a, b) {\n    return a\n}\ng(1)", kind: objects.ARGUMENT_ERROR, want: "g expects 2 arguments, got 1"},
+		{input: f + "f()", kind: objects.ARGUMENT_ERROR, want: ""},
+		{input: f + "f(1, c = 2)", kind: objects.ARGUMENT_ERROR, want: ""},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	const adder = "func adder(n) {\n    return x => x + n\n}\n"
+	runEvalTests(t, []evalTest{
+		{input: adder + "adder(2)(3)", want: "5"},
+		{input: adder + "var ops = {\"inc\": adder(1), \"double\": func(x) { return x * 2 }}\nops[\"double\"](ops[\"inc\"](1))", want: "4"},
+		{input: "var n = 1\nvar f = () => n\nn = 5\nf()", want: "5"},
+		{input: "var f = (a, ...rest) => len(rest)\nf(1, 2, 3)", want: "2"},
+	})
+}
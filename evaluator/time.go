@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/objects"
+)
+
+func temporal(obj objects.Object) bool {
+	return obj.Type() == objects.TIME || obj.Type() == objects.DURATION
+}
+
+// moves times by durations, subtracts times, scales durations by integers
+// and compares times or durations with each other
+func evalTimeInfixExpression(node *ast.InfixExpression, left, right objects.Object) objects.Object {
+	operator := node.Operator.Literal
+	switch left := left.(type) {
+	case *objects.Time:
+		switch right := right.(type) {
+		case *objects.Time:
+			if operator == "-" {
+				return &objects.Duration{Value: left.Value.Sub(right.Value)}
+			}
+			if result, ok := compare(operator, order(left.Value.Sub(right.Value), 0)); ok {
+				return result
+			}
+		case *objects.Duration:
+			switch operator {
+			case "+":
+				return &objects.Time{Value: left.Value.Add(right.Value)}
+			case "-":
+				return &objects.Time{Value: left.Value.Add(-right.Value)}
+			}
+		}
+	case *objects.Duration:
+		switch right := right.(type) {
+		case *objects.Duration:
+			switch operator {
+			case "+":
+				return &objects.Duration{Value: left.Value + right.Value}
+			case "-":
+				return &objects.Duration{Value: left.Value - right.Value}
+			case "/":
+				if right.Value == 0 {
+					return objects.NewErrorKind(objects.VALUE_ERROR, "division by zero")
+				}
+				return &objects.Float{Value: float64(left.Value) / float64(right.Value)}
+			}
+			if result, ok := compare(operator, order(left.Value, right.Value)); ok {
+				return result
+			}
+		case *objects.Time:
+			if operator == "+" {
+				return &objects.Time{Value: right.Value.Add(left.Value)}
+			}
+		case *objects.Integer:
+			switch operator {
+			case "*":
+				return &objects.Duration{Value: left.Value * time.Duration(right.Value)}
+			case "/":
+				if right.Value == 0 {
+					return objects.NewErrorKind(objects.VALUE_ERROR, "division by zero")
+				}
+				return &objects.Duration{Value: left.Value / time.Duration(right.Value)}
+			}
+		}
+	case *objects.Integer:
+		if right, ok := right.(*objects.Duration); ok && operator == "*" {
+			return &objects.Duration{Value: time.Duration(left.Value) * right.Value}
+		}
+	}
+	return objects.NewErrorKind(objects.TYPE_ERROR, "type mismatch: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// the result of a comparison operator given -1, 0 or 1 for how the operands
+// are ordered, false for operators that do not compare
+func compare(operator string, order int) (objects.Object, bool) {
+	var result bool
+	switch operator {
+	case "==":
+		result = order == 0
+	case "!=":
+		result = order != 0
+	case "<":
+		result = order < 0
+	case ">":
+		result = order > 0
+	case "<=":
+		result = order <= 0
+	case ">=":
+		result = order >= 0
+	default:
+		return nil, false
+	}
+	return &objects.Boolean{Value: result}, true
+}
+
+func order(a, b time.Duration) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,543 @@
+package format
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/parser"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+const INDENT = "    "
+
+type printer struct {
+	out      strings.Builder
+	indent   int
+	comments []token.Token
+	// index of the next comment to print
+	next int
+	// last source line printed, 0 at the start of a block
+	lastLine int
+}
+
+// formats source code, returning the lexer or parser errors if it is invalid
+func Source(src string) (string, error) {
+	program, err := parser.Parse(src)
+	if err != nil {
+		return "", err
+	}
+
+	return Format(program), nil
+}
+
+func Format(program *ast.Program) string {
+	p := &printer{comments: program.Comments}
+	p.statements(program.Statements, math.MaxInt)
+	return p.out.String()
+}
+
+// the signature of a function as it is written in the source,
+// func add(a: int, b = 1) -> int
+func Signature(name string, params []ast.Parameter, returnType *ast.TypeName) string {
+	p := &printer{}
+	p.write("func ")
+	p.write(name)
+	p.parameters(params, returnType)
+	return p.out.String()
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+// starts a new line for an item beginning at line, keeping at most one
+// blank line from the source
+func (p *printer) startLine(line int) {
+	if p.lastLine > 0 && line > p.lastLine+1 {
+		p.write("\n")
+	}
+	for i := 0; i < p.indent; i++ {
+		p.write(INDENT)
+	}
+}
+
+func (p *printer) commentsBefore(line int) {
+	for p.next < len(p.comments) && p.comments[p.next].Line < line {
+		comment := p.comments[p.next]
+		p.startLine(comment.Line)
+		p.write(comment.Literal)
+		p.write("\n")
+		p.lastLine = comment.Line
+		p.next++
+	}
+}
+
+func (p *printer) trailingComment(line int) {
+	if p.next < len(p.comments) && p.comments[p.next].Line == line {
+		p.write(" ")
+		p.write(p.comments[p.next].Literal)
+		p.next++
+	}
+}
+
+func (p *printer) statements(statements []ast.AstStatement, endLine int) {
+	for _, statement := range statements {
+		span := statement.Lines()
+		p.commentsBefore(span.StartLine)
+		p.startLine(span.StartLine)
+		p.statement(statement)
+		// a comment after the closing brace belongs to the enclosing statement
+		if span.EndLine < endLine {
+			p.trailingComment(span.EndLine)
+		}
+		p.write("\n")
+		p.lastLine = span.EndLine
+	}
+	p.commentsBefore(endLine)
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	hasComments := p.next < len(p.comments) && p.comments[p.next].Line < block.EndLine
+	if len(block.Statements) == 0 && !hasComments {
+		p.write("{}")
+		return
+	}
+
+	p.write("{\n")
+	p.indent++
+	p.lastLine = 0
+	p.statements(block.Statements, block.EndLine)
+	p.indent--
+	for i := 0; i < p.indent; i++ {
+		p.write(INDENT)
+	}
+	p.write("}")
+}
+
+func (p *printer) statement(statement ast.AstStatement) {
+	switch node := statement.(type) {
+	case *ast.VarStatement:
+		p.write("var ")
+		p.write(node.Identifer.Ident)
+		p.typeName(node.Type, ": ")
+		p.write(" = ")
+		p.expression(node.Value, parser.LOWEST)
+	case *ast.ReturnStatement:
+		p.write("return")
+		if node.ReturnValue != nil {
+			p.write(" ")
+			p.expression(node.ReturnValue, parser.LOWEST)
+		}
+	case *ast.ExpressionStatement:
+		p.expression(node.Expression, parser.LOWEST)
+	case *ast.IfStatement:
+		p.write("if ")
+		p.expression(node.If.Condition, parser.LOWEST)
+		p.write(" ")
+		p.block(&node.If.Consequence)
+		for _, elif := range node.Elif {
+			p.write(" elif ")
+			p.expression(elif.Condition, parser.LOWEST)
+			p.write(" ")
+			p.block(&elif.Consequence)
+		}
+		if node.Else.Statements != nil {
+			p.write(" else ")
+			p.block(&node.Else)
+		}
+	case *ast.ForStatement:
+		p.write("for ")
+		p.statement(node.Initializer)
+		p.write("; ")
+		p.expression(node.Conditional, parser.LOWEST)
+		p.write("; ")
+		p.statement(node.Increment)
+		p.write(" ")
+		p.statement(node.Body)
+	case *ast.WhileStatement:
+		p.write("while ")
+		p.expression(node.Condition, parser.LOWEST)
+		p.write(" ")
+		p.statement(node.Body)
+	case *ast.TryStatement:
+		p.write("try ")
+		p.block(&node.Body)
+		if node.Catch != nil {
+			p.write(" catch ")
+			if node.CatchIdent != nil {
+				p.write("(" + node.CatchIdent.Ident + ") ")
+			}
+			p.block(node.Catch)
+		}
+		if node.Finally != nil {
+			p.write(" finally ")
+			p.block(node.Finally)
+		}
+	case *ast.ThrowStatement:
+		p.write("throw ")
+		p.expression(node.Value, parser.LOWEST)
+	case *ast.StructStatement:
+		p.structure(node)
+	case *ast.EnumStatement:
+		p.enum(node)
+	case *ast.BlockStatement:
+		p.block(node)
+	}
+}
+
+// fields go on the first line of the body and each method after a blank line
+func (p *printer) structure(node *ast.StructStatement) {
+	p.write("struct " + node.Name + " ")
+	if len(node.Fields) == 0 && len(node.Methods) == 0 {
+		p.write("{}")
+		return
+	}
+
+	p.write("{\n")
+	p.indent++
+	p.lastLine = 0
+	if len(node.Fields) > 0 {
+		p.commentsBefore(node.Fields[0].Token.Line)
+		p.startLine(node.Fields[0].Token.Line)
+		for i, field := range node.Fields {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.write(field.Ident)
+		}
+		last := node.Fields[len(node.Fields)-1].Token.Line
+		p.trailingComment(last)
+		p.write("\n")
+		p.lastLine = last
+	}
+	for i, method := range node.Methods {
+		if i > 0 || len(node.Fields) > 0 {
+			p.write("\n")
+		}
+		p.lastLine = 0
+		p.commentsBefore(method.Token.Line)
+		p.startLine(method.Token.Line)
+		p.expression(method, parser.LOWEST)
+		p.trailingComment(method.Body.EndLine)
+		p.write("\n")
+		p.lastLine = method.Body.EndLine
+	}
+	p.commentsBefore(node.EndLine)
+	p.indent--
+	for i := 0; i < p.indent; i++ {
+		p.write(INDENT)
+	}
+	p.write("}")
+}
+
+// each variant goes on its own line
+func (p *printer) enum(node *ast.EnumStatement) {
+	p.write("enum " + node.Name + " ")
+	if len(node.Variants) == 0 {
+		p.write("{}")
+		return
+	}
+
+	p.write("{\n")
+	p.indent++
+	p.lastLine = 0
+	for i, variant := range node.Variants {
+		p.commentsBefore(variant.Token.Line)
+		p.startLine(variant.Token.Line)
+		p.write(variant.Name)
+		if len(variant.Fields) > 0 {
+			p.write("(")
+			for j, field := range variant.Fields {
+				if j > 0 {
+					p.write(", ")
+				}
+				p.write(field.Ident)
+			}
+			p.write(")")
+		}
+		if i < len(node.Variants)-1 {
+			p.write(",")
+		}
+		p.trailingComment(variant.Token.Line)
+		p.write("\n")
+		p.lastLine = variant.Token.Line
+	}
+	p.commentsBefore(node.EndLine)
+	p.indent--
+	for i := 0; i < p.indent; i++ {
+		p.write(INDENT)
+	}
+	p.write("}")
+}
+
+// each arm goes on its own line
+func (p *printer) match(node *ast.MatchExpression) {
+	p.write("match ")
+	p.expression(node.Subject, parser.LOWEST)
+	if len(node.Arms) == 0 {
+		p.write(" {}")
+		return
+	}
+
+	p.write(" {\n")
+	p.indent++
+	for i, arm := range node.Arms {
+		for j := 0; j < p.indent; j++ {
+			p.write(INDENT)
+		}
+		p.pattern(arm.Pattern)
+		if arm.Guard != nil {
+			p.write(" if ")
+			p.expression(arm.Guard, parser.LOWEST)
+		}
+		p.write(" => ")
+		p.expression(arm.Body, parser.LOWEST)
+		if i < len(node.Arms)-1 {
+			p.write(",")
+		}
+		p.write("\n")
+	}
+	p.indent--
+	for i := 0; i < p.indent; i++ {
+		p.write(INDENT)
+	}
+	p.write("}")
+}
+
+func (p *printer) pattern(pattern ast.Pattern) {
+	switch pattern := pattern.(type) {
+	case *ast.WildcardPattern:
+		p.write("_")
+	case *ast.BindingPattern:
+		p.write(pattern.Ident.Ident)
+	case *ast.LiteralPattern:
+		p.expression(pattern.Value, parser.PREFIX)
+	case *ast.RangePattern:
+		p.expression(pattern.Low, parser.PREFIX)
+		p.write("..")
+		p.expression(pattern.High, parser.PREFIX)
+	case *ast.VariantPattern:
+		p.write(pattern.Enum.Ident + "." + pattern.Variant.Ident)
+		if len(pattern.Fields) > 0 {
+			p.write("(")
+			p.patternList(pattern.Fields)
+			p.write(")")
+		}
+	case *ast.ArrayPattern:
+		p.write("[")
+		p.patternList(pattern.Elements)
+		if pattern.Rest != nil {
+			if len(pattern.Elements) > 0 {
+				p.write(", ")
+			}
+			p.write("..." + pattern.Rest.Ident)
+		}
+		p.write("]")
+	case *ast.HashPattern:
+		p.write("{")
+		for i, key := range pattern.Keys {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(key, parser.PREFIX)
+			p.write(": ")
+			p.pattern(pattern.Values[i])
+		}
+		p.write("}")
+	}
+}
+
+func (p *printer) patternList(patterns []ast.Pattern) {
+	for i, pattern := range patterns {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.pattern(pattern)
+	}
+}
+
+// writes an expression that appears where operators binding less tightly
+// than prec need parentheses
+func (p *printer) parameters(params []ast.Parameter, returnType *ast.TypeName) {
+	p.write("(")
+	for i, param := range params {
+		if i > 0 {
+			p.write(", ")
+		}
+		if param.Variadic {
+			p.write("...")
+		}
+		p.write(param.Ident.Ident)
+		p.typeName(param.Type, ": ")
+		if param.Default != nil {
+			p.write(" = ")
+			p.expression(param.Default, parser.LOWEST)
+		}
+	}
+	p.write(")")
+	p.typeName(returnType, " -> ")
+}
+
+// writes an annotation after its separator, nothing when there is none
+func (p *printer) typeName(typ *ast.TypeName, separator string) {
+	if typ != nil {
+		p.write(separator)
+		p.write(typ.Name)
+	}
+}
+
+func (p *printer) expression(expression ast.AstExpression, prec int) {
+	switch node := expression.(type) {
+	case *ast.IdentiferLiteral:
+		p.write(node.Ident)
+	case *ast.IntegerLiteral:
+		if node.Token.Literal != "" {
+			p.write(node.Token.Literal)
+		} else {
+			p.write(strconv.FormatInt(node.Value, 10))
+		}
+	case *ast.FloatLiteral:
+		if node.Token.Literal != "" {
+			p.write(node.Token.Literal)
+		} else {
+			p.write(strconv.FormatFloat(node.Value, 'f', -1, 64))
+		}
+	case *ast.StringLiteral:
+		p.write("\"" + node.Value + "\"")
+	case *ast.BooleanLiteral:
+		p.write(strconv.FormatBool(node.Value))
+	case *ast.ArrayLiteral:
+		p.write("[")
+		p.expressionList(node.Elements)
+		p.write("]")
+	case *ast.HashLiteral:
+		p.write("{")
+		for i, key := range node.Keys {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(key, parser.LOWEST)
+			p.write(": ")
+			p.expression(node.Pairs[key], parser.LOWEST)
+		}
+		p.write("}")
+	case *ast.IndexExpression:
+		p.expression(node.Left, parser.CALL)
+		p.write("[")
+		p.expression(node.Index, parser.LOWEST)
+		p.write("]")
+	case *ast.CallExpression:
+		p.expression(node.Function, parser.CALL)
+		p.write("(")
+		p.expressionList(node.Arguments)
+		p.write(")")
+	case *ast.PrefixExpression:
+		// ** binds more tightly than a prefix, (-2) ** 2 keeps its parentheses
+		if prec > parser.PREFIX {
+			p.write("(")
+		}
+		p.write(node.Prefix.Literal)
+		p.expression(node.Expression, parser.PREFIX)
+		if prec > parser.PREFIX {
+			p.write(")")
+		}
+	case *ast.InfixExpression:
+		p.infix(node, prec)
+	case *ast.FunctionLiteral:
+		p.function(node, prec)
+	case *ast.Reassignment:
+		p.write(node.Ident.Ident)
+		p.write(" = ")
+		p.expression(node.Value, parser.LOWEST)
+	case *ast.MemberExpression:
+		p.expression(node.Left, parser.CALL)
+		p.write(".")
+		p.write(node.Member.Ident)
+	case *ast.FieldAssignment:
+		p.expression(node.Target, parser.LOWEST)
+		p.write(" = ")
+		p.expression(node.Value, parser.LOWEST)
+	case *ast.MatchExpression:
+		p.match(node)
+	case *ast.NamedArgument:
+		p.write(node.Name.Ident)
+		p.write(" = ")
+		p.expression(node.Value, parser.LOWEST)
+	case *ast.SpreadExpression:
+		p.write("...")
+		p.expression(node.Value, parser.LOWEST)
+	}
+}
+
+func (p *printer) expressionList(expressions []ast.AstExpression) {
+	for i, expression := range expressions {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(expression, parser.LOWEST)
+	}
+}
+
+// anonymous functions are put in parentheses when they are called, an arrow
+// function whenever it is an operand
+func (p *printer) function(node *ast.FunctionLiteral, prec int) {
+	parens := node.Arrow && prec > parser.LOWEST || node.Name == "" && prec >= parser.CALL
+	if parens {
+		p.write("(")
+	}
+
+	switch {
+	case node.Arrow:
+		params := node.Parameters
+		if len(params) == 1 && params[0].Default == nil && !params[0].Variadic {
+			p.write(params[0].Ident.Ident)
+		} else {
+			p.parameters(params, nil)
+		}
+		p.write(" => ")
+		p.expression(node.Body.Statements[0].(*ast.ReturnStatement).ReturnValue, parser.LOWEST)
+	case node.Name == "":
+		p.write("func")
+		p.parameters(node.Parameters, node.ReturnType)
+		p.write(" ")
+		p.block(&node.Body)
+	default:
+		p.write("func ")
+		p.write(node.Name)
+		p.parameters(node.Parameters, node.ReturnType)
+		p.write(" ")
+		p.block(&node.Body)
+	}
+
+	if parens {
+		p.write(")")
+	}
+}
+
+func (p *printer) infix(node *ast.InfixExpression, prec int) {
+	own := parser.Precedence(node.Operator.Type)
+	parens := own < prec
+	if parens {
+		p.write("(")
+	}
+
+	// operators are left associative apart from assignments and **, the other
+	// side needs parentheses when it has the same precedence
+	left, right := own, own+1
+	if own == parser.ASSIGN || own == parser.POWER {
+		left, right = own+1, own
+	}
+	// the right side of ** may start with a prefix, 2 ** -1
+	if own == parser.POWER {
+		right = parser.PREFIX
+	}
+	p.expression(node.Left, left)
+	p.write(" " + node.Operator.Literal + " ")
+	p.expression(node.Right, right)
+
+	if parens {
+		p.write(")")
+	}
+}
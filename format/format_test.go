@@ -0,0 +1,88 @@
+package format
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"spacing", "var x=1+2*3\n", "var x = 1 + 2 * 3\n"},
+		{"parentheses", "var y = (1+2)*3 - (4-5)\n", "var y = (1 + 2) * 3 - (4 - 5)\n"},
+		{"redundant parentheses", "var y = (a*b)+c\n", "var y = a * b + c\n"},
+		{"prefix", "var z = -(a+b)\n", "var z = -(a + b)\n"},
+		{"power", "var p = (2**3)**2 + 2**(3**2) + (-2)**2 + 2**-1\n", "var p = (2 ** 3) ** 2 + 2 ** 3 ** 2 + (-2) ** 2 + 2 ** -1\n"},
+		{"bitwise", "var b = (a|b)&c<<(1+1)\n", "var b = (a | b) & c << 1 + 1\n"},
+		{"literals", "var h = {\"b\":1,\"a\":[1,2.50]}\n", "var h = {\"b\": 1, \"a\": [1, 2.50]}\n"},
+		{"calls", "println( f(1,2)[0] )\n", "println(f(1, 2)[0])\n"},
+		{"function", "func   add(a,b){return a+b}\n", "func add(a, b) {\n    return a + b\n}\n"},
+		{"empty function", "func f() {\n}\n", "func f() {}\n"},
+		{"if chain", "if x>1 {a()} elif x<0 {b()} else {c()}\n",
+			"if x > 1 {\n    a()\n} elif x < 0 {\n    b()\n} else {\n    c()\n}\n"},
+		{"loops", "for var i=0;i<3;i+=1 { while x { x = false } }\n",
+			"for var i = 0; i < 3; i += 1 {\n    while x {\n        x = false\n    }\n}\n"},
+		{"try", "try {a()} catch(e) {throw e} finally {b()}\ntry { a() } catch { }\n",
+			"try {\n    a()\n} catch (e) {\n    throw e\n} finally {\n    b()\n}\ntry {\n    a()\n} catch {}\n"},
+		{"struct", "struct P {x,y\nfunc get(self) {return self.x}}\np.x=P(1,2).get()\n",
+			"struct P {\n    x, y\n\n    func get(self) {\n        return self.x\n    }\n}\np.x = P(1, 2).get()\n"},
+		{"enum", "enum E {A,B(x,y)}\nenum F {}\n", "enum E {\n    A,\n    B(x, y)\n}\nenum F {}\n"},
+		{"match", "var v = match x {E.B(a,_) if a>1=>a,[h,...t]=>h\n{\"k\":-1..5}=>0 _=>1}\n",
+			"var v = match x {\n    E.B(a, _) if a > 1 => a,\n    [h, ...t] => h,\n    {\"k\": -1..5} => 0,\n    _ => 1\n}\n"},
+		{"annotations", "func add(a:int,b)->int{return a+b}\nvar x:float=1.5\n",
+			"func add(a: int, b) -> int {\n    return a + b\n}\nvar x: float = 1.5\n"},
+		{"parameters", "func f(a,b=1,...rest){}\nf(...xs,b=2)\n", "func f(a, b = 1, ...rest) {}\nf(...xs, b = 2)\n"},
+		{"anonymous functions", "var f = func(x){return x}\nvar g = (a,b)=>a+b\nprintln((x=>x)(1), (func(){})())\n",
+			"var f = func(x) {\n    return x\n}\nvar g = (a, b) => a + b\nprintln((x => x)(1), (func() {})())\n"},
+		{"blank lines", "var a = 1\n\n\n\nvar b = 2\n", "var a = 1\n\nvar b = 2\n"},
+		{"blank line after brace", "func f() {\n\n    return 1\n}\n", "func f() {\n    return 1\n}\n"},
+		{"comments", "// head\n\nvar a = 1 // trailing\n// last\n", "// head\n\nvar a = 1 // trailing\n// last\n"},
+		{"comment in block", "func f() {\n// inside\nreturn 1\n    // end\n}\n", "func f() {\n    // inside\n    return 1\n    // end\n}\n"},
+		{"comment in empty block", "func f() {\n    // todo\n}\n", "func f() {\n    // todo\n}\n"},
+		{"comment after brace", "if x { a() } // done\n", "if x {\n    a()\n} // done\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Source(tt.input)
+			if err != nil {
+				t.Fatalf("Source() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+			again, err := Source(got)
+			if err != nil || again != got {
+				t.Errorf("Source() is not idempotent, second pass = %q, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestSourceIdempotentOnScripts(t *testing.T) {
+	for _, file := range []string{"../main.al", "../test.al"} {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		once, err := Source(string(code))
+		if err != nil {
+			t.Fatalf("Source(%s) error = %v", file, err)
+		}
+		twice, _ := Source(once)
+		if once != twice {
+			t.Errorf("Source(%s) is not idempotent", file)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	if _, err := Source("var = 1"); err == nil {
+		t.Errorf("Source() expected a parse error")
+	}
+	if _, err := Source("var x = @"); err == nil {
+		t.Errorf("Source() expected a lex error")
+	}
+}
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/EVFUBS/AlphaLang/token"
@@ -11,15 +12,43 @@ type Lexer struct {
 	curPosition   int
 	nextPostition int
 	ch            byte
+	line          int
+	column        int
+	errors        []LexerError
+}
+
+type LexerError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (le LexerError) String() string {
+	return fmt.Sprintf("%d:%d: %s", le.Line, le.Column, le.Message)
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
+func (l *Lexer) Errors() []LexerError {
+	return l.errors
+}
+
+func (l *Lexer) addError(line, column int, msg string) {
+	l.errors = append(l.errors, LexerError{Line: line, Column: column, Message: msg})
+}
+
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
 	if l.nextPostition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -54,9 +83,14 @@ func (l *Lexer) peekChar() byte {
 	return 0
 }
 
+// reports whether the next characters are .. so 1..5 is not read as a float
+func (l *Lexer) rangeAhead() bool {
+	return l.nextPostition+1 < len(l.input) && l.input[l.nextPostition:l.nextPostition+2] == ".."
+}
+
 func (l *Lexer) readIdent() string {
 	position := l.curPosition
-	for l.isChar(l.peekChar()) {
+	for l.isChar(l.peekChar()) || l.isNum(l.peekChar()) {
 		l.readChar()
 	}
 	return l.input[position:l.nextPostition]
@@ -64,25 +98,38 @@ func (l *Lexer) readIdent() string {
 
 func (l *Lexer) readNum() string {
 	position := l.curPosition
-	for l.isNum(l.peekChar()) || l.peekChar() == '.' {
+	for l.isNum(l.peekChar()) || l.peekChar() == '.' && !l.rangeAhead() {
 		l.readChar()
 	}
 	return l.input[position:l.nextPostition]
 }
 
-func (l *Lexer) readString() string {
+// reports false when the input ends before the closing quote
+func (l *Lexer) readString() (string, bool) {
 	position := l.curPosition
-	for l.peekChar() != '"' {
+	for l.peekChar() != '"' && l.peekChar() != 0 {
 		l.readChar()
 	}
 	l.readChar()
 	//return l.input[position:l.nextPostition]
-	return l.input[position+1 : l.nextPostition-1]
+	if l.ch == 0 {
+		return l.input[position+1:], false
+	}
+	return l.input[position+1 : l.nextPostition-1], true
+}
+
+func (l *Lexer) readComment() string {
+	position := l.curPosition
+	for l.peekChar() != '\n' && l.peekChar() != 0 {
+		l.readChar()
+	}
+	return strings.TrimRight(l.input[position:l.nextPostition], " \t\r")
 }
 
 func (l *Lexer) NextToken() *token.Token {
 	l.skipWhitespace()
 	var newToken *token.Token
+	line, column := l.line, l.column
 
 	switch l.ch {
 	case '{':
@@ -103,10 +150,25 @@ func (l *Lexer) NextToken() *token.Token {
 		newToken = &token.Token{Type: token.COLON, Literal: ":"}
 	case ',':
 		newToken = &token.Token{Type: token.COMMA, Literal: ","}
+	case '.':
+		if l.peekChar() == '.' {
+			l.readChar()
+			if l.peekChar() == '.' {
+				l.readChar()
+				newToken = &token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			} else {
+				newToken = &token.Token{Type: token.DOTDOT, Literal: ".."}
+			}
+		} else {
+			newToken = &token.Token{Type: token.DOT, Literal: "."}
+		}
 	case '=':
 		if l.peekChar() == '=' {
 			l.readChar()
 			newToken = &token.Token{Type: token.EQUAL, Literal: "=="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			newToken = &token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			newToken = &token.Token{Type: token.ASSIGN, Literal: "="}
 		}
@@ -114,6 +176,9 @@ func (l *Lexer) NextToken() *token.Token {
 		if l.peekChar() == '=' {
 			l.readChar()
 			newToken = &token.Token{Type: token.LEQUAL, Literal: "<="}
+		} else if l.peekChar() == '<' {
+			l.readChar()
+			newToken = &token.Token{Type: token.LSHIFT, Literal: "<<"}
 		} else {
 			newToken = &token.Token{Type: token.LTHAN, Literal: "<"}
 		}
@@ -121,6 +186,9 @@ func (l *Lexer) NextToken() *token.Token {
 		if l.peekChar() == '=' {
 			l.readChar()
 			newToken = &token.Token{Type: token.GEQUAL, Literal: ">="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			newToken = &token.Token{Type: token.RSHIFT, Literal: ">>"}
 		} else {
 			newToken = &token.Token{Type: token.GTHAN, Literal: ">"}
 		}
@@ -135,15 +203,35 @@ func (l *Lexer) NextToken() *token.Token {
 		if l.peekChar() == '=' {
 			l.readChar()
 			newToken = &token.Token{Type: token.DECREMENT, Literal: "-="}
+		} else if l.peekChar() == '>' {
+			l.readChar()
+			newToken = &token.Token{Type: token.RARROW, Literal: "->"}
 		} else {
 			newToken = &token.Token{Type: token.MINUS, Literal: "-"}
 		}
 	case '*':
-		newToken = &token.Token{Type: token.ASTERISK, Literal: "*"}
+		if l.peekChar() == '*' {
+			l.readChar()
+			newToken = &token.Token{Type: token.POWER, Literal: "**"}
+		} else {
+			newToken = &token.Token{Type: token.ASTERISK, Literal: "*"}
+		}
 	case '/':
-		newToken = &token.Token{Type: token.SLASH, Literal: "/"}
+		if l.peekChar() == '/' {
+			newToken = &token.Token{Type: token.COMMENT, Literal: l.readComment()}
+		} else {
+			newToken = &token.Token{Type: token.SLASH, Literal: "/"}
+		}
 	case '%':
 		newToken = &token.Token{Type: token.MODULUS, Literal: "%"}
+	case '&':
+		newToken = &token.Token{Type: token.AMPERSAND, Literal: "&"}
+	case '|':
+		newToken = &token.Token{Type: token.PIPE, Literal: "|"}
+	case '^':
+		newToken = &token.Token{Type: token.CARET, Literal: "^"}
+	case '~':
+		newToken = &token.Token{Type: token.TILDE, Literal: "~"}
 	case 0:
 		newToken = &token.Token{Type: token.EOF, Literal: "EOF"}
 	case '!':
@@ -154,7 +242,10 @@ func (l *Lexer) NextToken() *token.Token {
 			newToken = &token.Token{Type: token.BANG, Literal: "!"}
 		}
 	case '"':
-		newString := l.readString()
+		newString, ok := l.readString()
+		if !ok {
+			l.addError(line, column, "unterminated string")
+		}
 		newToken = &token.Token{Type: token.STRING, Literal: newString}
 	default:
 		if l.isChar(l.ch) {
@@ -169,9 +260,12 @@ func (l *Lexer) NextToken() *token.Token {
 				newToken = &token.Token{Type: token.INTEGER, Literal: num}
 			}
 		} else {
-			newToken = &token.Token{Type: token.ILLEGAL, Literal: "ILLEGAL"}
+			newToken = &token.Token{Type: token.ILLEGAL, Literal: string(l.ch)}
+			l.addError(line, column, fmt.Sprintf("illegal character %q", string(l.ch)))
 		}
 	}
+	newToken.Line = line
+	newToken.Column = column
 	l.readChar()
 	return newToken
 }
@@ -0,0 +1,42 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+func TestLexer_NextToken(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []token.Token
+	}{
+		{"x1 + y22", []token.Token{
+			{Type: token.IDENT, Literal: "x1"},
+			{Type: token.PLUS, Literal: "+"},
+			{Type: token.IDENT, Literal: "y22"},
+		}},
+		{"2.5 * 3", []token.Token{
+			{Type: token.FLOAT, Literal: "2.5"},
+			{Type: token.ASTERISK, Literal: "*"},
+			{Type: token.INTEGER, Literal: "3"},
+		}},
+		{"\"open", []token.Token{
+			{Type: token.STRING, Literal: "open"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := New(tt.input)
+			for _, want := range tt.want {
+				got := l.NextToken()
+				if got.Type != want.Type || got.Literal != want.Literal {
+					t.Fatalf("NextToken() = %s %q, want %s %q", got.Type, got.Literal, want.Type, want.Literal)
+				}
+			}
+			if got := l.NextToken(); got.Type != token.EOF {
+				t.Errorf("NextToken() = %s %q, want EOF", got.Type, got.Literal)
+			}
+		})
+	}
+}
@@ -0,0 +1,495 @@
+package lineeditor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const MAX_HISTORY = 1000
+
+var ErrInterrupted = errors.New("interrupted")
+
+// returns every candidate that could replace word
+type Completer func(word string) []string
+
+type Editor struct {
+	Complete Completer
+
+	in       *bufio.Reader
+	out      io.Writer
+	fd       uintptr
+	terminal bool
+	history  []string
+
+	// state of the line being edited
+	prompt    string
+	buf       []rune
+	pos       int
+	historyAt int
+	// the line being typed, kept while moving through history
+	draft   []rune
+	pending []rune
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyBackspace = 8
+	keyTab       = 9
+	keyNewline   = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// escape sequences are mapped onto private use runes
+const (
+	keyUp rune = 0xe000 + iota
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDeleteForward
+	keyWordLeft
+	keyWordRight
+)
+
+// editing is only enabled when in is a terminal, anything else is read line by line
+func New(in io.Reader, out io.Writer) *Editor {
+	editor := &Editor{
+		in:  bufio.NewReader(in),
+		out: out,
+	}
+	if file, ok := in.(*os.File); ok {
+		editor.fd = file.Fd()
+		editor.terminal = IsTerminal(editor.fd)
+	}
+	return editor
+}
+
+func (e *Editor) IsTerminal() bool {
+	return e.terminal
+}
+
+func (e *Editor) History() []string {
+	return e.history
+}
+
+func (e *Editor) AddHistory(line string) {
+	line = strings.TrimRight(line, "\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > MAX_HISTORY {
+		e.history = e.history[len(e.history)-MAX_HISTORY:]
+	}
+}
+
+func (e *Editor) LoadHistory(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		e.AddHistory(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+func (e *Editor) SaveHistory(path string) error {
+	var out string
+	for _, line := range e.history {
+		out += line + "\n"
+	}
+	return os.WriteFile(path, []byte(out), 0600)
+}
+
+// reads a single line, io.EOF is returned on ctrl-d and ErrInterrupted on ctrl-c
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		return e.readPlain(prompt)
+	}
+
+	state, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readPlain(prompt)
+	}
+	defer restore(e.fd, state)
+
+	line, err := e.edit(prompt)
+	if err == nil {
+		e.AddHistory(line)
+	}
+	return line, err
+}
+
+func (e *Editor) readPlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (e *Editor) edit(prompt string) (string, error) {
+	e.prompt = prompt
+	e.buf = nil
+	e.pos = 0
+	e.historyAt = len(e.history)
+	e.draft = nil
+	e.pending = nil
+	e.refresh()
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case keyEnter, keyNewline:
+			fmt.Fprint(e.out, "\n")
+			return string(e.buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyBackspace, keyDelete:
+			e.backspace()
+		case keyDeleteForward:
+			e.deleteForward()
+		case keyCtrlA, keyHome:
+			e.pos = 0
+		case keyCtrlE, keyEnd:
+			e.pos = len(e.buf)
+		case keyCtrlB, keyLeft:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyCtrlF, keyRight:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyWordLeft:
+			e.pos = e.wordStart(e.pos)
+		case keyWordRight:
+			e.pos = e.wordEnd(e.pos)
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.wordStart(e.pos)
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP, keyUp:
+			e.historyMove(-1)
+		case keyCtrlN, keyDown:
+			e.historyMove(1)
+		case keyTab:
+			e.complete()
+		case keyCtrlR:
+			line, done, err := e.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+			if done {
+				fmt.Fprint(e.out, "\n")
+				return line, nil
+			}
+		default:
+			if unicode.IsPrint(key) {
+				e.insert(key)
+			}
+		}
+		e.refresh()
+	}
+}
+
+func (e *Editor) readKey() (rune, error) {
+	if len(e.pending) > 0 {
+		key := e.pending[0]
+		e.pending = e.pending[1:]
+		return key, nil
+	}
+
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != keyEscape {
+		return r, nil
+	}
+
+	next, _, err := e.in.ReadRune()
+	if err != nil {
+		return keyEscape, nil
+	}
+	switch next {
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case '[', 'O':
+	default:
+		return keyEscape, nil
+	}
+
+	// read parameters up to the final byte of the sequence
+	var params string
+	for {
+		c, _, err := e.in.ReadRune()
+		if err != nil {
+			return keyEscape, nil
+		}
+		if c >= 0x40 && c <= 0x7e {
+			switch c {
+			case 'A':
+				return keyUp, nil
+			case 'B':
+				return keyDown, nil
+			case 'C':
+				if strings.HasSuffix(params, ";5") {
+					return keyWordRight, nil
+				}
+				return keyRight, nil
+			case 'D':
+				if strings.HasSuffix(params, ";5") {
+					return keyWordLeft, nil
+				}
+				return keyLeft, nil
+			case 'H':
+				return keyHome, nil
+			case 'F':
+				return keyEnd, nil
+			case '~':
+				switch params {
+				case "1", "7":
+					return keyHome, nil
+				case "4", "8":
+					return keyEnd, nil
+				case "3":
+					return keyDeleteForward, nil
+				}
+			}
+			return 0, nil
+		}
+		params += string(c)
+	}
+}
+
+func (e *Editor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *Editor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *Editor) insertString(s string) {
+	for _, r := range s {
+		e.insert(r)
+	}
+}
+
+func (e *Editor) backspace() {
+	if e.pos == 0 {
+		return
+	}
+	e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+	e.pos--
+}
+
+func (e *Editor) deleteForward() {
+	if e.pos >= len(e.buf) {
+		return
+	}
+	e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func (e *Editor) wordStart(pos int) int {
+	for pos > 0 && !isWordChar(e.buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordChar(e.buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func (e *Editor) wordEnd(pos int) int {
+	for pos < len(e.buf) && !isWordChar(e.buf[pos]) {
+		pos++
+	}
+	for pos < len(e.buf) && isWordChar(e.buf[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func (e *Editor) historyMove(step int) {
+	at := e.historyAt + step
+	if at < 0 || at > len(e.history) {
+		return
+	}
+	if e.historyAt == len(e.history) {
+		e.draft = append([]rune(nil), e.buf...)
+	}
+	e.historyAt = at
+	if at == len(e.history) {
+		e.buf = e.draft
+	} else {
+		e.buf = []rune(e.history[at])
+	}
+	e.pos = len(e.buf)
+}
+
+func (e *Editor) complete() {
+	if e.Complete == nil {
+		return
+	}
+
+	// meta commands are completed including their leading colon
+	start := e.pos
+	for start > 0 && isWordChar(e.buf[start-1]) {
+		start--
+	}
+	if start == 1 && e.buf[0] == ':' {
+		start = 0
+	}
+	word := string(e.buf[start:e.pos])
+
+	candidates := e.Complete(word)
+	if len(candidates) == 0 {
+		return
+	}
+
+	prefix := commonPrefix(candidates)
+	if len(prefix) > len(word) {
+		e.insertString(prefix[len(word):])
+		return
+	}
+	if len(candidates) == 1 {
+		return
+	}
+	fmt.Fprintf(e.out, "\n%s\n", strings.Join(candidates, "  "))
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// ctrl-r searches backwards through history for lines containing the query,
+// returns done when the user accepted the match with enter
+func (e *Editor) reverseSearch() (string, bool, error) {
+	original := append([]rune{}, e.buf...)
+	originalPos := e.pos
+	var query []rune
+	match := len(e.history)
+	failed := false
+
+	search := func(from int) {
+		for i := from; i >= 0; i-- {
+			if idx := strings.Index(e.history[i], string(query)); idx >= 0 {
+				match = i
+				e.buf = []rune(e.history[i])
+				e.pos = len([]rune(e.history[i][:idx]))
+				failed = false
+				return
+			}
+		}
+		failed = true
+	}
+
+	for {
+		label := "reverse-i-search"
+		if failed {
+			label = "failing " + label
+		}
+		fmt.Fprintf(e.out, "\r(%s)'%s': %s\x1b[K", label, string(query), string(e.buf))
+
+		key, err := e.readKey()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch {
+		case key == keyCtrlR:
+			search(match - 1)
+		case key == keyBackspace || key == keyDelete:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				search(len(e.history) - 1)
+			}
+		case key == keyEnter || key == keyNewline:
+			return string(e.buf), true, nil
+		case key == keyCtrlG || key == keyEscape:
+			e.buf = original
+			e.pos = originalPos
+			return "", false, nil
+		case key == keyCtrlC:
+			e.pending = append(e.pending, key)
+			return "", false, nil
+		case unicode.IsPrint(key):
+			query = append(query, key)
+			if match >= len(e.history) {
+				match = len(e.history) - 1
+			}
+			search(match)
+		default:
+			// any other key leaves search and is handled by the editor
+			e.pending = append(e.pending, key)
+			return "", false, nil
+		}
+	}
+}
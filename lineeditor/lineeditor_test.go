@@ -0,0 +1,85 @@
+package lineeditor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestEditor(keys string, history ...string) *Editor {
+	e := New(strings.NewReader(keys), &bytes.Buffer{})
+	for _, line := range history {
+		e.AddHistory(line)
+	}
+	e.Complete = func(word string) []string {
+		var candidates []string
+		for _, name := range []string{"println", "print", "pop", "while"} {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+		return candidates
+	}
+	return e
+}
+
+func TestEditor_edit(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		history []string
+		want    string
+	}{
+		{"plain", "var x = 1\r", nil, "var x = 1"},
+		{"backspace", "abd\x7fc\r", nil, "abc"},
+		{"cursor left insert", "ac\x1b[Db\r", nil, "abc"},
+		{"home and end", "bc\x01a\x05d\r", nil, "abcd"},
+		{"kill to end", "abcdef\x1b[D\x1b[D\x1b[D\x0b\r", nil, "abc"},
+		{"delete word", "var total\x17x\r", nil, "var x"},
+		{"delete forward", "abxc\x1b[D\x1b[D\x1b[3~\r", nil, "abc"},
+		{"history up", "\x1b[A\r", []string{"first", "second"}, "second"},
+		{"history up twice", "\x1b[A\x1b[A\r", []string{"first", "second"}, "first"},
+		{"history back down", "\x1b[A\x1b[A\x1b[B\r", []string{"first", "second"}, "second"},
+		{"history keeps draft", "dra\x1b[A\x1b[A\x1b[B\x1b[Bft\r", []string{"first", "second"}, "draft"},
+		{"complete unique", "whi\t\r", nil, "while"},
+		{"complete common prefix", "prin\t\r", nil, "print"},
+		{"reverse search", "\x12ar\r", []string{"var a = 1", "println(a)", "var b = 2"}, "var b = 2"},
+		{"reverse search older", "\x12ar\x12\r", []string{"var a = 1", "println(a)", "var b = 2"}, "var a = 1"},
+		{"reverse search then edit", "\x12prin\x05!\r", []string{"println(a)", "x"}, "println(a)!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEditor(tt.keys, tt.history...)
+			got, err := e.edit(">> ")
+			if err != nil {
+				t.Fatalf("edit() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditor_editControl(t *testing.T) {
+	if _, err := newTestEditor("abc\x03").edit(">> "); err != ErrInterrupted {
+		t.Errorf("ctrl-c error = %v, want %v", err, ErrInterrupted)
+	}
+	if _, err := newTestEditor("\x04").edit(">> "); err != io.EOF {
+		t.Errorf("ctrl-d error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestEditor_ReadLinePlain(t *testing.T) {
+	e := New(strings.NewReader("first\nsecond"), &bytes.Buffer{})
+	for _, want := range []string{"first", "second"} {
+		got, err := e.ReadLine(">> ")
+		if err != nil || got != want {
+			t.Errorf("ReadLine() = %q, %v, want %q", got, err, want)
+		}
+	}
+	if _, err := e.ReadLine(">> "); err != io.EOF {
+		t.Errorf("ReadLine() error = %v, want %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build linux
+
+package lineeditor
+
+import "syscall"
+
+const ioctlGetTermios = syscall.TCGETS
+const ioctlSetTermios = syscall.TCSETS
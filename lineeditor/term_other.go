@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package lineeditor
+
+import "errors"
+
+type termState struct{}
+
+func IsTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (*termState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restore(fd uintptr, state *termState) error {
+	return nil
+}
@@ -0,0 +1,181 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/check"
+	"github.com/EVFUBS/AlphaLang/lexer"
+	"github.com/EVFUBS/AlphaLang/parser"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+// an open file, analysed again whenever its text changes
+type document struct {
+	uri           string
+	text          string
+	lines         []string
+	program       *ast.Program
+	analysis      *check.Analysis
+	tokens        []token.Token
+	lexErrors     []lexer.LexerError
+	parseErrors   []parser.ParserError
+	parseWarnings []parser.ParserError
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lines: strings.Split(text, "\n")}
+	for i, line := range d.lines {
+		d.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	l := lexer.New(text)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		d.tokens = append(d.tokens, *tok)
+	}
+
+	l = lexer.New(text)
+	p := parser.New(l)
+	d.program = p.ParseProgram()
+	d.lexErrors = l.Errors()
+	d.parseErrors = p.Errors()
+	d.parseWarnings = p.Warnings()
+	d.analysis = check.Analyze(d.program)
+	return d
+}
+
+func (d *document) line(line int) string {
+	if line < 1 || line > len(d.lines) {
+		return ""
+	}
+	return d.lines[line-1]
+}
+
+// converts a 1-based line and byte column into an lsp position
+func (d *document) position(line, column int) Position {
+	text := d.line(line)
+	if column < 1 {
+		column = 1
+	}
+	if column > len(text)+1 {
+		column = len(text) + 1
+	}
+	return Position{Line: line - 1, Character: utf16Len(text[:column-1])}
+}
+
+// converts an lsp position into a 1-based line and byte column
+func (d *document) location(pos Position) (int, int) {
+	text := d.line(pos.Line + 1)
+	units := 0
+	for i, r := range text {
+		if units >= pos.Character {
+			return pos.Line + 1, i + 1
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return pos.Line + 1, len(text) + 1
+}
+
+// the range covered by a token, or by the word at the position for errors
+func (d *document) tokenRange(line, column int, literal string) Range {
+	if literal == "" {
+		text := d.line(line)
+		end := column - 1
+		for end < len(text) && isWordChar(text[end]) {
+			end++
+		}
+		if end == column-1 && end < len(text) {
+			end++
+		}
+		return Range{Start: d.position(line, column), End: d.position(line, end+1)}
+	}
+	return Range{Start: d.position(line, column), End: d.position(line, column+len(literal))}
+}
+
+func (d *document) end() Position {
+	return d.position(len(d.lines), len(d.line(len(d.lines)))+1)
+}
+
+// the identifier token under the cursor, a cursor just after it counts
+func (d *document) identifierAt(line, column int) (token.Token, bool) {
+	for _, tok := range d.tokens {
+		if tok.Type == token.IDENT && covers(tok, line, column) {
+			return tok, true
+		}
+	}
+	return token.Token{}, false
+}
+
+// the symbol declared or referenced at the position
+func (d *document) symbolAt(line, column int) *check.Symbol {
+	for _, sym := range d.analysis.Symbols {
+		if covers(sym.Token, line, column) {
+			return sym
+		}
+		for _, ref := range sym.References {
+			if covers(ref, line, column) {
+				return sym
+			}
+		}
+	}
+	return nil
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, err := range d.lexErrors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(err.Line, err.Column, ""),
+			Severity: SEVERITY_ERROR,
+			Source:   "alpha",
+			Message:  err.Message,
+		})
+	}
+	for _, err := range d.parseErrors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(err.Line, err.Column, ""),
+			Severity: SEVERITY_ERROR,
+			Source:   "alpha",
+			Message:  err.Message,
+		})
+	}
+	// findings on a half parsed program are mostly noise
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	for _, warning := range d.parseWarnings {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(warning.Line, warning.Column, ""),
+			Severity: SEVERITY_WARNING,
+			Source:   "alpha",
+			Message:  warning.Message,
+		})
+	}
+	for _, finding := range d.analysis.Findings {
+		severity := SEVERITY_ERROR
+		if finding.Severity == check.WARNING {
+			severity = SEVERITY_WARNING
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.tokenRange(finding.Line, finding.Column, ""),
+			Severity: severity,
+			Source:   "alpha",
+			Message:  finding.Message,
+		})
+	}
+	return diagnostics
+}
+
+func covers(tok token.Token, line, column int) bool {
+	return tok.Line == line && column >= tok.Column && column <= tok.Column+len(tok.Literal)
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9')
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,356 @@
+package lsp
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/builtins"
+	"github.com/EVFUBS/AlphaLang/check"
+	"github.com/EVFUBS/AlphaLang/format"
+	"github.com/EVFUBS/AlphaLang/token"
+)
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, *responseError) {
+	result := &InitializeResult{Capabilities: ServerCapabilities{
+		TextDocumentSync:           SYNC_FULL,
+		DefinitionProvider:         true,
+		ReferencesProvider:         true,
+		HoverProvider:              true,
+		CompletionProvider:         struct{}{},
+		DocumentSymbolProvider:     true,
+		DocumentFormattingProvider: true,
+	}}
+	result.ServerInfo.Name = "alpha"
+	return result, nil
+}
+
+func (s *Server) shutdownRequest(params json.RawMessage) (interface{}, *responseError) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) error {
+	var p DidOpenTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	return s.update(p.TextDocument.URI, p.TextDocument.Text)
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p DidChangeTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil || len(p.ContentChanges) == 0 {
+		return nil
+	}
+	// full sync, the last change holds the whole text
+	return s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params json.RawMessage) error {
+	var p DidCloseTextDocumentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	delete(s.documents, p.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+func (s *Server) update(uri, text string) error {
+	d := newDocument(uri, text)
+	s.documents[uri] = d
+	return s.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{URI: uri, Diagnostics: d.diagnostics()})
+}
+
+// finds the open document and the 1-based line and column of the position
+func (s *Server) lookup(params json.RawMessage, p *TextDocumentPositionParams) (*document, int, int, *responseError) {
+	if err := decode(params, p); err != nil {
+		return nil, 0, 0, err
+	}
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, 0, 0, &responseError{Code: INVALID_PARAMS, Message: "document not open: " + p.TextDocument.URI}
+	}
+	line, column := d.location(p.Position)
+	return d, line, column, nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, *responseError) {
+	var p TextDocumentPositionParams
+	d, line, column, err := s.lookup(params, &p)
+	if err != nil {
+		return nil, err
+	}
+	sym := d.symbolAt(line, column)
+	if sym == nil {
+		return nil, nil
+	}
+	return &Location{URI: d.uri, Range: d.tokenRange(sym.Token.Line, sym.Token.Column, sym.Token.Literal)}, nil
+}
+
+func (s *Server) references(params json.RawMessage) (interface{}, *responseError) {
+	var p ReferenceParams
+	d, line, column, err := s.lookup(params, &p.TextDocumentPositionParams)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	locations := []Location{}
+	sym := d.symbolAt(line, column)
+	if sym == nil {
+		return locations, nil
+	}
+	if p.Context.IncludeDeclaration {
+		locations = append(locations, Location{URI: d.uri, Range: d.tokenRange(sym.Token.Line, sym.Token.Column, sym.Token.Literal)})
+	}
+	for _, ref := range sym.References {
+		locations = append(locations, Location{URI: d.uri, Range: d.tokenRange(ref.Line, ref.Column, ref.Literal)})
+	}
+	return locations, nil
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, *responseError) {
+	var p TextDocumentPositionParams
+	d, line, column, err := s.lookup(params, &p)
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := d.identifierAt(line, column)
+	if !ok {
+		return nil, nil
+	}
+
+	var signature string
+	if sym := d.symbolAt(line, column); sym != nil {
+		signature = describe(sym)
+	} else if _, ok := builtins.BuiltIns[tok.Literal]; ok {
+		signature = "builtin func " + tok.Literal
+	} else if _, ok := builtins.Modules[tok.Literal]; ok {
+		signature = "module " + tok.Literal
+	} else {
+		return nil, nil
+	}
+
+	r := d.tokenRange(tok.Line, tok.Column, tok.Literal)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: "```alpha\n" + signature + "\n```"}, Range: &r}, nil
+}
+
+func describe(sym *check.Symbol) string {
+	switch sym.Kind {
+	case check.FUNCTION:
+		return format.Signature(sym.Name, sym.Function.Parameters, sym.Function.ReturnType)
+	case check.STRUCT:
+		var fields []string
+		for _, field := range sym.Struct.Fields {
+			fields = append(fields, field.Ident)
+		}
+		return "struct " + sym.Name + " { " + strings.Join(fields, ", ") + " }"
+	case check.ENUM:
+		var variants []string
+		for _, variant := range sym.Enum.Variants {
+			name := variant.Name
+			if len(variant.Fields) > 0 {
+				var fields []string
+				for _, field := range variant.Fields {
+					fields = append(fields, field.Ident)
+				}
+				name += "(" + strings.Join(fields, ", ") + ")"
+			}
+			variants = append(variants, name)
+		}
+		return "enum " + sym.Name + " { " + strings.Join(variants, ", ") + " }"
+	case check.PARAMETER:
+		return "param " + sym.Name + annotation(sym)
+	default:
+		return "var " + sym.Name + annotation(sym)
+	}
+}
+
+func annotation(sym *check.Symbol) string {
+	if sym.Type == "" {
+		return ""
+	}
+	return ": " + sym.Type
+}
+
+func (s *Server) completion(params json.RawMessage) (interface{}, *responseError) {
+	var p TextDocumentPositionParams
+	d, line, _, err := s.lookup(params, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+	add := func(item CompletionItem) {
+		if !seen[item.Label] {
+			seen[item.Label] = true
+			items = append(items, item)
+		}
+	}
+
+	// innermost scopes first so shadowing names win
+	var visible []*check.Symbol
+	for _, sym := range d.analysis.Symbols {
+		if sym.ScopeStart <= line && line <= sym.ScopeEnd {
+			visible = append(visible, sym)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool { return visible[i].ScopeStart > visible[j].ScopeStart })
+	for _, sym := range visible {
+		kind := COMPLETION_VARIABLE
+		switch sym.Kind {
+		case check.FUNCTION:
+			kind = COMPLETION_FUNCTION
+		case check.STRUCT:
+			kind = COMPLETION_STRUCT
+		case check.ENUM:
+			kind = COMPLETION_ENUM
+		}
+		add(CompletionItem{Label: sym.Name, Kind: kind, Detail: describe(sym)})
+	}
+
+	var names []string
+	for name := range builtins.BuiltIns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(CompletionItem{Label: name, Kind: COMPLETION_FUNCTION, Detail: "builtin"})
+	}
+	var modules []string
+	for name := range builtins.Modules {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	for _, name := range modules {
+		add(CompletionItem{Label: name, Kind: COMPLETION_MODULE, Detail: "builtin module"})
+	}
+	for _, keyword := range token.Keywords() {
+		add(CompletionItem{Label: keyword, Kind: COMPLETION_KEYWORD})
+	}
+	return items, nil
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (interface{}, *responseError) {
+	var p DocumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{Code: INVALID_PARAMS, Message: "document not open: " + p.TextDocument.URI}
+	}
+	symbols := d.functions(d.program.Statements)
+	if symbols == nil {
+		symbols = []DocumentSymbol{}
+	}
+	return symbols, nil
+}
+
+// the functions and structs declared in the statements, with nested ones as
+// children
+func (d *document) functions(statements []ast.AstStatement) []DocumentSymbol {
+	var symbols []DocumentSymbol
+	for _, statement := range statements {
+		switch node := statement.(type) {
+		case *ast.ExpressionStatement:
+			fn, ok := node.Expression.(*ast.FunctionLiteral)
+			if !ok || fn.Name == "" {
+				continue
+			}
+			var params []string
+			for _, param := range fn.Parameters {
+				params = append(params, param.Ident.Ident)
+			}
+			symbols = append(symbols, DocumentSymbol{
+				Name:   fn.Name,
+				Detail: "(" + strings.Join(params, ", ") + ")",
+				Kind:   SYMBOL_FUNCTION,
+				Range: Range{
+					Start: d.position(node.StartLine, node.StartColumn),
+					End:   d.position(node.EndLine, len(d.line(node.EndLine))+1),
+				},
+				SelectionRange: d.tokenRange(fn.Token.Line, fn.Token.Column, fn.Token.Literal),
+				Children:       d.functions(fn.Body.Statements),
+			})
+		case *ast.StructStatement:
+			var methods []ast.AstStatement
+			for _, method := range node.Methods {
+				methods = append(methods, &ast.ExpressionStatement{
+					Span:       ast.Span{StartLine: method.Token.Line, StartColumn: method.Token.Column, EndLine: method.Body.EndLine},
+					Expression: method,
+				})
+			}
+			symbols = append(symbols, DocumentSymbol{
+				Name: node.Name,
+				Kind: SYMBOL_STRUCT,
+				Range: Range{
+					Start: d.position(node.StartLine, node.StartColumn),
+					End:   d.position(node.EndLine, len(d.line(node.EndLine))+1),
+				},
+				SelectionRange: d.tokenRange(node.Token.Line, node.Token.Column, node.Token.Literal),
+				Children:       d.functions(methods),
+			})
+		case *ast.EnumStatement:
+			var variants []DocumentSymbol
+			for _, variant := range node.Variants {
+				r := d.tokenRange(variant.Token.Line, variant.Token.Column, variant.Token.Literal)
+				variants = append(variants, DocumentSymbol{Name: variant.Name, Kind: SYMBOL_ENUM_MEMBER, Range: r, SelectionRange: r})
+			}
+			symbols = append(symbols, DocumentSymbol{
+				Name: node.Name,
+				Kind: SYMBOL_ENUM,
+				Range: Range{
+					Start: d.position(node.StartLine, node.StartColumn),
+					End:   d.position(node.EndLine, len(d.line(node.EndLine))+1),
+				},
+				SelectionRange: d.tokenRange(node.Token.Line, node.Token.Column, node.Token.Literal),
+				Children:       variants,
+			})
+		case *ast.BlockStatement:
+			symbols = append(symbols, d.functions(node.Statements)...)
+		case *ast.IfStatement:
+			symbols = append(symbols, d.functions(node.If.Consequence.Statements)...)
+			for _, elif := range node.Elif {
+				symbols = append(symbols, d.functions(elif.Consequence.Statements)...)
+			}
+			symbols = append(symbols, d.functions(node.Else.Statements)...)
+		case *ast.ForStatement:
+			symbols = append(symbols, d.functions([]ast.AstStatement{node.Body})...)
+		case *ast.WhileStatement:
+			symbols = append(symbols, d.functions([]ast.AstStatement{node.Body})...)
+		case *ast.TryStatement:
+			symbols = append(symbols, d.functions(node.Body.Statements)...)
+			if node.Catch != nil {
+				symbols = append(symbols, d.functions(node.Catch.Statements)...)
+			}
+			if node.Finally != nil {
+				symbols = append(symbols, d.functions(node.Finally.Statements)...)
+			}
+		}
+	}
+	return symbols
+}
+
+func (s *Server) formatting(params json.RawMessage) (interface{}, *responseError) {
+	var p DocumentParams
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{Code: INVALID_PARAMS, Message: "document not open: " + p.TextDocument.URI}
+	}
+
+	formatted, err := format.Source(d.text)
+	// invalid code is left alone, the diagnostics already explain why
+	if err != nil || formatted == d.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: Range{Start: Position{}, End: d.end()}, NewText: formatted}}, nil
+}
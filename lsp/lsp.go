@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+var ErrExitWithoutShutdown = errors.New("exit received before shutdown")
+
+// a language server speaking json-rpc over a pair of streams
+type Server struct {
+	in        *bufio.Reader
+	out       io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+type handler func(s *Server, params json.RawMessage) (interface{}, *responseError)
+
+var handlers map[string]handler
+var notifications map[string]func(s *Server, params json.RawMessage) error
+
+func init() {
+	handlers = map[string]handler{
+		"initialize":                  (*Server).initialize,
+		"shutdown":                    (*Server).shutdownRequest,
+		"textDocument/definition":     (*Server).definition,
+		"textDocument/references":     (*Server).references,
+		"textDocument/hover":          (*Server).hover,
+		"textDocument/completion":     (*Server).completion,
+		"textDocument/documentSymbol": (*Server).documentSymbol,
+		"textDocument/formatting":     (*Server).formatting,
+	}
+	notifications = map[string]func(s *Server, params json.RawMessage) error{
+		"initialized":            func(s *Server, params json.RawMessage) error { return nil },
+		"textDocument/didOpen":   (*Server).didOpen,
+		"textDocument/didChange": (*Server).didChange,
+		"textDocument/didClose":  (*Server).didClose,
+	}
+}
+
+func New(in io.Reader, out io.Writer) *Server {
+	return &Server{in: bufio.NewReader(in), out: out, documents: make(map[string]*document)}
+}
+
+// serves requests until the client sends exit or closes the input
+func (s *Server) Run() error {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(json.RawMessage("null"), nil, &responseError{Code: PARSE_ERROR, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.dispatch(&req); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) dispatch(req *request) error {
+	// requests carry an id, notifications are never answered
+	if req.ID == nil {
+		if notify, ok := notifications[req.Method]; ok && !s.shutdown {
+			return notify(s, req.Params)
+		}
+		return nil
+	}
+
+	if s.shutdown {
+		return s.reply(req.ID, nil, &responseError{Code: INVALID_REQUEST, Message: "server is shutting down"})
+	}
+	handle, ok := handlers[req.Method]
+	if !ok {
+		return s.reply(req.ID, nil, &responseError{Code: METHOD_NOT_FOUND, Message: "method not found: " + req.Method})
+	}
+	result, rerr := handle(s, req.Params)
+	return s.reply(req.ID, result, rerr)
+}
+
+// reads the body of the next message, framed by a Content-Length header
+func (s *Server) read() ([]byte, error) {
+	headers, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading headers: %w", err)
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", headers.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, fmt.Errorf("reading body: %w", err)
+	}
+	return body, nil
+}
+
+func (s *Server) write(message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}, rerr *responseError) error {
+	resp := &response{JSONRPC: "2.0", ID: id, Error: rerr}
+	if rerr == nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = encoded
+	}
+	return s.write(resp)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func decode(params json.RawMessage, v interface{}) *responseError {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: INVALID_PARAMS, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///test.al"
+
+const src = `func add(a, b) {
+    return a + b
+}
+var total = add(1, 2)
+println(total)
+`
+
+type session struct {
+	input bytes.Buffer
+	id    int
+}
+
+func (s *session) send(method string, params interface{}, request bool) {
+	message := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if request {
+		s.id++
+		message["id"] = s.id
+	}
+	body, _ := json.Marshal(message)
+	fmt.Fprintf(&s.input, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// runs the session and returns every message the server wrote
+func (s *session) run(t *testing.T) []map[string]json.RawMessage {
+	var out bytes.Buffer
+	if err := New(&s.input, &out).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var messages []map[string]json.RawMessage
+	reader := bufio.NewReader(&out)
+	for {
+		var length int
+		if _, err := fmt.Fscanf(reader, "Content-Length: %d\r\n\r\n", &length); err != nil {
+			break
+		}
+		body := make([]byte, length)
+		io.ReadFull(reader, body)
+		var message map[string]json.RawMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func position(line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": line, "character": character},
+	}
+}
+
+func TestServer(t *testing.T) {
+	s := &session{}
+	s.send("initialize", map[string]interface{}{}, true)
+	s.send("initialized", map[string]interface{}{}, false)
+	s.send("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "alpha", "version": 1, "text": src},
+	}, false)
+	s.send("textDocument/definition", position(4, 9), true)
+	s.send("textDocument/references", map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     map[string]int{"line": 0, "character": 6},
+		"context":      map[string]bool{"includeDeclaration": true},
+	}, true)
+	s.send("textDocument/hover", position(3, 13), true)
+	s.send("textDocument/completion", position(1, 4), true)
+	s.send("textDocument/documentSymbol", map[string]interface{}{"textDocument": map[string]string{"uri": uri}}, true)
+	s.send("textDocument/formatting", map[string]interface{}{"textDocument": map[string]string{"uri": uri}}, true)
+	s.send("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "var x = y\n"}},
+	}, false)
+	s.send("unknown/method", map[string]interface{}{}, true)
+	s.send("shutdown", nil, true)
+	s.send("exit", nil, false)
+
+	messages := s.run(t)
+	if len(messages) != 11 {
+		t.Fatalf("got %d messages, want 11", len(messages))
+	}
+
+	tests := []struct {
+		name    string
+		message int
+		field   string
+		want    string
+	}{
+		{"capabilities", 0, "result", `"hoverProvider":true`},
+		{"clean diagnostics", 1, "params", `"diagnostics":[]`},
+		{"definition", 2, "result", `"range":{"start":{"line":3,"character":4},"end":{"line":3,"character":9}}`},
+		{"references", 3, "result", `{"start":{"line":0,"character":5},"end":{"line":0,"character":8}}},{"uri":"file:///test.al","range":{"start":{"line":3,"character":12}`},
+		{"hover", 4, "result", "func add(a, b)"},
+		{"completion parameter", 5, "result", `{"label":"a","kind":6,"detail":"param a"}`},
+		{"completion builtin", 5, "result", `{"label":"println","kind":3,"detail":"builtin"}`},
+		{"completion keyword", 5, "result", `{"label":"while","kind":14}`},
+		{"document symbols", 6, "result", `"name":"add","detail":"(a, b)","kind":12`},
+		{"formatting", 7, "result", `[]`},
+		{"change diagnostics", 8, "params", `"message":"undefined: y"`},
+		{"unknown method", 9, "error", `"code":-32601`},
+		{"shutdown", 10, "result", `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(messages[tt.message][tt.field])
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("message %d %s = %s, want it to contain %s", tt.message, tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	s := &session{}
+	s.send("exit", nil, false)
+	if err := New(&s.input, &bytes.Buffer{}).Run(); err != ErrExitWithoutShutdown {
+		t.Errorf("Run() error = %v, want %v", err, ErrExitWithoutShutdown)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	d := newDocument(uri, "var s = \"é😀\" + x\n")
+	// x starts at byte 20 but at utf-16 unit 16
+	if got := d.position(1, 20); got != (Position{Line: 0, Character: 16}) {
+		t.Errorf("position() = %v", got)
+	}
+	if line, column := d.location(Position{Line: 0, Character: 16}); line != 1 || column != 20 {
+		t.Errorf("location() = %d:%d, want 1:20", line, column)
+	}
+}
@@ -0,0 +1,138 @@
+package objects
+
+import (
+	"fmt"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+)
+
+// the parameters a call is matched against, shared by the evaluator and the
+// checker so both agree on which calls fit
+type Signature struct {
+	Name   string
+	Params []string
+	// parameters with a default can be left out
+	Optional []bool
+	// the last parameter collects the positional arguments left over
+	Variadic bool
+}
+
+// why a call does not fit a signature
+type ArityError struct {
+	Message string
+	// the named argument the error is about, -1 when it is the whole call
+	Named int
+}
+
+// the signature of a declared function
+func FunctionSignature(name string, params []ast.Parameter) *Signature {
+	signature := &Signature{Name: name}
+	for _, param := range params {
+		signature.Params = append(signature.Params, param.Ident.Ident)
+		signature.Optional = append(signature.Optional, param.Default != nil)
+		signature.Variadic = param.Variadic
+	}
+	return signature
+}
+
+// the number of parameters before a variadic one
+func (s *Signature) Fixed() int {
+	if s.Variadic {
+		return len(s.Params) - 1
+	}
+	return len(s.Params)
+}
+
+// matches the arguments of a call to the parameters, first by position and
+// then by name, positional arguments are numbered first and named ones after
+// them. each fixed parameter gets the number of its argument, or -1 when it
+// is left to its default, the positional arguments past them go to a
+// variadic last parameter
+func (s *Signature) Bind(positional int, named []string) ([]int, *ArityError) {
+	fixed := s.Fixed()
+	required := 0
+	for i := 0; i < fixed; i++ {
+		if !s.optional(i) {
+			required++
+		}
+	}
+
+	if positional > fixed && !s.Variadic {
+		if required == fixed {
+			return nil, s.error(-1, "%s expects %s, got %d", s.Name, Arguments(fixed), positional)
+		}
+		return nil, s.error(-1, "%s expects at most %s, got %d", s.Name, Arguments(fixed), positional)
+	}
+
+	bound := make([]int, fixed)
+	for i := range bound {
+		bound[i] = -1
+		if i < positional {
+			bound[i] = i
+		}
+	}
+	for n, name := range named {
+		i := s.index(name)
+		if i < 0 {
+			return nil, s.error(n, "%s has no parameter %s", s.Name, name)
+		}
+		if bound[i] >= 0 {
+			return nil, s.error(n, "%s got two values for %s", s.Name, name)
+		}
+		bound[i] = positional + n
+	}
+
+	for i := 0; i < fixed; i++ {
+		if bound[i] >= 0 || s.optional(i) {
+			continue
+		}
+		switch {
+		case len(named) > 0:
+			return nil, s.error(-1, "%s is missing argument %s", s.Name, s.Params[i])
+		case required == fixed && !s.Variadic:
+			return nil, s.error(-1, "%s expects %s, got %d", s.Name, Arguments(fixed), positional)
+		default:
+			return nil, s.error(-1, "%s expects at least %s, got %d", s.Name, Arguments(required), positional)
+		}
+	}
+	return bound, nil
+}
+
+func (s *Signature) optional(i int) bool {
+	return i < len(s.Optional) && s.Optional[i]
+}
+
+// the fixed parameter with the name, -1 when there is none
+func (s *Signature) index(name string) int {
+	for i, param := range s.Params[:s.Fixed()] {
+		if param == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Signature) error(named int, format string, a ...interface{}) *ArityError {
+	return &ArityError{Message: fmt.Sprintf(format, a...), Named: named}
+}
+
+// checks got arguments against the bounds a builtin was registered with,
+// returns "" when they fit
+func CountError(name string, min, max, got int) string {
+	switch {
+	case min == max && got != min:
+		return fmt.Sprintf("%s expects %s, got %d", name, Arguments(min), got)
+	case got < min:
+		return fmt.Sprintf("%s expects at least %s, got %d", name, Arguments(min), got)
+	case max >= 0 && got > max:
+		return fmt.Sprintf("%s expects at most %s, got %d", name, Arguments(max), got)
+	}
+	return ""
+}
+
+func Arguments(n int) string {
+	if n == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", n)
+}
@@ -1,6 +1,9 @@
 package objects
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 type Environment struct {
 	store map[string]Object
@@ -31,6 +34,36 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// names bound directly in this environment, sorted
+func (e *Environment) Locals() []string {
+	var names []string
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// names bound in this environment and every enclosing one, sorted
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (e *Environment) String() string {
 	var out string
 	var pairs []string
@@ -0,0 +1,63 @@
+package objects
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// capabilities a program has to be given to use the modules that reach
+// outside the interpreter
+const (
+	FS_CAPABILITY      = "fs"
+	PROCESS_CAPABILITY = "process"
+	NET_CAPABILITY     = "net"
+)
+
+var Capabilities = []string{FS_CAPABILITY, PROCESS_CAPABILITY, NET_CAPABILITY}
+
+// builtins grouped under a name and reached with fs.read_file, using them
+// needs the capability of the module when it has one
+type Module struct {
+	Name       string
+	Capability string
+	Members    map[string]Object
+}
+
+func (m *Module) Type() ObjectType { return MODULE }
+func (m *Module) Inspect() string  { return "<module " + m.Name + ">" }
+
+// a file opened by fs.open, read and written a line or a string at a time
+type File struct {
+	Path   string
+	Handle *os.File
+	Reader *bufio.Reader
+	Closed bool
+	// read_line, eof, write and close bound to the file
+	Methods map[string]Object
+}
+
+func (f *File) Type() ObjectType { return FILE }
+func (f *File) Inspect() string  { return "<file " + f.Path + ">" }
+
+// values produced one at a time, like the rows of csv_rows, through its
+// next, done and close methods
+type Iterator struct {
+	Name    string
+	Methods map[string]Object
+}
+
+func (it *Iterator) Type() ObjectType { return ITERATOR }
+func (it *Iterator) Inspect() string  { return "<iterator " + it.Name + ">" }
+
+// a pattern compiled by regex("..."), matched with its methods
+type Regex struct {
+	Pattern string
+	Regexp  *regexp.Regexp
+	// match, find_all, captures, replace and split bound to the pattern
+	Methods map[string]Object
+}
+
+func (r *Regex) Type() ObjectType { return REGEX }
+func (r *Regex) Inspect() string  { return "regex(" + strconv.Quote(r.Pattern) + ")" }
@@ -3,6 +3,9 @@ package objects
 import (
 	"fmt"
 	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
 	"strconv"
 	"strings"
 
@@ -24,12 +27,23 @@ const (
 	NULL         = "NULL"
 	RETURN_VALUE = "RETURN_VALUE"
 	ERROR        = "ERROR"
+	ERROR_VALUE  = "ERROR_VALUE"
 	FUNCTION     = "FUNCTION"
 	BUILTIN      = "BUILTIN"
 	ARRAY        = "ARRAY"
 	HASH         = "HASH"
 	HASHKEY      = "HASHKEY"
 	HASHPAIR     = "HASHPAIR"
+	STRUCT_TYPE  = "STRUCT_TYPE"
+	STRUCT       = "STRUCT"
+	ENUM         = "ENUM"
+	ENUM_VALUE   = "ENUM_VALUE"
+	MODULE       = "MODULE"
+	FILE         = "FILE"
+	ITERATOR     = "ITERATOR"
+	REGEX        = "REGEX"
+	TIME         = "TIME"
+	DURATION     = "DURATION"
 )
 
 type Integer struct {
@@ -81,16 +95,76 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+// kinds of errors raised by the interpreter
+const (
+	GENERIC_ERROR   = "Error"
+	TYPE_ERROR      = "TypeError"
+	NAME_ERROR      = "NameError"
+	VALUE_ERROR     = "ValueError"
+	ARGUMENT_ERROR  = "ArgumentError"
+	ASSERTION_ERROR = "AssertionError"
+	IO_ERROR        = "IOError"
+	// using a module the program was not given the capability for
+	PERMISSION_ERROR = "PermissionError"
+	// not an error, made by exit() to stop the program
+	EXIT = "Exit"
+)
+
+// an error being raised, it unwinds the program until a try catches it
 type Error struct {
 	Message string
+	Kind    string
+	// where the error happened, 0 when unknown
+	Line   int
+	Column int
+	// calls in progress when it happened, innermost first
+	Stack []StackFrame
+	// stops the program, try does not catch it
+	Fatal bool
+	// the status the program exits with when Kind is EXIT
+	Code int
+}
+
+type StackFrame struct {
+	Name string
+	Line int
 }
 
 func (e *Error) Type() ObjectType { return ERROR }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// an error caught by a try, it is an ordinary value until thrown again
+type ErrorValue struct {
+	Err *Error
+}
+
+func (ev *ErrorValue) Type() ObjectType { return ERROR_VALUE }
+func (ev *ErrorValue) Inspect() string  { return ev.Err.Kind + ": " + ev.Err.Message }
+
+var ErrorFields = []string{"message", "kind", "line", "stack"}
+
+// the fields of the error available by indexing, e["message"]
+func (ev *ErrorValue) Field(name string) (Object, bool) {
+	switch name {
+	case "message":
+		return &String{Value: ev.Err.Message}, true
+	case "kind":
+		return &String{Value: ev.Err.Kind}, true
+	case "line":
+		return &Integer{Value: int64(ev.Err.Line)}, true
+	case "stack":
+		var frames []Object
+		for _, frame := range ev.Err.Stack {
+			frames = append(frames, &String{Value: fmt.Sprintf("%s:%d", frame.Name, frame.Line)})
+		}
+		return &Array{Elements: frames}, true
+	}
+	return nil, false
+}
+
 type Function struct {
 	Name       string
-	Parameters []ast.IdentiferLiteral
+	Parameters []ast.Parameter
 	Body       ast.BlockStatement
 	Env        Environment
 }
@@ -117,8 +191,32 @@ func (f *Function) String() string {
 
 type BuiltinFunction func(args ...Object) Object
 
+// lets builtins call functions written in AlphaLang
+type Interpreter interface {
+	Call(fn Object, args ...Object) Object
+	// reports whether the program was given the capability
+	Allows(capability string) bool
+	// the generator shared by rand and the random module
+	Random() *rand.Rand
+	// where print and println write, and where programs report errors
+	Output() io.Writer
+	ErrorOutput() io.Writer
+	// the arguments given to the program after its file name
+	ScriptArgs() []string
+	// reads a line for input after showing the prompt
+	ReadLine(prompt string) (string, error)
+}
+
 type Builtin struct {
 	Fn BuiltinFunction
+	// used instead of Fn by builtins that call back into the interpreter
+	Native func(interp Interpreter, args ...Object) Object
+	// how many arguments the checker lets a call pass, MaxArgs of -1
+	// allows any number
+	MinArgs int
+	MaxArgs int
+	// type of the result for the checker, empty when it is not known
+	Returns string
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN }
@@ -133,18 +231,7 @@ func (ao *Array) Type() ObjectType { return ARRAY }
 func (ao *Array) Inspect() string  { return ao.String() }
 
 func (ao *Array) String() string {
-	var out string
-
-	elements := []string{}
-	for _, e := range ao.Elements {
-		elements = append(elements, e.Inspect())
-	}
-
-	out += "["
-	out += strings.Join(elements, ", ")
-	out += "]"
-
-	return out
+	return inspect(ao, map[*Struct]bool{})
 }
 
 type HashKey struct {
@@ -163,6 +250,39 @@ type HashPair struct {
 
 type Hash struct {
 	Pairs map[HashKey]HashPair
+	// keys in the order they were first set
+	Keys []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// sets the pair under the key, a new key goes after the others
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.Pairs[key]; !ok {
+		h.Keys = append(h.Keys, key)
+	}
+	h.Pairs[key] = pair
+}
+
+// the pairs in the order their keys were first set, pairs that were added to
+// Pairs directly come last
+func (h *Hash) Ordered() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	seen := make(map[HashKey]bool, len(h.Pairs))
+	for _, key := range h.Keys {
+		if pair, ok := h.Pairs[key]; ok && !seen[key] {
+			seen[key] = true
+			pairs = append(pairs, pair)
+		}
+	}
+	for key, pair := range h.Pairs {
+		if !seen[key] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
 }
 
 func (h *Hash) HashKey() HashKey {
@@ -172,20 +292,276 @@ func (h *Hash) Type() ObjectType { return HASH }
 func (h *Hash) Inspect() string  { return h.String() }
 
 func (h *Hash) String() string {
-	var out string
+	return inspect(h, map[*Struct]bool{})
+}
+
+// a declared struct, calling it builds an instance from the field values in
+// order
+type StructType struct {
+	Name    string
+	Fields  []string
+	Methods map[string]*Function
+}
+
+func (st *StructType) Type() ObjectType { return STRUCT_TYPE }
+func (st *StructType) Inspect() string {
+	return "struct " + st.Name + " { " + strings.Join(st.Fields, ", ") + " }"
+}
+
+func (st *StructType) HasField(name string) bool {
+	for _, field := range st.Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+type Struct struct {
+	Def    *StructType
+	Fields map[string]Object
+}
+
+func (s *Struct) Type() ObjectType { return STRUCT }
+func (s *Struct) Inspect() string  { return s.String() }
 
-	pairs := []string{}
-	for _, p := range h.Pairs {
-		pairs = append(pairs, p.Key.Inspect()+":"+p.Value.Inspect())
+func (s *Struct) String() string {
+	return inspect(s, map[*Struct]bool{})
+}
+
+// a declared enum, its variants are reached with Shape.Circle
+type Enum struct {
+	Name     string
+	Variants []string
+	// names of the values each variant carries
+	Fields map[string][]string
+}
+
+func (en *Enum) Type() ObjectType { return ENUM }
+func (en *Enum) Inspect() string {
+	var variants []string
+	for _, name := range en.Variants {
+		if fields := en.Fields[name]; len(fields) > 0 {
+			name += "(" + strings.Join(fields, ", ") + ")"
+		}
+		variants = append(variants, name)
 	}
+	return "enum " + en.Name + " { " + strings.Join(variants, ", ") + " }"
+}
+
+func (en *Enum) HasVariant(name string) bool {
+	_, ok := en.Fields[name]
+	return ok
+}
+
+type EnumValue struct {
+	Enum    *Enum
+	Variant string
+	Values  []Object
+}
+
+func (ev *EnumValue) Type() ObjectType { return ENUM_VALUE }
+func (ev *EnumValue) Inspect() string  { return ev.String() }
 
-	out += "{"
-	out += strings.Join(pairs, ", ")
-	out += "}"
+func (ev *EnumValue) String() string {
+	return inspect(ev, map[*Struct]bool{})
+}
+
+// writes values holding other values, a struct met again inside itself is
+// written as Name{...}
+func inspect(obj Object, visiting map[*Struct]bool) string {
+	var out string
+
+	switch obj := obj.(type) {
+	case *Array:
+		elements := []string{}
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, visiting))
+		}
+		out += "["
+		out += strings.Join(elements, ", ")
+		out += "]"
+	case *Hash:
+		pairs := []string{}
+		for _, p := range obj.Ordered() {
+			pairs = append(pairs, p.Key.Inspect()+":"+inspect(p.Value, visiting))
+		}
+		out += "{"
+		out += strings.Join(pairs, ", ")
+		out += "}"
+	case *Struct:
+		if visiting[obj] {
+			return obj.Def.Name + "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		fields := []string{}
+		for _, name := range obj.Def.Fields {
+			fields = append(fields, name+": "+inspect(obj.Fields[name], visiting))
+		}
+		out += obj.Def.Name
+		out += "{"
+		out += strings.Join(fields, ", ")
+		out += "}"
+	case *EnumValue:
+		out += obj.Enum.Name + "." + obj.Variant
+		if len(obj.Values) > 0 {
+			values := []string{}
+			for _, value := range obj.Values {
+				values = append(values, inspect(value, visiting))
+			}
+			out += "(" + strings.Join(values, ", ") + ")"
+		}
+	case nil:
+		out += "null"
+	default:
+		out += obj.Inspect()
+	}
 
 	return out
 }
 
+// the value carried under a field name of the variant
+func (ev *EnumValue) Field(name string) (Object, bool) {
+	for i, field := range ev.Enum.Fields[ev.Variant] {
+		if field == name {
+			return ev.Values[i], true
+		}
+	}
+	return nil, false
+}
+
 func NewError(format string, a ...interface{}) *Error {
-	return &Error{Message: fmt.Sprintf(format, a...)}
+	return &Error{Message: fmt.Sprintf(format, a...), Kind: GENERIC_ERROR}
+}
+
+func NewErrorKind(kind, format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...), Kind: kind}
+}
+
+// the value of an integer or a float as a float64
+func Number(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *Float:
+		return obj.Value, true
+	}
+	return 0, false
+}
+
+// the product and whether it fits in an int64
+func Multiply(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || a == -1 && b == math.MinInt64 || b == -1 && a == math.MinInt64 {
+		return 0, false
+	}
+	return product, true
+}
+
+// the negation and whether it fits in an int64, -MinInt64 does not
+func Negate(a int64) (int64, bool) {
+	if a == math.MinInt64 {
+		return 0, false
+	}
+	return -a, true
+}
+
+func IntegerOverflow(left int64, operator string, right int64) *Error {
+	return NewErrorKind(VALUE_ERROR, "integer overflow: %d %s %d", left, operator, right)
+}
+
+// reports whether two values are the same, arrays and hashes are compared
+// element by element and functions by identity
+func Equal(a, b Object) bool {
+	return equal(a, b, map[[2]*Struct]bool{})
+}
+
+// structs already being compared are taken to be equal, so structs that
+// hold themselves are only compared once
+func equal(a, b Object, comparing map[[2]*Struct]bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value == b.(*Integer).Value
+	case *Float:
+		return a.Value == b.(*Float).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *Null:
+		return true
+	case *Array:
+		other := b.(*Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !equal(a.Elements[i], other.Elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		other := b.(*Hash)
+		if len(a.Pairs) != len(other.Pairs) {
+			return false
+		}
+		for key, pair := range a.Pairs {
+			otherPair, ok := other.Pairs[key]
+			if !ok || !equal(pair.Value, otherPair.Value, comparing) {
+				return false
+			}
+		}
+		return true
+	case *Struct:
+		other := b.(*Struct)
+		if a.Def != other.Def {
+			return false
+		}
+		pair := [2]*Struct{a, other}
+		if comparing[pair] {
+			return true
+		}
+		comparing[pair] = true
+		for _, name := range a.Def.Fields {
+			if !equal(a.Fields[name], other.Fields[name], comparing) {
+				return false
+			}
+		}
+		return true
+	case *EnumValue:
+		other := b.(*EnumValue)
+		if a.Enum != other.Enum || a.Variant != other.Variant {
+			return false
+		}
+		for i := range a.Values {
+			if !equal(a.Values[i], other.Values[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *Error:
+		return a.Message == b.(*Error).Message
+	case *ErrorValue:
+		other := b.(*ErrorValue)
+		return a.Err.Kind == other.Err.Kind && a.Err.Message == other.Err.Message
+	case *Regex:
+		return a.Pattern == b.(*Regex).Pattern
+	case *Time:
+		return a.Value.Equal(b.(*Time).Value)
+	case *Duration:
+		return a.Value == b.(*Duration).Value
+	}
+	return a == b
 }
@@ -0,0 +1,72 @@
+package objects
+
+import (
+	"time"
+)
+
+// an instant in a time zone, made by now() and parse_time
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() ObjectType { return TIME }
+func (t *Time) Inspect() string  { return t.Value.Format(time.RFC3339Nano) }
+
+// the names Field knows, for dir
+var TimeFields = []string{"year", "month", "day", "hour", "minute", "second", "nanosecond", "weekday", "yearday", "unix", "zone"}
+
+// the parts of the time available with t.year
+func (t *Time) Field(name string) (Object, bool) {
+	switch name {
+	case "year":
+		return &Integer{Value: int64(t.Value.Year())}, true
+	case "month":
+		return &Integer{Value: int64(t.Value.Month())}, true
+	case "day":
+		return &Integer{Value: int64(t.Value.Day())}, true
+	case "hour":
+		return &Integer{Value: int64(t.Value.Hour())}, true
+	case "minute":
+		return &Integer{Value: int64(t.Value.Minute())}, true
+	case "second":
+		return &Integer{Value: int64(t.Value.Second())}, true
+	case "nanosecond":
+		return &Integer{Value: int64(t.Value.Nanosecond())}, true
+	case "weekday":
+		return &String{Value: t.Value.Weekday().String()}, true
+	case "yearday":
+		return &Integer{Value: int64(t.Value.YearDay())}, true
+	case "unix":
+		return &Integer{Value: t.Value.Unix()}, true
+	case "zone":
+		return &String{Value: t.Value.Location().String()}, true
+	}
+	return nil, false
+}
+
+// a length of time, made by duration("1h30m") or by subtracting times
+type Duration struct {
+	Value time.Duration
+}
+
+func (d *Duration) Type() ObjectType { return DURATION }
+func (d *Duration) Inspect() string  { return d.Value.String() }
+
+var DurationFields = []string{"hours", "minutes", "seconds", "milliseconds", "nanoseconds"}
+
+// the length in different units, available with d.seconds
+func (d *Duration) Field(name string) (Object, bool) {
+	switch name {
+	case "hours":
+		return &Float{Value: d.Value.Hours()}, true
+	case "minutes":
+		return &Float{Value: d.Value.Minutes()}, true
+	case "seconds":
+		return &Float{Value: d.Value.Seconds()}, true
+	case "milliseconds":
+		return &Integer{Value: d.Value.Milliseconds()}, true
+	case "nanoseconds":
+		return &Integer{Value: int64(d.Value)}, true
+	}
+	return nil, false
+}
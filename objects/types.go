@@ -0,0 +1,77 @@
+package objects
+
+// names used by type annotations, structs and enums are named by their
+// declaration
+const (
+	INT_TYPE      = "int"
+	FLOAT_TYPE    = "float"
+	STRING_TYPE   = "string"
+	BOOL_TYPE     = "bool"
+	NULL_TYPE     = "null"
+	ARRAY_TYPE    = "array"
+	HASH_TYPE     = "hash"
+	FUNC_TYPE     = "func"
+	ERROR_TYPE    = "error"
+	MODULE_TYPE   = "module"
+	FILE_TYPE     = "file"
+	ITER_TYPE     = "iterator"
+	REGEX_TYPE    = "regex"
+	TIME_TYPE     = "time"
+	DURATION_TYPE = "duration"
+	// accepts any value, the same as leaving the annotation out
+	ANY_TYPE = "any"
+)
+
+var typeNames = map[ObjectType]string{
+	INT:         INT_TYPE,
+	FLOAT:       FLOAT_TYPE,
+	STRING:      STRING_TYPE,
+	BOOLEAN:     BOOL_TYPE,
+	NULL:        NULL_TYPE,
+	ARRAY:       ARRAY_TYPE,
+	HASH:        HASH_TYPE,
+	FUNCTION:    FUNC_TYPE,
+	BUILTIN:     FUNC_TYPE,
+	STRUCT_TYPE: FUNC_TYPE,
+	ERROR_VALUE: ERROR_TYPE,
+	MODULE:      MODULE_TYPE,
+	FILE:        FILE_TYPE,
+	ITERATOR:    ITER_TYPE,
+	REGEX:       REGEX_TYPE,
+	TIME:        TIME_TYPE,
+	DURATION:    DURATION_TYPE,
+}
+
+// the type annotation that describes the value
+func TypeName(obj Object) string {
+	switch obj := obj.(type) {
+	case nil:
+		return NULL_TYPE
+	case *Struct:
+		return obj.Def.Name
+	case *EnumValue:
+		return obj.Enum.Name
+	}
+	if name, ok := typeNames[obj.Type()]; ok {
+		return name
+	}
+	return ANY_TYPE
+}
+
+// reports whether the value can be used where the annotation is
+func Satisfies(obj Object, typ string) bool {
+	return typ == ANY_TYPE || TypeName(obj) == typ
+}
+
+// reports whether the name is a type every program has
+func IsBuiltinType(name string) bool {
+	if name == ANY_TYPE {
+		return true
+	}
+	for _, typ := range typeNames {
+		if typ == name {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/EVFUBS/AlphaLang/ast"
 	"github.com/EVFUBS/AlphaLang/lexer"
@@ -13,16 +16,33 @@ type Parser struct {
 	curToken  *token.Token
 	nextToken *token.Token
 	errors    []ParserError
+	warnings  []ParserError
+	comments  []token.Token
+	// enums declared so far and every match, to check matches cover every
+	// variant once the whole program is parsed
+	enums   map[string]*ast.EnumStatement
+	matches []*ast.MatchExpression
+	// set while parsing a match guard, where => ends the guard instead of
+	// starting an arrow function
+	guard bool
 }
 
-type ParserError string
+type ParserError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (pe ParserError) String() string {
+	return fmt.Sprintf("%d:%d: %s", pe.Line, pe.Column, pe.Message)
+}
 
 type InfixFunction func(node ast.AstExpression) ast.AstExpression
 type PrefixFunction func() ast.AstExpression
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l}
-	p.nextToken = p.l.NextToken()
+	p := &Parser{l: l, enums: make(map[string]*ast.EnumStatement)}
+	p.readToken()
 	return p
 }
 
@@ -30,13 +50,47 @@ func (p *Parser) Errors() []ParserError {
 	return p.errors
 }
 
-func (p *Parser) addError(msg string) {
-	p.errors = append(p.errors, ParserError(msg))
+// problems that do not stop the program from running
+func (p *Parser) Warnings() []ParserError {
+	return p.warnings
+}
+
+func (p *Parser) addError(tok *token.Token, msg string) {
+	p.errors = append(p.errors, ParserError{Line: tok.Line, Column: tok.Column, Message: msg})
 }
 
 func (p *Parser) AdvanceToken() {
 	p.curToken = p.nextToken
+	p.readToken()
+}
+
+// comments are kept aside on the program rather than parsed
+func (p *Parser) readToken() {
 	p.nextToken = p.l.NextToken()
+	for p.nextToken.Type == token.COMMENT {
+		p.comments = append(p.comments, *p.nextToken)
+		p.nextToken = p.l.NextToken()
+	}
+}
+
+// lexes and parses source code, returning the lexer and parser errors
+// joined into one if it is invalid
+func Parse(code string) (*ast.Program, error) {
+	l := lexer.New(code)
+	p := New(l)
+	program := p.ParseProgram()
+
+	var errs []string
+	for _, err := range l.Errors() {
+		errs = append(errs, err.String())
+	}
+	for _, err := range p.Errors() {
+		errs = append(errs, err.String())
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return program, nil
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -46,6 +100,8 @@ func (p *Parser) ParseProgram() *ast.Program {
 		statement := p.Parse()
 		program.Statements = append(program.Statements, *statement)
 	}
+	program.Comments = p.comments
+	p.checkExhaustive()
 
 	return &program
 }
@@ -54,6 +110,7 @@ func (p *Parser) Parse() *ast.AstStatement {
 	var statement ast.AstStatement
 
 	p.AdvanceToken()
+	start := p.curToken
 
 	switch p.curToken.Type {
 	case token.VAR:
@@ -66,22 +123,90 @@ func (p *Parser) Parse() *ast.AstStatement {
 		statement = p.ParseForStatement()
 	case token.WHILE:
 		statement = p.parseWhileStatement()
+	case token.TRY:
+		statement = p.parseTryStatement()
+	case token.THROW:
+		statement = p.parseThrowStatement()
+	case token.STRUCT:
+		statement = p.parseStructStatement()
+	case token.ENUM:
+		statement = p.parseEnumStatement()
 	default:
 		statement = p.ParseExpressionStatement()
 	}
 
+	span := statement.Lines()
+	span.StartLine = start.Line
+	span.StartColumn = start.Column
+	span.EndLine = p.curToken.Line
+
 	return &statement
 }
 
 // Expression Parsing
+const (
+	_ int = iota
+	LOWEST
+	ASSIGN      // += -=
+	EQUALS      // == !=
+	LESSGREATER // < > <= >=
+	BITOR       // |
+	BITXOR      // ^
+	BITAND      // &
+	SHIFT       // << >>
+	SUM         // + -
+	PRODUCT     // * / %
+	PREFIX      // -x !x ~x
+	POWER       // **
+	CALL        // f(x) a[x] a.x
+)
+
+var precedence = map[token.TokenType]int{
+	token.INCREMENT: ASSIGN,
+	token.DECREMENT: ASSIGN,
+	token.EQUAL:     EQUALS,
+	token.NOTEQUAL:  EQUALS,
+	token.LTHAN:     LESSGREATER,
+	token.GTHAN:     LESSGREATER,
+	token.GEQUAL:    LESSGREATER,
+	token.LEQUAL:    LESSGREATER,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.ASTERISK:  PRODUCT,
+	token.SLASH:     PRODUCT,
+	token.MODULUS:   PRODUCT,
+	token.POWER:     POWER,
+	token.PIPE:      BITOR,
+	token.CARET:     BITXOR,
+	token.AMPERSAND: BITAND,
+	token.LSHIFT:    SHIFT,
+	token.RSHIFT:    SHIFT,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  CALL,
+	token.DOT:       CALL,
+}
+
 func (p *Parser) ParseExpression() ast.AstExpression {
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseExpression(prec int) ast.AstExpression {
 	var node ast.AstExpression
 
 	switch p.curToken.Type {
 	case token.IDENT:
-		node = p.ParseIdentiferLiteral()
+		if p.nextTokenIs(token.ARROW) && prec == LOWEST && !p.guard {
+			start := p.curToken
+			node = p.parseArrowFunction(start, []ast.Parameter{{Ident: *p.ParseIdentiferLiteral()}})
+		} else {
+			node = p.ParseIdentiferLiteral()
+		}
+	case token.FUNCTION:
+		node = p.parseFunctionExpression()
 	case token.INTEGER:
 		node = p.ParseIntegerLiteral()
+	case token.FLOAT:
+		node = p.ParseFloatLiteral()
 	case token.TRUE:
 		node = p.ParseBoolLiteral()
 	case token.FALSE:
@@ -92,17 +217,26 @@ func (p *Parser) ParseExpression() ast.AstExpression {
 		node = p.ParseArrayLiteral()
 	case token.LBRACE:
 		node = p.parseHashLiteral()
+	case token.MATCH:
+		node = p.parseMatchExpression()
 	}
 
 	var prefixOperations = map[token.TokenType]PrefixFunction{
-		token.BANG:  p.parsePrefixExpression,
-		token.MINUS: p.parsePrefixExpression,
+		token.BANG:   p.parsePrefixExpression,
+		token.MINUS:  p.parsePrefixExpression,
+		token.TILDE:  p.parsePrefixExpression,
+		token.LPAREN: p.parseGroupedExpression,
 	}
 
 	if prefixOperations[p.curToken.Type] != nil {
 		node = prefixOperations[p.curToken.Type]()
 	}
 
+	if node == nil {
+		p.addError(p.curToken, "unexpected token "+p.curToken.String())
+		return nil
+	}
+
 	// precedence parsing
 	var infixOperations = map[token.TokenType]InfixFunction{
 		token.PLUS:      p.parseInfixExpression,
@@ -110,6 +244,12 @@ func (p *Parser) ParseExpression() ast.AstExpression {
 		token.ASTERISK:  p.parseInfixExpression,
 		token.SLASH:     p.parseInfixExpression,
 		token.MODULUS:   p.parseInfixExpression,
+		token.POWER:     p.parseInfixExpression,
+		token.AMPERSAND: p.parseInfixExpression,
+		token.PIPE:      p.parseInfixExpression,
+		token.CARET:     p.parseInfixExpression,
+		token.LSHIFT:    p.parseInfixExpression,
+		token.RSHIFT:    p.parseInfixExpression,
 		token.EQUAL:     p.parseInfixExpression,
 		token.NOTEQUAL:  p.parseInfixExpression,
 		token.LTHAN:     p.parseInfixExpression,
@@ -120,26 +260,31 @@ func (p *Parser) ParseExpression() ast.AstExpression {
 		token.INCREMENT: p.parseInfixExpression,
 		token.DECREMENT: p.parseInfixExpression,
 		token.LBRACKET:  p.parseIndexExpression,
+		token.DOT:       p.parseMemberExpression,
 	}
-	if _, ok := infixOperations[p.nextToken.Type]; ok {
-		node = infixOperations[p.nextToken.Type](node)
-		if infix, ok := node.(*ast.InfixExpression); ok {
-			node = p.sortPrecedence(infix)
+	for prec < p.nextPrecedence() {
+		infix, ok := infixOperations[p.nextToken.Type]
+		if !ok {
+			return node
 		}
-		return node
+		node = infix(node)
 	}
 
 	return node
 }
 
 // Infix Parsing
-// need to consider precedence
 func (p *Parser) parseInfixExpression(node ast.AstExpression) ast.AstExpression {
 	left := node
 	p.AdvanceToken()
 	operator := p.curToken
+	prec := p.curPrecedence()
+	// assignment operators and ** are right associative
+	if prec == ASSIGN || prec == POWER {
+		prec--
+	}
 	p.AdvanceToken()
-	right := p.ParseExpression()
+	right := p.parseExpression(prec)
 	newNode := &ast.InfixExpression{
 		Left:     left,
 		Operator: operator,
@@ -151,38 +296,117 @@ func (p *Parser) parseInfixExpression(node ast.AstExpression) ast.AstExpression
 func (p *Parser) parsePrefixExpression() ast.AstExpression {
 	operator := p.curToken
 	p.AdvanceToken()
-	expression := p.ParseExpression()
+	expression := p.parseExpression(PREFIX)
 	return &ast.PrefixExpression{
 		Prefix:     operator,
 		Expression: expression,
 	}
 }
 
-var precedence = map[token.TokenType]int{
-	token.PLUS:     1,
-	token.MINUS:    2,
-	token.ASTERISK: 3,
-	token.SLASH:    4,
+// parses an expression in parentheses, or the parameters of an arrow
+// function, (a, b = 1, ...rest) => a + b
+func (p *Parser) parseGroupedExpression() ast.AstExpression {
+	start := p.curToken
+	p.AdvanceToken()
+	items := p.parseList(token.RPAREN, p.parseArgument)
+	if p.nextTokenIs(token.ARROW) && !p.guard {
+		return p.parseArrowFunction(start, p.arrowParameters(items))
+	}
+	if len(items) != 1 {
+		p.addError(start, "Expected one expression in parentheses")
+		return nil
+	}
+	switch item := items[0].(type) {
+	case *ast.NamedArgument, *ast.SpreadExpression:
+		p.addError(start, "Expected expression but got "+item.String())
+		return nil
+	}
+	return items[0]
 }
 
-func (p *Parser) sortPrecedence(node *ast.InfixExpression) *ast.InfixExpression {
-	// if right node is infix expression
-	if right, ok := node.Right.(*ast.InfixExpression); ok {
-		// if right node has higher precedence
-		if precedence[node.Operator.Type] > precedence[right.Operator.Type] {
-			// swap
-			node.Right = right.Left
-			right.Left = node
-			return p.sortPrecedence(right)
+// turns what was parsed as the items in parentheses into parameters
+func (p *Parser) arrowParameters(items []ast.AstExpression) []ast.Parameter {
+	var params []ast.Parameter
+	for _, item := range items {
+		var param ast.Parameter
+		switch item := item.(type) {
+		case *ast.IdentiferLiteral:
+			param.Ident = *item
+		case *ast.NamedArgument:
+			param.Ident = item.Name
+			param.Default = item.Value
+		case *ast.SpreadExpression:
+			ident, ok := item.Value.(*ast.IdentiferLiteral)
+			if !ok {
+				p.addError(&item.Token, "Expected parameter name after ...")
+				continue
+			}
+			param.Ident = *ident
+			param.Variadic = true
+		default:
+			p.addError(p.curToken, "Expected parameter but got "+item.String())
+			continue
 		}
+		p.checkParameter(params, &param)
+		params = append(params, param)
 	}
-	return node
+	return params
+}
+
+// parses what follows the parameters of an arrow function, its body is a
+// single expression that is returned
+func (p *Parser) parseArrowFunction(start *token.Token, params []ast.Parameter) ast.AstExpression {
+	function := &ast.FunctionLiteral{Token: *start, Parameters: params, Arrow: true}
+	p.AdvanceToken()
+	p.AdvanceToken()
+
+	body := p.curToken
+	statement := &ast.ReturnStatement{ReturnValue: p.ParseExpression()}
+	statement.StartLine = body.Line
+	statement.StartColumn = body.Column
+	statement.EndLine = p.curToken.Line
+	function.Body = ast.BlockStatement{Span: statement.Span, Statements: []ast.AstStatement{statement}}
+	return function
+}
+
+// parses a function used as a value, it has no name
+func (p *Parser) parseFunctionExpression() ast.AstExpression {
+	function := p.ParseFunctionLiteral()
+	if function.Name != "" {
+		p.addError(&function.Token, "a function used as a value has no name, write func(...)")
+	}
+	return function
+}
+
+func Precedence(tokenType token.TokenType) int {
+	if prec, ok := precedence[tokenType]; ok {
+		return prec
+	}
+	return LOWEST
+}
+
+func (p *Parser) nextPrecedence() int {
+	return Precedence(p.nextToken.Type)
+}
+
+func (p *Parser) curPrecedence() int {
+	return Precedence(p.curToken.Type)
 }
 
 func (p *Parser) parseCallExpression(node ast.AstExpression) ast.AstExpression {
 	p.AdvanceToken()
 	p.AdvanceToken()
-	list := p.parseExpressionList(token.RPAREN)
+	named := false
+	list := p.parseList(token.RPAREN, func() ast.AstExpression {
+		tok := p.curToken
+		arg := p.parseArgument()
+		if _, ok := arg.(*ast.NamedArgument); ok {
+			named = true
+		} else if named {
+			p.addError(tok, "positional argument after a named argument")
+		}
+		return arg
+	})
 	return &ast.CallExpression{
 		Function:  node,
 		Arguments: list,
@@ -200,22 +424,39 @@ func (p *Parser) parseIndexExpression(node ast.AstExpression) ast.AstExpression
 	}
 }
 
+func (p *Parser) parseMemberExpression(node ast.AstExpression) ast.AstExpression {
+	p.AdvanceToken()
+	// keywords can name members, like re.match
+	if token.KeywordLookUp(p.nextToken.Literal) != token.IDENT {
+		p.AdvanceToken()
+		p.curToken.Type = token.IDENT
+	} else {
+		p.CheckTokenAdvance(token.IDENT)
+	}
+	return &ast.MemberExpression{
+		Left:   node,
+		Member: *p.ParseIdentiferLiteral(),
+	}
+}
+
 func (p *Parser) parseHashLiteral() ast.AstExpression {
+	var keys []ast.AstExpression
 	hash := make(map[ast.AstExpression]ast.AstExpression)
-	p.AdvanceToken()
-	for p.nextToken.Type != token.RBRACE {
-		key := p.ParseExpression()
+	for !p.nextTokenIs(token.RBRACE) && !p.nextTokenIs(token.EOF) {
 		p.AdvanceToken()
+		key := p.ParseExpression()
+		p.CheckTokenAdvance(token.COLON)
 		p.AdvanceToken()
 		value := p.ParseExpression()
+		keys = append(keys, key)
 		hash[key] = value
-		if p.nextToken.Type == token.COMMA {
-			p.AdvanceToken()
+		if p.nextTokenIs(token.COMMA) {
 			p.AdvanceToken()
 		}
 	}
-	p.AdvanceToken()
+	p.CheckTokenAdvance(token.RBRACE)
 	return &ast.HashLiteral{
+		Keys:  keys,
 		Pairs: hash,
 	}
 }
@@ -226,13 +467,17 @@ func (p *Parser) ParseVarStatement() *ast.VarStatement {
 	var statement *ast.VarStatement
 
 	if p.curToken.Type != token.IDENT {
-		p.errors = append(p.errors, ParserError("Expected identifier"))
-		return nil
+		p.addError(p.curToken, "Expected identifier")
+		return &ast.VarStatement{}
 	}
 
 	statement = &ast.VarStatement{
 		Identifer: *p.ParseIdentiferLiteral(),
 	}
+	if p.nextTokenIs(token.COLON) {
+		p.AdvanceToken()
+		statement.Type = p.parseTypeName()
+	}
 
 	p.CheckTokenAdvance(token.ASSIGN)
 	p.AdvanceToken()
@@ -243,33 +488,30 @@ func (p *Parser) ParseVarStatement() *ast.VarStatement {
 }
 
 func (p *Parser) ParseReturnStatement() *ast.ReturnStatement {
-	p.AdvanceToken()
-	statement := &ast.ReturnStatement{
-		ReturnValue: p.ParseExpression(),
+	statement := &ast.ReturnStatement{}
+	if p.nextTokenIs(token.RBRACE) || p.nextTokenIs(token.EOF) {
+		return statement
 	}
 
+	p.AdvanceToken()
+	statement.ReturnValue = p.ParseExpression()
+
 	return statement
 }
 
 func (p *Parser) ParseExpressionStatement() *ast.ExpressionStatement {
 	var exprStatement ast.ExpressionStatement
 
-	switch p.curToken.Type {
-	case token.FUNCTION:
+	switch {
+	case p.curToken.Type == token.FUNCTION && !p.nextTokenIs(token.LPAREN):
 		exprStatement.Expression = p.ParseFunctionLiteral()
-	}
-
-	if p.curToken.Type == token.IDENT && p.nextToken.Type == token.ASSIGN {
+	case p.curToken.Type == token.IDENT && p.nextToken.Type == token.ASSIGN:
 		exprStatement.Expression = p.parseReassignment()
-	}
-
-	switch p.nextToken.Type {
-	case token.LPAREN:
-		exprStatement.Expression = p.ParseExpression()
-	case token.INCREMENT:
-		exprStatement.Expression = p.ParseExpression()
-	case token.DECREMENT:
+	default:
 		exprStatement.Expression = p.ParseExpression()
+		if member, ok := exprStatement.Expression.(*ast.MemberExpression); ok && p.nextTokenIs(token.ASSIGN) {
+			exprStatement.Expression = p.parseFieldAssignment(member)
+		}
 	}
 
 	return &exprStatement
@@ -277,10 +519,17 @@ func (p *Parser) ParseExpressionStatement() *ast.ExpressionStatement {
 
 func (p *Parser) ParseBlockStatement() *ast.BlockStatement {
 	var statements ast.BlockStatement
+	statements.StartLine = p.curToken.Line
+	statements.StartColumn = p.curToken.Column
 	for p.nextToken.Type != token.RBRACE {
+		if p.nextTokenIs(token.EOF) {
+			p.addError(p.nextToken, "Expected } but got "+p.nextToken.String())
+			break
+		}
 		statement := p.Parse()
 		statements.Statements = append(statements.Statements, *statement)
 	}
+	statements.EndLine = p.nextToken.Line
 
 	return &statements
 }
@@ -290,10 +539,13 @@ func (p *Parser) ParseIfStatement() *ast.IfStatement {
 	var IfStatement ast.IfStatement
 
 	p.AdvanceToken()
+	IfStatement.If.StartLine = p.curToken.Line
+	IfStatement.If.StartColumn = p.curToken.Column
 	IfStatement.If.Condition = p.ParseExpression()
 	p.CheckTokenAdvance(token.LBRACE)
 	IfStatement.If.Consequence = *p.ParseBlockStatement()
 	p.CheckTokenAdvance(token.RBRACE)
+	IfStatement.If.EndLine = p.curToken.Line
 
 	if p.nextTokenIs(token.ELIF) {
 		for {
@@ -301,10 +553,13 @@ func (p *Parser) ParseIfStatement() *ast.IfStatement {
 
 			p.CheckTokenAdvance(token.ELIF)
 			p.AdvanceToken()
+			conditional.StartLine = p.curToken.Line
+			conditional.StartColumn = p.curToken.Column
 			conditional.Condition = p.ParseExpression()
 			p.CheckTokenAdvance(token.LBRACE)
 			conditional.Consequence = *p.ParseBlockStatement()
 			p.CheckTokenAdvance(token.RBRACE)
+			conditional.EndLine = p.curToken.Line
 
 			IfStatement.Elif = append(IfStatement.Elif, conditional)
 
@@ -328,7 +583,12 @@ func (p *Parser) ParseForStatement() *ast.ForStatement {
 	var ForStatement ast.ForStatement
 
 	p.AdvanceToken()
-	ForStatement.Initializer = p.ParseVarStatement()
+	start := p.curToken
+	initializer := p.ParseVarStatement()
+	initializer.StartLine = start.Line
+	initializer.StartColumn = start.Column
+	initializer.EndLine = p.curToken.Line
+	ForStatement.Initializer = initializer
 	p.CheckTokenAdvance(token.SEMICOLON)
 	p.CheckTokenAdvance(token.IDENT)
 	ForStatement.Conditional = p.ParseExpression()
@@ -354,6 +614,294 @@ func (p *Parser) parseWhileStatement() *ast.WhileStatement {
 
 // while x < 10 {println(x)}
 
+func (p *Parser) parseTryStatement() *ast.TryStatement {
+	var TryStatement ast.TryStatement
+	start := p.curToken
+	p.CheckTokenAdvance(token.LBRACE)
+	TryStatement.Body = *p.ParseBlockStatement()
+	p.CheckTokenAdvance(token.RBRACE)
+
+	if p.nextTokenIs(token.CATCH) {
+		p.AdvanceToken()
+		if p.nextTokenIs(token.LPAREN) {
+			p.AdvanceToken()
+			p.CheckTokenAdvance(token.IDENT)
+			TryStatement.CatchIdent = p.ParseIdentiferLiteral()
+			p.CheckTokenAdvance(token.RPAREN)
+		}
+		p.CheckTokenAdvance(token.LBRACE)
+		TryStatement.Catch = p.ParseBlockStatement()
+		p.CheckTokenAdvance(token.RBRACE)
+	}
+
+	if p.nextTokenIs(token.FINALLY) {
+		p.AdvanceToken()
+		p.CheckTokenAdvance(token.LBRACE)
+		TryStatement.Finally = p.ParseBlockStatement()
+		p.CheckTokenAdvance(token.RBRACE)
+	}
+
+	if TryStatement.Catch == nil && TryStatement.Finally == nil {
+		p.addError(start, "Expected catch or finally after try block")
+	}
+	return &TryStatement
+}
+
+// try { risky() } catch (e) { println(e) } finally { cleanup() }
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	var ThrowStatement ast.ThrowStatement
+	p.AdvanceToken()
+	ThrowStatement.Value = p.ParseExpression()
+	return &ThrowStatement
+}
+
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	var StructStatement ast.StructStatement
+	p.CheckTokenAdvance(token.IDENT)
+	StructStatement.Token = *p.curToken
+	StructStatement.Name = p.curToken.Literal
+	p.CheckTokenAdvance(token.LBRACE)
+
+	names := make(map[string]bool)
+	for !p.nextTokenIs(token.RBRACE) && !p.nextTokenIs(token.EOF) {
+		p.AdvanceToken()
+		switch p.curToken.Type {
+		case token.COMMA:
+		case token.IDENT:
+			field := p.ParseIdentiferLiteral()
+			if names[field.Ident] {
+				p.addError(p.curToken, "duplicate field "+field.Ident+" in struct "+StructStatement.Name)
+			}
+			names[field.Ident] = true
+			StructStatement.Fields = append(StructStatement.Fields, *field)
+		case token.FUNCTION:
+			method := p.ParseFunctionLiteral()
+			if names[method.Name] {
+				p.addError(&method.Token, "duplicate field "+method.Name+" in struct "+StructStatement.Name)
+			}
+			if len(method.Parameters) == 0 {
+				p.addError(&method.Token, "method "+method.Name+" needs a receiver parameter")
+			}
+			names[method.Name] = true
+			StructStatement.Methods = append(StructStatement.Methods, method)
+		default:
+			p.addError(p.curToken, "Expected field or method but got "+p.curToken.String())
+		}
+	}
+	p.CheckTokenAdvance(token.RBRACE)
+	return &StructStatement
+}
+
+func (p *Parser) parseEnumStatement() *ast.EnumStatement {
+	var EnumStatement ast.EnumStatement
+	p.CheckTokenAdvance(token.IDENT)
+	EnumStatement.Token = *p.curToken
+	EnumStatement.Name = p.curToken.Literal
+	p.CheckTokenAdvance(token.LBRACE)
+
+	names := make(map[string]bool)
+	for !p.nextTokenIs(token.RBRACE) && !p.nextTokenIs(token.EOF) {
+		p.AdvanceToken()
+		if p.curToken.Type == token.COMMA {
+			continue
+		}
+		if p.curToken.Type != token.IDENT {
+			p.addError(p.curToken, "Expected variant but got "+p.curToken.String())
+			continue
+		}
+		variant := ast.EnumVariant{Token: *p.curToken, Name: p.curToken.Literal}
+		if names[variant.Name] {
+			p.addError(p.curToken, "duplicate variant "+variant.Name+" in enum "+EnumStatement.Name)
+		}
+		names[variant.Name] = true
+
+		if p.nextTokenIs(token.LPAREN) {
+			p.AdvanceToken()
+			for !p.nextTokenIs(token.RPAREN) && !p.nextTokenIs(token.EOF) {
+				p.CheckTokenAdvance(token.IDENT)
+				variant.Fields = append(variant.Fields, *p.ParseIdentiferLiteral())
+				if !p.nextTokenIs(token.RPAREN) {
+					p.CheckTokenAdvance(token.COMMA)
+				}
+			}
+			p.CheckTokenAdvance(token.RPAREN)
+		}
+		EnumStatement.Variants = append(EnumStatement.Variants, variant)
+	}
+	p.CheckTokenAdvance(token.RBRACE)
+
+	p.enums[EnumStatement.Name] = &EnumStatement
+	return &EnumStatement
+}
+
+// enum Shape { Circle(r), Rect(w, h), Empty }
+
+func (p *Parser) parseMatchExpression() ast.AstExpression {
+	match := &ast.MatchExpression{Token: *p.curToken}
+	p.AdvanceToken()
+	match.Subject = p.ParseExpression()
+	p.CheckTokenAdvance(token.LBRACE)
+
+	for !p.nextTokenIs(token.RBRACE) && !p.nextTokenIs(token.EOF) {
+		var arm ast.MatchArm
+		p.AdvanceToken()
+		arm.Pattern = p.parsePattern()
+		if p.nextTokenIs(token.IF) {
+			p.AdvanceToken()
+			p.AdvanceToken()
+			p.guard = true
+			arm.Guard = p.ParseExpression()
+			p.guard = false
+		}
+		p.CheckTokenAdvance(token.ARROW)
+		p.AdvanceToken()
+		arm.Body = p.ParseExpression()
+		match.Arms = append(match.Arms, arm)
+		if p.nextTokenIs(token.COMMA) {
+			p.AdvanceToken()
+		}
+	}
+	p.CheckTokenAdvance(token.RBRACE)
+
+	p.matches = append(p.matches, match)
+	return match
+}
+
+// match shape { Shape.Circle(r) => r * r, Shape.Rect(w, h) if w == h => w * w, _ => 0 }
+
+func (p *Parser) parsePattern() ast.Pattern {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.curToken.Literal == "_" {
+			return &ast.WildcardPattern{Token: *p.curToken}
+		}
+		if p.nextTokenIs(token.DOT) {
+			return p.parseVariantPattern()
+		}
+		return &ast.BindingPattern{Ident: *p.ParseIdentiferLiteral()}
+	case token.INTEGER, token.FLOAT, token.STRING, token.TRUE, token.FALSE, token.MINUS:
+		value := p.parseExpression(PREFIX)
+		if !p.nextTokenIs(token.DOTDOT) {
+			return &ast.LiteralPattern{Value: value}
+		}
+		p.AdvanceToken()
+		p.AdvanceToken()
+		return &ast.RangePattern{Low: value, High: p.parseExpression(PREFIX)}
+	case token.LBRACKET:
+		elements, rest := p.parsePatternList(token.RBRACKET)
+		return &ast.ArrayPattern{Elements: elements, Rest: rest}
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+	p.addError(p.curToken, "Expected pattern but got "+p.curToken.String())
+	return &ast.WildcardPattern{Token: *p.curToken}
+}
+
+func (p *Parser) parseVariantPattern() ast.Pattern {
+	pattern := &ast.VariantPattern{Enum: *p.ParseIdentiferLiteral()}
+	p.AdvanceToken()
+	p.CheckTokenAdvance(token.IDENT)
+	pattern.Variant = *p.ParseIdentiferLiteral()
+	if p.nextTokenIs(token.LPAREN) {
+		p.AdvanceToken()
+		var rest *ast.IdentiferLiteral
+		pattern.Fields, rest = p.parsePatternList(token.RPAREN)
+		if rest != nil {
+			p.addError(&rest.Token, "a rest pattern can only end an array pattern")
+		}
+	}
+	return pattern
+}
+
+// parses patterns up to the end token, the last one may be ...rest
+func (p *Parser) parsePatternList(end token.TokenType) ([]ast.Pattern, *ast.IdentiferLiteral) {
+	var patterns []ast.Pattern
+	var rest *ast.IdentiferLiteral
+	for !p.nextTokenIs(end) && !p.nextTokenIs(token.EOF) {
+		p.AdvanceToken()
+		if p.curToken.Type == token.ELLIPSIS {
+			p.CheckTokenAdvance(token.IDENT)
+			rest = p.ParseIdentiferLiteral()
+			break
+		}
+		patterns = append(patterns, p.parsePattern())
+		if !p.nextTokenIs(end) {
+			p.CheckTokenAdvance(token.COMMA)
+		}
+	}
+	p.CheckTokenAdvance(end)
+	return patterns, rest
+}
+
+func (p *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{}
+	for !p.nextTokenIs(token.RBRACE) && !p.nextTokenIs(token.EOF) {
+		p.AdvanceToken()
+		pattern.Keys = append(pattern.Keys, p.parseExpression(PREFIX))
+		p.CheckTokenAdvance(token.COLON)
+		p.AdvanceToken()
+		pattern.Values = append(pattern.Values, p.parsePattern())
+		if !p.nextTokenIs(token.RBRACE) {
+			p.CheckTokenAdvance(token.COMMA)
+		}
+	}
+	p.CheckTokenAdvance(token.RBRACE)
+	return pattern
+}
+
+// warns about matches on a declared enum that leave out some of its variants
+// and have no arm that matches anything
+func (p *Parser) checkExhaustive() {
+	for _, match := range p.matches {
+		var enum *ast.EnumStatement
+		covered := make(map[string]bool)
+		catchAll := false
+		for _, arm := range match.Arms {
+			switch pattern := arm.Pattern.(type) {
+			case *ast.WildcardPattern, *ast.BindingPattern:
+				catchAll = catchAll || arm.Guard == nil
+			case *ast.VariantPattern:
+				if declared, ok := p.enums[pattern.Enum.Ident]; ok {
+					enum = declared
+				}
+				if arm.Guard == nil && irrefutable(pattern.Fields) {
+					covered[pattern.Variant.Ident] = true
+				}
+			}
+		}
+		if enum == nil || catchAll {
+			continue
+		}
+
+		var missing []string
+		for _, variant := range enum.Variants {
+			if !covered[variant.Name] {
+				missing = append(missing, variant.Name)
+			}
+		}
+		if len(missing) > 0 {
+			p.warnings = append(p.warnings, ParserError{
+				Line:    match.Token.Line,
+				Column:  match.Token.Column,
+				Message: "match on " + enum.Name + " is not exhaustive, missing " + strings.Join(missing, ", "),
+			})
+		}
+	}
+}
+
+// reports whether the patterns match any value
+func irrefutable(patterns []ast.Pattern) bool {
+	for _, pattern := range patterns {
+		switch pattern.(type) {
+		case *ast.WildcardPattern, *ast.BindingPattern:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // Literal Parsing
 func (p *Parser) ParseIdentiferLiteral() *ast.IdentiferLiteral {
 	return &ast.IdentiferLiteral{
@@ -365,7 +913,7 @@ func (p *Parser) ParseIdentiferLiteral() *ast.IdentiferLiteral {
 func (p *Parser) ParseIntegerLiteral() *ast.IntegerLiteral {
 	intVal, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		//return some kind of error
+		p.addError(p.curToken, "could not parse "+p.curToken.Literal+" as integer")
 	}
 	return &ast.IntegerLiteral{
 		Token: *p.curToken,
@@ -373,6 +921,17 @@ func (p *Parser) ParseIntegerLiteral() *ast.IntegerLiteral {
 	}
 }
 
+func (p *Parser) ParseFloatLiteral() *ast.FloatLiteral {
+	floatVal, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		p.addError(p.curToken, "could not parse "+p.curToken.Literal+" as float")
+	}
+	return &ast.FloatLiteral{
+		Token: *p.curToken,
+		Value: floatVal,
+	}
+}
+
 func (p *Parser) ParseBoolLiteral() *ast.BooleanLiteral {
 	if p.curToken.Type == token.TRUE {
 		return &ast.BooleanLiteral{
@@ -396,28 +955,89 @@ func (p *Parser) ParseStringLiteral() *ast.StringLiteral {
 func (p *Parser) ParseFunctionLiteral() *ast.FunctionLiteral {
 	var function ast.FunctionLiteral
 
-	p.CheckTokenAdvance(token.IDENT)
-	function.Name = p.curToken.Literal
+	if p.nextTokenIs(token.LPAREN) {
+		function.Token = *p.curToken
+	} else {
+		p.CheckTokenAdvance(token.IDENT)
+		function.Token = *p.curToken
+		function.Name = p.curToken.Literal
+	}
 	p.AdvanceToken()
 	p.AdvanceToken()
 
-	for p.curToken.Type != token.RPAREN {
+	for p.curToken.Type != token.RPAREN && p.curToken.Type != token.EOF {
 		if p.curToken.Type == token.COMMA {
 			p.AdvanceToken()
 		}
-		if p.curToken.Type == token.IDENT {
-			function.Parameters = append(function.Parameters, *p.ParseIdentiferLiteral())
-			p.AdvanceToken()
+		param, ok := p.parseParameter(function.Parameters)
+		if !ok {
+			break
 		}
+		function.Parameters = append(function.Parameters, param)
+		p.AdvanceToken()
+	}
+	if p.nextTokenIs(token.RARROW) {
+		p.AdvanceToken()
+		function.ReturnType = p.parseTypeName()
 	}
 
-	p.AdvanceToken()
+	p.CheckTokenAdvance(token.LBRACE)
 	function.Body = *p.ParseBlockStatement()
-	p.AdvanceToken()
+	p.CheckTokenAdvance(token.RBRACE)
 
 	return &function
 }
 
+// parses a parameter after those already in the list, a, b: int, c = 1 or
+// ...rest
+func (p *Parser) parseParameter(previous []ast.Parameter) (ast.Parameter, bool) {
+	var param ast.Parameter
+	if p.curToken.Type == token.ELLIPSIS {
+		param.Variadic = true
+		p.AdvanceToken()
+	}
+	if p.curToken.Type != token.IDENT {
+		p.addError(p.curToken, "Expected IDENT but got "+p.curToken.String())
+		return param, false
+	}
+	param.Ident = *p.ParseIdentiferLiteral()
+
+	if p.nextTokenIs(token.COLON) {
+		p.AdvanceToken()
+		param.Type = p.parseTypeName()
+	}
+	if p.nextTokenIs(token.ASSIGN) {
+		p.AdvanceToken()
+		p.AdvanceToken()
+		param.Default = p.ParseExpression()
+	}
+	p.checkParameter(previous, &param)
+	return param, true
+}
+
+// checks a parameter can follow the ones before it
+func (p *Parser) checkParameter(previous []ast.Parameter, param *ast.Parameter) {
+	if len(previous) > 0 && previous[len(previous)-1].Variadic {
+		p.addError(&param.Ident.Token, "rest parameter "+previous[len(previous)-1].Ident.Ident+" must be the last parameter")
+	}
+	if param.Variadic && param.Default != nil {
+		p.addError(&param.Ident.Token, "rest parameter "+param.Ident.Ident+" cannot have a default")
+	}
+	if !param.Variadic && param.Default == nil && len(previous) > 0 && previous[len(previous)-1].Default != nil {
+		p.addError(&param.Ident.Token, "parameter "+param.Ident.Ident+" needs a default, it follows a parameter with one")
+	}
+}
+
+// parses the type after a : or ->, func is a keyword but also names a type
+func (p *Parser) parseTypeName() *ast.TypeName {
+	p.AdvanceToken()
+	if p.curToken.Type != token.IDENT && p.curToken.Type != token.FUNCTION {
+		p.addError(p.curToken, "Expected type but got "+p.curToken.String())
+		return nil
+	}
+	return &ast.TypeName{Token: *p.curToken, Name: p.curToken.Literal}
+}
+
 func (p *Parser) ParseArrayLiteral() *ast.ArrayLiteral {
 	var array ast.ArrayLiteral
 
@@ -438,12 +1058,21 @@ func (p *Parser) parseReassignment() *ast.Reassignment {
 	}
 }
 
+func (p *Parser) parseFieldAssignment(target *ast.MemberExpression) *ast.FieldAssignment {
+	p.CheckTokenAdvance(token.ASSIGN)
+	p.AdvanceToken()
+	return &ast.FieldAssignment{
+		Target: target,
+		Value:  p.ParseExpression(),
+	}
+}
+
 // Helper Functions
 func (p *Parser) CheckTokenAdvance(wanted token.TokenType) {
 	if p.nextToken.Type != wanted {
 		//some kind of error handle
 		var newError string = "Expected " + string(wanted) + " but got " + p.nextToken.String()
-		p.addError(newError)
+		p.addError(p.nextToken, newError)
 	}
 	p.AdvanceToken()
 }
@@ -453,17 +1082,48 @@ func (p *Parser) nextTokenIs(wanted token.TokenType) bool {
 }
 
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.AstExpression {
+	return p.parseList(end, p.ParseExpression)
+}
+
+// parses the items of a comma separated list up to the end token
+func (p *Parser) parseList(end token.TokenType, parse func() ast.AstExpression) []ast.AstExpression {
 	var expressions []ast.AstExpression
+	// the list is closed by its end token, so a guard can hold arrows in it
+	guard := p.guard
+	p.guard = false
+	defer func() { p.guard = guard }()
 
 	for p.curToken.Type != end {
+		if p.curToken.Type == token.EOF {
+			p.addError(p.curToken, "Expected "+string(end)+" but got "+p.curToken.String())
+			break
+		}
 		if p.curToken.Type == token.COMMA {
 			p.AdvanceToken()
 		}
-		expressions = append(expressions, p.ParseExpression())
+		expressions = append(expressions, parse())
 		p.AdvanceToken()
 	}
 
 	return expressions
 }
 
+// parses an argument of a call, it can be passed by name or spread from an
+// array
+func (p *Parser) parseArgument() ast.AstExpression {
+	switch {
+	case p.curToken.Type == token.ELLIPSIS:
+		spread := &ast.SpreadExpression{Token: *p.curToken}
+		p.AdvanceToken()
+		spread.Value = p.ParseExpression()
+		return spread
+	case p.curToken.Type == token.IDENT && p.nextTokenIs(token.ASSIGN):
+		name := *p.ParseIdentiferLiteral()
+		p.AdvanceToken()
+		p.AdvanceToken()
+		return &ast.NamedArgument{Name: name, Value: p.ParseExpression()}
+	}
+	return p.ParseExpression()
+}
+
 // func test(a,b){return a}
@@ -2,9 +2,11 @@ package parser
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/lexer"
 )
 
 func TestParser_ParseStringLiteral(t *testing.T) {
@@ -23,3 +25,148 @@ func TestParser_ParseStringLiteral(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_ParseExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1 + 2 * 3", "InfixExpr(Integer(1)+InfixExpr(Integer(2)*Integer(3)))"},
+		{"10 - 2 - 3", "InfixExpr(InfixExpr(Integer(10)-Integer(2))-Integer(3))"},
+		{"(1 + 2) * 3", "InfixExpr(InfixExpr(Integer(1)+Integer(2))*Integer(3))"},
+		{"-a * b", "InfixExpr(Prefix(-Ident(a))*Ident(b))"},
+		{"a + 1 == b", "InfixExpr(InfixExpr(Ident(a)+Integer(1))==Ident(b))"},
+		{"f(1) + arr[0]", "InfixExpr(Call(Ident(f)Arguments(Integer(1))+Index(Ident(arr)Integer(0)))"},
+		{"x += 1 + 2", "InfixExpr(Ident(x)+=InfixExpr(Integer(1)+Integer(2)))"},
+		{"2.5 * x1", "InfixExpr(Float(2.5)*Ident(x1))"},
+		{"!(a == b)", "Prefix(!InfixExpr(Ident(a)==Ident(b)))"},
+		{"x => x * 2", "Func( \nParams(Ident(x)) \nBlockStatement(\nReturnStatement(InfixExpr(Ident(x)*Integer(2)))\n)\n)"},
+		{"f(1)(2)", "Call(Call(Ident(f)Arguments(Integer(1))Arguments(Integer(2))"},
+		{"(a, ...b) => a", "Func( \nParams(Ident(a)...Ident(b)) \nBlockStatement(\nReturnStatement(Ident(a))\n)\n)"},
+		{"-p.x * a.b.c()", "InfixExpr(Prefix(-Member(Ident(p)Ident(x)))*Call(Member(Member(Ident(a)Ident(b))Ident(c))Arguments())"},
+		{"re.match(s)", "Call(Member(Ident(re)Ident(match))Arguments(Ident(s))"},
+		{"2 ** 3 ** 2", "InfixExpr(Integer(2)**InfixExpr(Integer(3)**Integer(2)))"},
+		{"-2 ** 2", "Prefix(-InfixExpr(Integer(2)**Integer(2)))"},
+		{"a | b ^ c & d << 1", "InfixExpr(Ident(a)|InfixExpr(Ident(b)^InfixExpr(Ident(c)&InfixExpr(Ident(d)<<Integer(1)))))"},
+		{"~a >> 2 == b", "InfixExpr(InfixExpr(Prefix(~Ident(a))>>Integer(2))==Ident(b))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.AdvanceToken()
+			got := p.ParseExpression()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("ParseExpression() errors = %v", p.Errors())
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseExpression() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseStruct(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{"struct P { x, y }", "Struct(PFields(Ident(x)Ident(y)))", ""},
+		{"struct P {\n    x\n    func get(self) {\n        return self.x\n    }\n}", "Struct(PFields(Ident(x))Func( \nget", ""},
+		{"p.x = 1", "AssignField(Member(Ident(p)Ident(x))Integer(1))", ""},
+		{"struct P { x, x }", "", "1:15: duplicate field x in struct P"},
+		{"struct P { func f() {} }", "", "1:17: method f needs a receiver parameter"},
+		{"enum E { A, B(x, y) }", "Enum(EVariant(A)Variant(BIdent(x)Ident(y)))", ""},
+		{"enum E { A, A }", "", "1:13: duplicate variant A in enum E"},
+		{"match x { E.B(a, _) if a > 1 => a, [h, ...t] => h, {\"k\": 1..5} => 0, -1 => 1, n => n }",
+			"Match(Ident(x)Arm(Variant(E.BBind(a)_)If(InfixExpr(Ident(a)>Integer(1)))Ident(a))Arm(ArrayPattern(Bind(h)Rest(t))Ident(h))" +
+				"Arm(HashPattern(String(k)Range(Integer(1)Integer(5)))Integer(0))Arm(Prefix(-Integer(1))Integer(1))Arm(Bind(n)Ident(n)))", ""},
+		{"match x { 1 2 }", "", "1:13: Expected => but got Type: INTEGER Literal: 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			if tt.err != "" {
+				if len(p.Errors()) == 0 || p.Errors()[0].String() != tt.err {
+					t.Fatalf("errors = %v, want %s", p.Errors(), tt.err)
+				}
+				return
+			}
+			if len(p.Errors()) > 0 {
+				t.Fatalf("errors = %v", p.Errors())
+			}
+			if got := program.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("program = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseAnnotations(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   string
+	}{
+		{"var x: int = 1", "VarStatement(var Ident(x): Type(int) = Integer(1))", ""},
+		{"func f(a: int, b, c: func) -> P {}", "Func( \nfParams(Ident(a): Type(int)Ident(b)Ident(c): Type(func)) -> Type(P) \n", ""},
+		{"var x: = 1", "", "1:8: Expected type but got Type: = Literal: ="},
+		{"func f(a, b: int = 1, ...rest) {}", "Params(Ident(a)Ident(b): Type(int) = Integer(1)...Ident(rest))", ""},
+		{"f(1, ...xs, b = 2)", "Call(Ident(f)Arguments(Integer(1)Spread(Ident(xs))Named(Ident(b)Integer(2)))", ""},
+		{"func f(a = 1, b) {}", "", "1:15: parameter b needs a default, it follows a parameter with one"},
+		{"func f(...a, b) {}", "", "1:14: rest parameter a must be the last parameter"},
+		{"f(a = 1, 2)", "", "1:10: positional argument after a named argument"},
+		{"var f = func(x) { return x }", "VarStatement(var Ident(f) = Func( \nParams(Ident(x))", ""},
+		{"var f = func g() {}", "", "1:14: a function used as a value has no name, write func(...)"},
+		{"match x { n if n == m => n }", "Arm(Bind(n)If(InfixExpr(Ident(n)==Ident(m)))Ident(n))", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			if tt.err != "" {
+				if len(p.Errors()) == 0 || p.Errors()[0].String() != tt.err {
+					t.Fatalf("errors = %v, want %s", p.Errors(), tt.err)
+				}
+				return
+			}
+			if len(p.Errors()) > 0 {
+				t.Fatalf("errors = %v", p.Errors())
+			}
+			if got := program.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("program = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Exhaustive(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"enum E { A, B(x) }\nmatch e { E.A => 1 }", []string{"2:1: match on E is not exhaustive, missing B"}},
+		{"enum E { A, B(x) }\nmatch e { E.A => 1, E.B(1) => 2 }", []string{"2:1: match on E is not exhaustive, missing B"}},
+		{"enum E { A, B(x) }\nmatch e { E.A => 1, E.B(x) if x > 1 => 2 }", []string{"2:1: match on E is not exhaustive, missing B"}},
+		{"match e { E.A => 1 }\nenum E { A, B(x) }", []string{"1:1: match on E is not exhaustive, missing B"}},
+		{"enum E { A, B(x) }\nmatch e { E.A => 1, E.B(_) => 2 }", nil},
+		{"enum E { A, B(x) }\nmatch e { E.A => 1, _ => 2 }", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("errors = %v", p.Errors())
+			}
+			var got []string
+			for _, warning := range p.Warnings() {
+				got = append(got, warning.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("warnings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
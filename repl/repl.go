@@ -1,78 +1,236 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/EVFUBS/AlphaLang/builtins"
 	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/format"
 	"github.com/EVFUBS/AlphaLang/lexer"
+	"github.com/EVFUBS/AlphaLang/lineeditor"
+	"github.com/EVFUBS/AlphaLang/objects"
 	"github.com/EVFUBS/AlphaLang/parser"
+	"github.com/EVFUBS/AlphaLang/token"
 )
 
 const PROMPT = ">> "
+const CONTINUE_PROMPT = ".. "
+
+const HELP = `:env          show the variables bound in this session
+:reset        discard all variables and start again
+:load <file>  evaluate a file into this session
+:ast <code>   print the parsed ast of some code
+:tokens <code> print the tokens of some code
+:help         show this message
+:quit         leave the repl`
+
+const HISTORY_FILE = ".alpha_history"
+
+var COMMANDS = []string{":env", ":reset", ":load", ":ast", ":tokens", ":help", ":quit"}
+
+type Repl struct {
+	editor    *lineeditor.Editor
+	out       io.Writer
+	evaluator *evaluator.Evaluator
+	// capabilities the session is given, like fs
+	Capabilities map[string]bool
+	// seeds the generator when set, also after :reset
+	Seed *int64
+}
+
+func New(in io.Reader, out io.Writer) *Repl {
+	r := &Repl{
+		editor:    lineeditor.New(in, out),
+		out:       out,
+		evaluator: evaluator.New(),
+	}
+	r.editor.Complete = r.complete
+	return r
+}
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	for {
-		fmt.Printf(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
-			return
-		}
-		line := scanner.Text()
-		l := lexer.New(line)
+	New(in, out).Run()
+}
 
-		/* tok := l.NextToken()
-		for tok.Type != token.EOF {
-			println(tok.String())
-			tok = l.NextToken()
-		} */
+// gives the evaluator the capabilities, seed and output of the session
+func (r *Repl) configure() {
+	r.evaluator.Capabilities = r.Capabilities
+	r.evaluator.Stdout = r.out
+	r.evaluator.Stderr = r.out
+	r.evaluator.Stdin = r.editor
+	if r.Seed != nil {
+		r.evaluator.Seed(*r.Seed)
+	}
+}
 
-		p := parser.New(l)
-		ast := p.ParseProgram()
+func (r *Repl) Run() {
+	r.configure()
+	if r.editor.IsTerminal() {
+		if home, err := os.UserHomeDir(); err == nil {
+			historyFile := filepath.Join(home, HISTORY_FILE)
+			r.editor.LoadHistory(historyFile)
+			defer r.editor.SaveHistory(historyFile)
+		}
+	}
 
-		if len(p.Errors()) > 0 {
-			for _, err := range p.Errors() {
-				fmt.Println(err)
-			}
-		} else {
-			for _, statement := range ast.Statements {
-				fmt.Println(statement.String())
+	for {
+		code, ok := r.readInput()
+		if !ok {
+			return
+		}
+		if strings.HasPrefix(strings.TrimSpace(code), ":") {
+			if !r.command(strings.TrimSpace(code)) {
+				return
 			}
+			continue
+		}
+		if !r.eval(code) {
+			return
 		}
-
-		/* e := evaluator.New()
-		evaluated := e.Eval(ast, e.Env)
-		if evaluated != nil {
-			fmt.Println(evaluated.Inspect())
-		} */
 	}
 }
 
-func RunFile(file io.Reader) {
-	scanner := bufio.NewScanner(file)
+// reads lines until every opened bracket has been closed
+func (r *Repl) readInput() (string, bool) {
 	var code string
-	for scanner.Scan() {
-		line := scanner.Text()
-		line += "\n"
-		code += line
+	prompt := PROMPT
+	for {
+		line, err := r.editor.ReadLine(prompt)
+		if err == lineeditor.ErrInterrupted {
+			code = ""
+			prompt = PROMPT
+			continue
+		}
+		if err != nil {
+			return code, code != ""
+		}
+		code += line + "\n"
+		if strings.HasPrefix(strings.TrimSpace(code), ":") || depth(code) <= 0 {
+			return code, true
+		}
+		prompt = CONTINUE_PROMPT
 	}
+}
+
+func depth(code string) int {
+	depth := 0
 	l := lexer.New(code)
-	p := parser.New(l)
-	ast := p.ParseProgram()
-	/* if len(p.Errors()) > 0 {
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LBRACE, token.LPAREN, token.LBRACKET:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACKET:
+			depth--
+		}
+	}
+	return depth
+}
+
+// returns false when the program called exit
+func (r *Repl) eval(code string) bool {
+	p := parser.New(lexer.New(code))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintln(r.out, err)
+		}
+		return true
+	}
+
+	evaluated := r.evaluator.Eval(program, r.evaluator.Env)
+	if err, ok := evaluated.(*objects.Error); ok && err.Kind == objects.EXIT {
+		return false
+	}
+	if evaluated != nil && evaluated.Type() != objects.NULL {
+		fmt.Fprintln(r.out, evaluated.Inspect())
+	}
+	return true
+}
+
+// returns false when the repl should stop
+func (r *Repl) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, HELP)
+	case ":env":
+		for _, name := range r.evaluator.Env.Names() {
+			value, _ := r.evaluator.Env.Get(name)
+			fmt.Fprintf(r.out, "%s = %s\n", name, inspectShort(value))
+		}
+	case ":reset":
+		r.evaluator = evaluator.New()
+		r.configure()
+	case ":load":
+		file, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+			return true
+		}
+		return r.eval(string(file))
+	case ":ast":
+		p := parser.New(lexer.New(arg))
+		program := p.ParseProgram()
 		for _, err := range p.Errors() {
-			fmt.Println(err)
+			fmt.Fprintln(r.out, err)
 		}
+		for _, statement := range program.Statements {
+			fmt.Fprintln(r.out, statement.String())
+		}
+	case ":tokens":
+		l := lexer.New(arg)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			fmt.Fprintln(r.out, tok.String())
+		}
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, try :help\n", name)
+	}
+	return true
+}
+
+func (r *Repl) complete(word string) []string {
+	var names []string
+	if strings.HasPrefix(word, ":") {
+		names = COMMANDS
 	} else {
-		for _, statement := range ast.Statements {
-			fmt.Println(statement.String())
-		}
-	} */
-	e := evaluator.New()
-	evaluated := e.Eval(ast, e.Env)
-	if evaluated != nil {
-		fmt.Println(evaluated.Inspect())
+		names = append(names, token.Keywords()...)
+		for name := range builtins.BuiltIns {
+			names = append(names, name)
+		}
+		for name := range builtins.Modules {
+			names = append(names, name)
+		}
+		names = append(names, r.evaluator.Env.Names()...)
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, name := range names {
+		if strings.HasPrefix(name, word) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+func inspectShort(obj objects.Object) string {
+	if obj == nil {
+		return "null"
+	}
+	if fn, ok := obj.(*objects.Function); ok {
+		return format.Signature(fn.Name, fn.Parameters, nil)
 	}
+	return obj.Inspect()
 }
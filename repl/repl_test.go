@@ -0,0 +1,40 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runRepl(input string) string {
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	return strings.ReplaceAll(strings.ReplaceAll(out.String(), PROMPT, ""), CONTINUE_PROMPT, "")
+}
+
+func TestRepl_Start(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"expression", "1 + 2 * 3\n", "7\n"},
+		{"persistent variables", "var x = 5\nx * 2\n", "10\n"},
+		{"multi-line function", "func add(a, b) {\nreturn a + b\n}\nadd(2, 3)\n", "5\n"},
+		{"env", "var x = 1\nfunc f(a) { return a }\n:env\n", "f = func f(a)\nx = 1\n"},
+		{"env with null", "var x = println(\"a\")\n:env\n", "a\nx = null\n"},
+		{"null result", "func f() {}\nvar y = f()\nprintln(y)\nf()\n", "null\n"},
+		{"input", "var n = input(\"name? \")\nann\nn\n", "name? ann\n"},
+		{"reset", "var x = 1\n:reset\nx\n", "ERROR: identifier not found: x\n"},
+		{"tokens", ":tokens x += 1\n", "Type: IDENT Literal: x\nType: INCREMENT Literal: +=\nType: INTEGER Literal: 1\n"},
+		{"parse error", "var = 1\n", "1:5: Expected identifier\n"},
+		{"quit", ":quit\n1\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runRepl(tt.input); got != tt.want {
+				t.Errorf("Start() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writes results as JUnit XML with a test suite for each file
+func WriteJUnit(w io.Writer, results []*Result) error {
+	var suites junitSuites
+	index := make(map[string]int)
+	for _, result := range results {
+		i, ok := index[result.File]
+		if !ok {
+			i = len(suites.Suites)
+			index[result.File] = i
+			suites.Suites = append(suites.Suites, junitSuite{Name: result.File})
+		}
+		suite := &suites.Suites[i]
+
+		testCase := junitCase{
+			Name:      result.Name,
+			ClassName: result.File,
+			Time:      seconds(result.Duration.Seconds()),
+			SystemOut: result.Output,
+		}
+		if !result.Passed() {
+			text := result.Failure
+			if result.Line > 0 {
+				text = fmt.Sprintf("%s:%d:%d: %s", result.File, result.Line, result.Column, result.Failure)
+			}
+			testCase.Failure = &junitFailure{Message: firstLine(result.Failure), Text: text}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for i := range suites.Suites {
+		var total float64
+		for _, result := range results {
+			if result.File == suites.Suites[i].Name {
+				total += result.Duration.Seconds()
+			}
+		}
+		suites.Suites[i].Time = seconds(total)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
+
+func firstLine(text string) string {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			return text[:i]
+		}
+	}
+	return text
+}
@@ -0,0 +1,168 @@
+package testrunner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/EVFUBS/AlphaLang/ast"
+	"github.com/EVFUBS/AlphaLang/evaluator"
+	"github.com/EVFUBS/AlphaLang/objects"
+	"github.com/EVFUBS/AlphaLang/parser"
+)
+
+// files ending in this hold tests
+const FILE_SUFFIX = "_test.al"
+
+// functions starting with this and taking no parameters are tests
+const TEST_PREFIX = "test_"
+
+type Result struct {
+	File string
+	Name string
+	// empty when the test passed
+	Failure string
+	// position of the failure, 0 when unknown
+	Line     int
+	Column   int
+	Duration time.Duration
+	// what the test printed
+	Output string
+}
+
+func (r *Result) Passed() bool {
+	return r.Failure == ""
+}
+
+type Runner struct {
+	// only tests with a matching name run when set
+	Filter  *regexp.Regexp
+	Verbose bool
+	Out     io.Writer
+	// capabilities the tests are given, like fs
+	Capabilities map[string]bool
+	// seeds the generator of every test when set, so runs repeat
+	Seed *int64
+}
+
+// runs the tests of a file, each in a fresh environment, and reports them to
+// the runner's output
+func (r *Runner) RunFile(name, code string) ([]*Result, error) {
+	program, err := parser.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var results []*Result
+	for _, test := range Tests(program) {
+		if r.Filter != nil && !r.Filter.MatchString(test) {
+			continue
+		}
+		if r.Verbose {
+			fmt.Fprintf(r.Out, "=== RUN   %s\n", test)
+		}
+		result := r.run(program, test)
+		result.File = name
+		results = append(results, result)
+		r.report(result)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	elapsed := time.Since(start).Seconds()
+	switch {
+	case len(results) == 0:
+		fmt.Fprintf(r.Out, "?     %s [no tests to run]\n", name)
+	case failed > 0:
+		fmt.Fprintf(r.Out, "FAIL  %s %.3fs (%d of %d failed)\n", name, elapsed, failed, len(results))
+	default:
+		fmt.Fprintf(r.Out, "ok    %s %.3fs (%d passed)\n", name, elapsed, len(results))
+	}
+	return results, nil
+}
+
+func (r *Runner) report(result *Result) {
+	seconds := result.Duration.Seconds()
+	if result.Passed() {
+		if r.Verbose {
+			fmt.Fprintf(r.Out, "--- PASS: %s (%.2fs)\n", result.Name, seconds)
+			fmt.Fprint(r.Out, indent(result.Output))
+		}
+		return
+	}
+
+	fmt.Fprintf(r.Out, "--- FAIL: %s (%.2fs)\n", result.Name, seconds)
+	fmt.Fprint(r.Out, indent(result.Output))
+	position := result.File
+	if result.Line > 0 {
+		position = fmt.Sprintf("%s:%d:%d", result.File, result.Line, result.Column)
+	}
+	fmt.Fprint(r.Out, indent(position+": "+result.Failure+"\n"))
+}
+
+// names of the tests declared at the top level of a program, in order
+func Tests(program *ast.Program) []string {
+	var names []string
+	for _, statement := range program.Statements {
+		expression, ok := statement.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+		fn, ok := expression.Expression.(*ast.FunctionLiteral)
+		if ok && strings.HasPrefix(fn.Name, TEST_PREFIX) && len(fn.Parameters) == 0 {
+			names = append(names, fn.Name)
+		}
+	}
+	return names
+}
+
+// runs the whole file in a new evaluator and then calls the test, so tests
+// can not see what other tests did
+func (r *Runner) run(program *ast.Program, test string) *Result {
+	var output bytes.Buffer
+
+	result := &Result{Name: test}
+	start := time.Now()
+	e := evaluator.New()
+	e.Capabilities = r.Capabilities
+	e.Stdout = &output
+	e.Stderr = &output
+	e.Stdin = evaluator.NoInput
+	if r.Seed != nil {
+		e.Seed(*r.Seed)
+	}
+	evaluated := e.Eval(program, e.Env)
+	if err, ok := evaluated.(*objects.Error); ok {
+		result.fail("setup failed: ", err)
+	} else {
+		fn, _ := e.Env.Get(test)
+		if err, ok := e.Call(fn).(*objects.Error); ok {
+			result.fail("", err)
+		}
+	}
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+	return result
+}
+
+func (r *Result) fail(prefix string, err *objects.Error) {
+	r.Failure = prefix + err.Message
+	r.Line = err.Line
+	r.Column = err.Column
+}
+
+func indent(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return "    " + strings.Join(lines, "\n    ") + "\n"
+}
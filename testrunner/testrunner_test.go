@@ -0,0 +1,137 @@
+package testrunner
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const tests = `var count = 0
+
+func add(a, b) {
+    return a + b
+}
+
+func test_add() {
+    count += 1
+    assert_eq(add(1, 2), 3)
+    assert(count == 1, "tests share state")
+}
+
+func test_isolated() {
+    count += 1
+    assert_eq(count, 1)
+}
+
+func test_diff() {
+    println("checking")
+    assert_eq([1, 2, {"a": 3}], [1, 2, {"a": 4}])
+}
+
+func test_raises() {
+    func broken() {
+        return 1 + "a"
+    }
+    assert_raises(broken, "type mismatch")
+    assert_raises(helper)
+}
+
+func helper() {
+    return 1
+}
+`
+
+func TestRunFile(t *testing.T) {
+	var out bytes.Buffer
+	runner := &Runner{Out: &out}
+	results, err := runner.RunFile("math_test.al", tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name    string
+		failure string
+		line    int
+	}{
+		{"test_add", "", 0},
+		{"test_isolated", "", 0},
+		{"test_diff", "assert_eq failed\n    got:  [1, 2, {\"a\": 3}]\n    want: [1, 2, {\"a\": 4}]\n    diff: at [2][\"a\"]: got 3, want 4", 20},
+		{"test_raises", "assert_raises failed: no error was raised", 28},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Name != w.name || results[i].Failure != w.failure {
+			t.Errorf("result %d = %s %q, want %s %q", i, results[i].Name, results[i].Failure, w.name, w.failure)
+		}
+		if w.line > 0 && (results[i].Line != w.line || results[i].Column != 5) {
+			t.Errorf("%s failed at %d:%d, want %d:5", w.name, results[i].Line, results[i].Column, w.line)
+		}
+	}
+
+	report := out.String()
+	for _, line := range []string{
+		"--- FAIL: test_diff",
+		"    checking\n    math_test.al:20:5: assert_eq failed\n",
+		"FAIL  math_test.al",
+		"(2 of 4 failed)",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report %q does not contain %q", report, line)
+		}
+	}
+	if strings.Contains(report, "test_add") {
+		t.Errorf("passing tests are only listed in verbose mode")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var out bytes.Buffer
+	runner := &Runner{Out: &out, Filter: regexp.MustCompile("isolated"), Verbose: true}
+	results, err := runner.RunFile("math_test.al", tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "test_isolated" || !results[0].Passed() {
+		t.Errorf("results = %v", results)
+	}
+	if !strings.Contains(out.String(), "=== RUN   test_isolated\n--- PASS: test_isolated") {
+		t.Errorf("verbose report = %q", out.String())
+	}
+}
+
+func TestStreamedOutput(t *testing.T) {
+	src := "func test_exec() {\n    var result = process.exec(\"/bin/echo\", [\"hi\"], {\"stream\": true})\n    assert_eq(len(result[\"stdout\"]), 3)\n}\n"
+	runner := &Runner{Out: &bytes.Buffer{}, Capabilities: map[string]bool{"process": true}}
+	results, err := runner.RunFile("exec_test.al", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Passed() || results[0].Output != "hi\n" {
+		t.Errorf("results = %v", results)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	runner := &Runner{Out: &bytes.Buffer{}}
+	results, _ := runner.RunFile("math_test.al", tests)
+
+	var out bytes.Buffer
+	if err := WriteJUnit(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+	for _, want := range []string{
+		`<testsuite name="math_test.al" tests="4" failures="2"`,
+		`<testcase name="test_add" classname="math_test.al"`,
+		`<failure message="assert_eq failed">math_test.al:20:5: assert_eq failed`,
+		`<system-out>checking&#xA;</system-out>`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report %q does not contain %q", report, want)
+		}
+	}
+}
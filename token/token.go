@@ -1,9 +1,12 @@
 package token
 
+import "sort"
+
 const (
 	// Special tokens
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT"
 
 	// Identifier
 	IDENT = "IDENT"
@@ -27,11 +30,25 @@ const (
 	ASTERISK = "*"
 	SLASH    = "/"
 	MODULUS  = "%"
+	POWER    = "**"
+
+	// Bitwise operators
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	LSHIFT    = "<<"
+	RSHIFT    = ">>"
 
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	DOTDOT    = ".."
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
+	RARROW    = "->"
 
 	// Brackets
 	LPAREN   = "("
@@ -52,6 +69,13 @@ const (
 	RETURN   = "RETURN"
 	FOR      = "FOR"
 	WHILE    = "WHILE"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	STRUCT   = "STRUCT"
+	ENUM     = "ENUM"
+	MATCH    = "MATCH"
 
 	// Types
 	STRING  = "STRING"
@@ -64,6 +88,8 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int
+	Column  int
 }
 
 func (t *Token) String() string {
@@ -72,16 +98,32 @@ func (t *Token) String() string {
 }
 
 var keywords = map[string]TokenType{
-	"if":     IF,
-	"elif":   ELIF,
-	"else":   ELSE,
-	"func":   FUNCTION,
-	"return": RETURN,
-	"var":    VAR,
-	"true":   TRUE,
-	"false":  FALSE,
-	"for":    FOR,
-	"while":  WHILE,
+	"if":      IF,
+	"elif":    ELIF,
+	"else":    ELSE,
+	"func":    FUNCTION,
+	"return":  RETURN,
+	"var":     VAR,
+	"true":    TRUE,
+	"false":   FALSE,
+	"for":     FOR,
+	"while":   WHILE,
+	"try":     TRY,
+	"catch":   CATCH,
+	"finally": FINALLY,
+	"throw":   THROW,
+	"struct":  STRUCT,
+	"enum":    ENUM,
+	"match":   MATCH,
+}
+
+func Keywords() []string {
+	var words []string
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 func KeywordLookUp(word string) TokenType {